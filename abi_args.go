@@ -0,0 +1,233 @@
+package seth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrArgumentCount   = "wrong number of arguments"
+	ErrInvalidArgument = "invalid argument"
+)
+
+// ParseABIArguments converts arguments passed as strings (e.g. from the CLI) to Go values expected by abi.Pack for given ABI inputs.
+// Numbers can be decimal or hex, addresses and bytes hex. Arrays, slices and tuples have to be passed as JSON, tuples either as
+// arrays of fields or objects with field names as keys, e.g. '[1, "0x..."]' or '{"amount": 1, "to": "0x..."}'.
+func ParseABIArguments(inputs abi.Arguments, args []string) ([]interface{}, error) {
+	if len(inputs) != len(args) {
+		return nil, errors.Errorf("%s: expected %d, got %d", ErrArgumentCount, len(inputs), len(args))
+	}
+
+	values := make([]interface{}, len(args))
+	for i, input := range inputs {
+		value, err := ParseABIArgument(input.Type, args[i])
+		if err != nil {
+			name := input.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, errors.Wrapf(err, "%s %s (%s)", ErrInvalidArgument, name, input.Type.String())
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// ParseABIArgument converts a single argument passed as string to Go value of given ABI type
+func ParseABIArgument(t abi.Type, arg string) (interface{}, error) {
+	var raw interface{} = arg
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		decoder := json.NewDecoder(bytes.NewReader([]byte(arg)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, errors.Wrap(err, "arrays and tuples must be passed as JSON")
+		}
+	}
+
+	value, err := abiValue(t, raw)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// abiValue converts a string or value decoded from JSON to reflect.Value of the Go type used for given ABI type
+func abiValue(t abi.Type, raw interface{}) (reflect.Value, error) {
+	goType := t.GetType()
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := abiNumber(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !fitsInt(n, t) {
+			return reflect.Value{}, fmt.Errorf("%s doesn't fit in %s", n.String(), t.String())
+		}
+		if goType == reflect.TypeOf(&big.Int{}) {
+			return reflect.ValueOf(n), nil
+		}
+		if t.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(goType), nil
+		}
+		return reflect.ValueOf(n.Int64()).Convert(goType), nil
+	case abi.BoolTy:
+		switch v := raw.(type) {
+		case bool:
+			return reflect.ValueOf(v), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(b), nil
+		}
+	case abi.StringTy:
+		if s, ok := raw.(string); ok {
+			return reflect.ValueOf(s), nil
+		}
+	case abi.AddressTy:
+		if s, ok := raw.(string); ok {
+			if !common.IsHexAddress(s) {
+				return reflect.Value{}, fmt.Errorf("%s is not a hex address", s)
+			}
+			return reflect.ValueOf(common.HexToAddress(s)), nil
+		}
+	case abi.BytesTy:
+		if s, ok := raw.(string); ok {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(b), nil
+		}
+	case abi.FixedBytesTy, abi.FunctionTy:
+		if s, ok := raw.(string); ok {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if len(b) != goType.Len() {
+				return reflect.Value{}, fmt.Errorf("expected %d bytes, got %d", goType.Len(), len(b))
+			}
+			value := reflect.New(goType).Elem()
+			reflect.Copy(value, reflect.ValueOf(b))
+			return value, nil
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		elems, ok := raw.([]interface{})
+		if !ok {
+			break
+		}
+		var value reflect.Value
+		if t.T == abi.SliceTy {
+			value = reflect.MakeSlice(goType, len(elems), len(elems))
+		} else {
+			if len(elems) != t.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d elements, got %d", t.Size, len(elems))
+			}
+			value = reflect.New(goType).Elem()
+		}
+		for i, elem := range elems {
+			elemValue, err := abiValue(*t.Elem, elem)
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "element %d", i)
+			}
+			value.Index(i).Set(elemValue)
+		}
+		return value, nil
+	case abi.TupleTy:
+		var fields []interface{}
+		switch v := raw.(type) {
+		case []interface{}:
+			fields = v
+		case map[string]interface{}:
+			for _, name := range t.TupleRawNames {
+				field, ok := v[name]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("missing tuple field %s", name)
+				}
+				fields = append(fields, field)
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("expected JSON array or object for %s, got %v", t.String(), raw)
+		}
+		if len(fields) != len(t.TupleElems) {
+			return reflect.Value{}, fmt.Errorf("expected %d tuple fields, got %d", len(t.TupleElems), len(fields))
+		}
+		value := reflect.New(goType).Elem()
+		for i, field := range fields {
+			fieldValue, err := abiValue(*t.TupleElems[i], field)
+			if err != nil {
+				return reflect.Value{}, errors.Wrapf(err, "tuple field %s", t.TupleRawNames[i])
+			}
+			value.Field(i).Set(fieldValue)
+		}
+		return value, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %s", t.String())
+	}
+
+	return reflect.Value{}, fmt.Errorf("can't convert %v to %s", raw, t.String())
+}
+
+// abiNumber parses decimal or hex number passed as string or JSON number
+func abiNumber(raw interface{}) (*big.Int, error) {
+	var s string
+	switch v := raw.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case json.Number:
+		s = v.String()
+	default:
+		return nil, fmt.Errorf("expected number, got %v", raw)
+	}
+	// only 0x prefix is accepted for hex numbers, everything else is decimal (leading zeros don't mean octal)
+	digits, negative := strings.CutPrefix(s, "-")
+	base := 10
+	if hex, ok := strings.CutPrefix(strings.ToLower(digits), "0x"); ok {
+		digits, base = hex, 16
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok || strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return nil, fmt.Errorf("%s is not a number", s)
+	}
+	if negative {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// fitsInt checks if number fits in ABI integer type
+func fitsInt(n *big.Int, t abi.Type) bool {
+	if t.T == abi.UintTy {
+		return n.Sign() >= 0 && n.BitLen() <= t.Size
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	return n.Cmp(new(big.Int).Neg(limit)) >= 0 && n.Cmp(limit) < 0
+}
+
+// ParseConstructorArguments converts constructor arguments passed as strings to values expected by the constructor of the contract
+// from Seth's Contract Store, so that they can be passed to DeployContractFromContractStore
+func (m *Client) ParseConstructorArguments(name string, args []string) ([]interface{}, error) {
+	if m.ContractStore == nil {
+		return nil, errors.New("ABIStore is nil")
+	}
+	contractAbi, ok := m.ContractStore.GetABI(strings.TrimSuffix(name, ".abi"))
+	if !ok {
+		return nil, errors.New("ABI not found")
+	}
+	return ParseABIArguments(contractAbi.Constructor.Inputs, args)
+}
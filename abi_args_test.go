@@ -0,0 +1,107 @@
+package seth_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const abiArgsConstructorABI = `[{"inputs":[
+	{"name":"amount","type":"uint256"},
+	{"name":"decimals","type":"uint8"},
+	{"name":"offset","type":"int16"},
+	{"name":"owner","type":"address"},
+	{"name":"paused","type":"bool"},
+	{"name":"name","type":"string"},
+	{"name":"data","type":"bytes"},
+	{"name":"salt","type":"bytes32"},
+	{"name":"limits","type":"uint256[]"},
+	{"name":"admins","type":"address[2]"},
+	{"name":"config","type":"tuple","components":[{"name":"fee","type":"uint32"},{"name":"recipient","type":"address"}]}
+],"stateMutability":"nonpayable","type":"constructor"}]`
+
+func TestParseABIArgumentsConvertsStringsToABITypes(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(abiArgsConstructorABI))
+	require.NoError(t, err, "failed to parse ABI")
+
+	owner := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	args := []string{
+		"1000000000000000000000",
+		"18",
+		"-5",
+		owner,
+		"true",
+		"Link Token",
+		"0xdeadbeef",
+		"0x" + strings.Repeat("ab", 32),
+		`[1, "0x10", "300"]`,
+		`["` + owner + `", "0x0000000000000000000000000000000000000001"]`,
+		`{"fee": 30, "recipient": "` + owner + `"}`,
+	}
+
+	values, err := seth.ParseABIArguments(parsed.Constructor.Inputs, args)
+	require.NoError(t, err, "failed to parse arguments")
+	require.Equal(t, "1000000000000000000000", values[0].(*big.Int).String(), "incorrect uint256")
+	require.Equal(t, uint8(18), values[1], "incorrect uint8")
+	require.Equal(t, int16(-5), values[2], "incorrect int16")
+	require.Equal(t, common.HexToAddress(owner), values[3], "incorrect address")
+	require.Equal(t, true, values[4], "incorrect bool")
+	require.Equal(t, "Link Token", values[5], "incorrect string")
+	require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, values[6], "incorrect bytes")
+	require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(16), big.NewInt(300)}, values[8], "incorrect uint256[]")
+
+	// leading zeros don't make a number octal
+	leadingZero, err := seth.ParseABIArgument(parsed.Constructor.Inputs[0].Type, "010")
+	require.NoError(t, err, "failed to parse number with leading zero")
+	require.Equal(t, big.NewInt(10), leadingZero, "number with leading zero should be parsed as decimal")
+	hex, err := seth.ParseABIArgument(parsed.Constructor.Inputs[2].Type, "-0X10")
+	require.NoError(t, err, "failed to parse negative hex number")
+	require.Equal(t, int16(-16), hex, "incorrect negative hex number")
+
+	_, err = parsed.Constructor.Inputs.Pack(values...)
+	require.NoError(t, err, "parsed arguments should be accepted by ABI packer")
+
+	// tuples can also be passed as arrays of fields
+	args[10] = `[30, "` + owner + `"]`
+	tupleValues, err := seth.ParseABIArguments(parsed.Constructor.Inputs, args)
+	require.NoError(t, err, "failed to parse tuple passed as array")
+	require.Equal(t, values[10], tupleValues[10], "tuple passed as array and object should be the same")
+}
+
+func TestParseABIArgumentsRejectsInvalidArguments(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(abiArgsConstructorABI))
+	require.NoError(t, err, "failed to parse ABI")
+	inputs := parsed.Constructor.Inputs
+
+	_, err = seth.ParseABIArguments(inputs, []string{"1"})
+	require.ErrorContains(t, err, seth.ErrArgumentCount, "missing arguments should be rejected")
+
+	_, err = seth.ParseABIArgument(inputs[1].Type, "256")
+	require.ErrorContains(t, err, "doesn't fit in uint8", "overflow should be rejected")
+
+	_, err = seth.ParseABIArgument(inputs[0].Type, "-1")
+	require.ErrorContains(t, err, "doesn't fit in uint256", "negative unsigned number should be rejected")
+
+	for _, number := range []string{"0b101", "0o17", "1_000", "0x_10", "--5", "0x-5", "0x", ""} {
+		_, err = seth.ParseABIArgument(inputs[0].Type, number)
+		require.ErrorContains(t, err, "is not a number", "only decimal and 0x-prefixed hex numbers should be accepted, got %q", number)
+	}
+
+	_, err = seth.ParseABIArgument(inputs[3].Type, "0x1234")
+	require.ErrorContains(t, err, "is not a hex address", "invalid address should be rejected")
+
+	_, err = seth.ParseABIArgument(inputs[7].Type, "0x1234")
+	require.ErrorContains(t, err, "expected 32 bytes", "too short bytes32 should be rejected")
+
+	_, err = seth.ParseABIArgument(inputs[9].Type, `["0x5FbDB2315678afecb367f032d93F642f64180aa3"]`)
+	require.ErrorContains(t, err, "expected 2 elements", "fixed array of wrong size should be rejected")
+
+	_, err = seth.ParseABIArgument(inputs[10].Type, `{"fee": 30}`)
+	require.ErrorContains(t, err, "missing tuple field recipient", "incomplete tuple should be rejected")
+}
@@ -24,6 +24,16 @@ func (a *ABIFinderResult) ContractName() string {
 	return strings.TrimSuffix(a.contractName, ".abi")
 }
 
+type ABIFinderEventResult struct {
+	ABI          abi.ABI
+	Event        *abi.Event
+	contractName string
+}
+
+func (a *ABIFinderEventResult) ContractName() string {
+	return strings.TrimSuffix(a.contractName, ".abi")
+}
+
 func NewABIFinder(contractMap ContractMap, contractStore *ContractStore) ABIFinder {
 	return ABIFinder{
 		ContractMap:   contractMap,
@@ -133,6 +143,57 @@ func (a *ABIFinder) FindABIByMethod(address string, signature []byte) (ABIFinder
 	return result, nil
 }
 
+// FindABIByEvent finds the ABI and event definition for a log with given topics emitted by contract at given address.
+// If the address is known and its ABI has a matching event, that ABI is used. Otherwise, we look for the event in
+// all known ABIs using the global event index and use the first definition whose indexed arguments match the topics.
+// Unlike FindABIByMethod it never updates the contract map, as events are much less unique than methods.
+func (a *ABIFinder) FindABIByEvent(address string, topics []common.Hash) (ABIFinderEventResult, error) {
+	if len(topics) == 0 {
+		return ABIFinderEventResult{}, errors.New(ErrNoEventTopics)
+	}
+
+	if a.ContractMap.IsKnownAddress(address) {
+		contractName := a.ContractMap.GetContractName(address)
+		if abiInstance, ok := a.ContractStore.GetABI(contractName); ok {
+			event, err := abiInstance.EventByID(topics[0])
+			if err == nil && eventMatchesTopics(event, topics) {
+				return ABIFinderEventResult{ABI: *abiInstance, Event: event, contractName: contractName}, nil
+			}
+			L.Trace().
+				Str("Address", address).
+				Str("Contract", contractName).
+				Str("Topic", topics[0].Hex()).
+				Msg("Event not found in ABI of the emitting contract. Searching all known ABIs")
+		}
+	}
+
+	for _, candidate := range a.ContractStore.FindEventsByTopic(topics[0]) {
+		candidate := candidate
+		if !eventMatchesTopics(&candidate.Event, topics) {
+			continue
+		}
+		abiInstance, ok := a.ContractStore.GetABI(candidate.ABINames[0])
+		if !ok {
+			continue
+		}
+
+		return ABIFinderEventResult{ABI: *abiInstance, Event: &candidate.Event, contractName: candidate.ABINames[0]}, nil
+	}
+
+	return ABIFinderEventResult{}, errors.New(ErrNoABIEvent)
+}
+
+// eventMatchesTopics returns true if number of indexed event arguments matches number of topics (excluding topic0)
+func eventMatchesTopics(event *abi.Event, topics []common.Hash) bool {
+	indexed := 0
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed++
+		}
+	}
+	return indexed == len(topics)-1
+}
+
 func (a *ABIFinder) getDuplicateCount(signature []byte) int {
 	count := 0
 	for _, abiInstance := range a.ContractStore.ABIs {
@@ -0,0 +1,113 @@
+package seth_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const (
+	erc20TransferABI  = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+	erc721TransferABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"}]`
+)
+
+func TestABIFinderFindsEventByEmitterAddress(t *testing.T) {
+	cs, err := seth.NewContractStore("./contracts/abi", "")
+	require.NoError(t, err, "failed to create contract store")
+
+	emitter := "0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82"
+	contractMap := seth.NewEmptyContractMap()
+	contractMap.AddContract(emitter, "NetworkDebugSubContract")
+	finder := seth.NewABIFinder(contractMap, cs)
+
+	topics := []common.Hash{crypto.Keccak256Hash([]byte("OneIndexEvent(uint256)")), common.BigToHash(common.Big1)}
+	result, err := finder.FindABIByEvent(emitter, topics)
+	require.NoError(t, err, "failed to find event ABI")
+	require.Equal(t, "NetworkDebugSubContract", result.ContractName(), "ABI of the emitting contract should be used")
+	require.Equal(t, "OneIndexEvent(uint256)", result.Event.Sig, "incorrect event")
+
+	// event emitted by an unknown contract is found in the global event index
+	topics = []common.Hash{crypto.Keccak256Hash([]byte("CallbackEvent(int256)")), common.BigToHash(common.Big1)}
+	result, err = finder.FindABIByEvent(emitter, topics)
+	require.NoError(t, err, "failed to find event ABI using global event index")
+	require.Equal(t, "NetworkDebugContract", result.ContractName(), "ABI with the event should be used")
+	require.Equal(t, "CallbackEvent(int256)", result.Event.Sig, "incorrect event")
+
+	_, err = finder.FindABIByEvent(emitter, []common.Hash{crypto.Keccak256Hash([]byte("Unknown()"))})
+	require.EqualError(t, err, seth.ErrNoABIEvent, "expected error for unknown event")
+}
+
+func TestABIFinderDistinguishesEventsByIndexedArguments(t *testing.T) {
+	cs, err := seth.NewContractStore("", "")
+	require.NoError(t, err, "failed to create contract store")
+
+	erc20, err := abi.JSON(strings.NewReader(erc20TransferABI))
+	require.NoError(t, err, "failed to parse ERC-20 ABI")
+	erc721, err := abi.JSON(strings.NewReader(erc721TransferABI))
+	require.NoError(t, err, "failed to parse ERC-721 ABI")
+	cs.AddABI("ERC20", erc20)
+	cs.AddABI("ERC721", erc721)
+
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	require.Len(t, cs.FindEventsByTopic(transferTopic), 2, "events with different indexed arguments should be indexed separately")
+
+	finder := seth.NewABIFinder(seth.NewEmptyContractMap(), cs)
+	from, to := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	result, err := finder.FindABIByEvent("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82", []common.Hash{transferTopic, from, to})
+	require.NoError(t, err, "failed to find ERC-20 Transfer")
+	require.Equal(t, "ERC20", result.ContractName(), "ERC-20 Transfer has 2 indexed arguments")
+
+	result, err = finder.FindABIByEvent("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82", []common.Hash{transferTopic, from, to, common.HexToHash("0x03")})
+	require.NoError(t, err, "failed to find ERC-721 Transfer")
+	require.Equal(t, "ERC721", result.ContractName(), "ERC-721 Transfer has 3 indexed arguments")
+}
+
+func TestDecodeSkipsMalformedLogAndKeepsOtherEvents(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node)
+
+	tokenABI, err := abi.JSON(strings.NewReader(`[{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`))
+	require.NoError(t, err, "failed to parse token ABI")
+	erc20, err := abi.JSON(strings.NewReader(erc20TransferABI))
+	require.NoError(t, err, "failed to parse ERC-20 ABI")
+	tokenABI.Events = erc20.Events
+	c.ContractStore.AddABI("Token", tokenABI)
+
+	token := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	node.code[token] = []byte{0x01}
+	recipient := common.HexToAddress("0x02")
+	tx, err := bind.NewBoundContract(token, tokenABI, c.Client, c.Client, c.Client).
+		Transact(c.NewTXOpts(), "transfer", recipient, big.NewInt(10))
+	require.NoError(t, err, "failed to send transaction")
+
+	transferTopics := []common.Hash{
+		crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+		common.BytesToHash(c.Addresses[0].Bytes()),
+		common.BytesToHash(recipient.Bytes()),
+	}
+	node.mine(t, tx, types.ReceiptStatusSuccessful,
+		&types.Log{Address: token, Topics: transferTopics, Data: common.BigToHash(big.NewInt(10)).Bytes()},
+		// value doesn't fit into data, so this log can't be unpacked
+		&types.Log{Address: token, Topics: transferTopics, Data: []byte{0x01}},
+		&types.Log{Address: token, Topics: transferTopics, Data: common.BigToHash(big.NewInt(20)).Bytes()},
+	)
+
+	decoded, err := c.Decode(tx, nil)
+	require.NoError(t, err, "failed to decode transaction")
+	require.Equal(t, "transfer(address,uint256)", decoded.Method, "incorrect method")
+	require.Len(t, decoded.Events, 2, "only the malformed log should be skipped")
+	require.Equal(t, big.NewInt(10), decoded.Events[0].EventData["value"], "incorrect value of the first event")
+	require.Equal(t, uint(0), decoded.Events[0].Index, "incorrect index of the first event")
+	require.Equal(t, big.NewInt(20), decoded.Events[1].EventData["value"], "incorrect value of the last event")
+	require.Equal(t, uint(2), decoded.Events[1].Index, "incorrect index of the last event")
+}
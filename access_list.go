@@ -0,0 +1,200 @@
+package seth
+
+import (
+	"context"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrCreateAccessList   = "failed to create access list"
+	ErrAccessListReverted = "transaction reverts, when creating access list"
+	ErrAccessListGasLimit = "failed to estimate gas limit with access list"
+)
+
+// gas saved by each access list entry, when it's accessed for the first time: cold access cost minus warm access cost minus the cost of the entry
+const (
+	accessListAddressGasSaving    = params.ColdAccountAccessCostEIP2929 - params.WarmStorageReadCostEIP2929 - params.TxAccessListAddressGas
+	accessListStorageKeyGasSaving = params.ColdSloadCostEIP2929 - params.WarmStorageReadCostEIP2929 - params.TxAccessListStorageKeyGas
+)
+
+type accessListContextKey struct{}
+
+// WithAccessList enables or disables automatic EIP-2930 access list generation for a single transaction, regardless of `access_lists_enabled` setting
+func WithAccessList(enabled bool) TransactOpt {
+	return func(o *bind.TransactOpts) {
+		ctx := o.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		o.Context = context.WithValue(ctx, accessListContextKey{}, enabled)
+	}
+}
+
+// accessListEnabled returns true if access list should be generated for transaction with given options
+func (m *Client) accessListEnabled(opts *bind.TransactOpts) bool {
+	if opts.Context != nil {
+		if enabled, ok := opts.Context.Value(accessListContextKey{}).(bool); ok {
+			return enabled
+		}
+	}
+	return m.Cfg.Network.AccessListsEnabled
+}
+
+// accessListSigner wraps signer function, so that before signing we call eth_createAccessList for the transaction and
+// replace it with an access list transaction (legacy) or a dynamic fee transaction with the access list. If access list
+// can't be created, original transaction is signed.
+func (m *Client) accessListSigner(signerFn bind.SignerFn) bind.SignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		txWithAccessList, err := m.addAccessList(from, tx)
+		if err != nil {
+			L.Warn().
+				Err(err).
+				Msg("Failed to add access list to transaction. Sending it without access list")
+			return signerFn(from, tx)
+		}
+		return signerFn(from, txWithAccessList)
+	}
+}
+
+func (m *Client) addAccessList(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		msg.GasFeeCap = tx.GasFeeCap()
+		msg.GasTipCap = tx.GasTipCap()
+	} else {
+		msg.GasPrice = tx.GasPrice()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+
+	accessList, gasUsed, vmErr, err := gethclient.New(m.Client.Client()).CreateAccessList(ctx, msg)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrCreateAccessList)
+	}
+	if vmErr != "" {
+		return nil, errors.Wrap(errors.New(vmErr), ErrAccessListReverted)
+	}
+
+	L.Debug().
+		Interface("AccessList", accessList).
+		Uint64("GasUsed", gasUsed).
+		Int64("GasSaved", AccessListGasSaving(*accessList, warmAddresses(from, tx)...)).
+		Msg("Created access list")
+
+	// entries of warm addresses (e.g. the called contract) cost more than they save, unless they have at least 24 storage keys,
+	// so gas limit estimated without access list might be too low. It's never lowered, because it might have been set explicitly.
+	gasLimit := tx.Gas()
+	estimated, err := m.estimateGasWithAccessList(ctx, msg, *accessList)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrAccessListGasLimit)
+	}
+	if estimated > gasLimit {
+		L.Debug().
+			Uint64("Gas limit", gasLimit).
+			Uint64("Gas limit with access list", estimated).
+			Msg("Access list costs more than it saves. Increasing gas limit")
+		gasLimit = estimated
+	}
+
+	switch tx.Type() {
+	case types.DynamicFeeTxType:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    big.NewInt(m.ChainID),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        gasLimit,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: *accessList,
+		}), nil
+	case types.LegacyTxType, types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    big.NewInt(m.ChainID),
+			Nonce:      tx.Nonce(),
+			GasPrice:   tx.GasPrice(),
+			Gas:        gasLimit,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: *accessList,
+		}), nil
+	default:
+		return nil, errors.Errorf("unsupported tx type %d", tx.Type())
+	}
+}
+
+// estimateGasWithAccessList calls eth_estimateGas with the access list attached. It's called directly, because ethclient drops
+// access list from call arguments. Gas limit of the message isn't passed, so that the estimate isn't capped by it.
+func (m *Client) estimateGasWithAccessList(ctx context.Context, msg ethereum.CallMsg, accessList types.AccessList) (uint64, error) {
+	arg := map[string]interface{}{
+		"from":       msg.From,
+		"to":         msg.To,
+		"accessList": accessList,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+
+	var gas hexutil.Uint64
+	if err := m.Client.Client().CallContext(ctx, &gas, "eth_estimateGas", arg); err != nil {
+		return 0, err
+	}
+	return uint64(gas), nil
+}
+
+// AccessListGasSaving returns gas saved by the access list, assuming that every address and storage key in it is accessed
+// by the transaction (which is the case for access lists created by eth_createAccessList). Each storage key saves 100 gas
+// and each address 100 gas, unless it's already warm (sender, recipient or created contract), in which case including it
+// costs 2400 gas. Result might be negative.
+func AccessListGasSaving(accessList types.AccessList, warmAddresses ...common.Address) int64 {
+	var saving int64
+	for _, tuple := range accessList {
+		if slices.Contains(warmAddresses, tuple.Address) {
+			saving -= int64(params.TxAccessListAddressGas)
+		} else {
+			saving += int64(accessListAddressGasSaving)
+		}
+		saving += int64(len(tuple.StorageKeys)) * int64(accessListStorageKeyGasSaving)
+	}
+	return saving
+}
+
+// accessListGasSaving returns gas saved by the access list of the signed transaction
+func accessListGasSaving(tx *types.Transaction) int64 {
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		L.Debug().Err(err).Msg("Failed to get sender from tx. Gas saved by access list might be inaccurate")
+	}
+	return AccessListGasSaving(tx.AccessList(), warmAddresses(sender, tx)...)
+}
+
+// warmAddresses returns addresses that are warm from the start of the transaction: sender and recipient or created contract
+func warmAddresses(sender common.Address, tx *types.Transaction) []common.Address {
+	if tx.To() != nil {
+		return []common.Address{sender, *tx.To()}
+	}
+	return []common.Address{sender, crypto.CreateAddress(sender, tx.Nonce())}
+}
@@ -0,0 +1,121 @@
+package seth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+var (
+	accessListContract = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	accessListToken    = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+)
+
+// withTokenAccessList makes the fake node estimate 50_000 gas and return access list with two storage keys of the token.
+// Gas limit is set to 100_000 in config, so that it's not estimated.
+func withTokenAccessList(accessListsEnabled bool) fakeNodeClientOption {
+	return fakeNodeClientOption{
+		node: func(n *fakeNode) {
+			n.gasEstimate = 50_000
+			n.accessList = types.AccessList{
+				{Address: accessListToken, StorageKeys: []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")}},
+			}
+		},
+		config: func(cfg *seth.Config) {
+			cfg.Network.GasLimit = 100_000
+			cfg.Network.AccessListsEnabled = accessListsEnabled
+		},
+	}
+}
+
+func TestAccessListGasSaving(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	accessList := types.AccessList{
+		{Address: accessListContract, StorageKeys: []common.Hash{common.HexToHash("0x01")}},
+		{Address: accessListToken, StorageKeys: []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")}},
+	}
+
+	require.Equal(t, int64(2*100+3*100), seth.AccessListGasSaving(accessList), "all addresses should be cold")
+	require.Equal(t, int64(-2400+100+3*100), seth.AccessListGasSaving(accessList, sender, accessListContract), "recipient should be warm")
+	require.Equal(t, int64(0), seth.AccessListGasSaving(nil), "empty access list should save nothing")
+}
+
+func TestAccessListAddedToDynamicFeeTransaction(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withTokenAccessList(true), withEIP1559(true))
+
+	opts := c.NewTXOpts()
+	require.Nil(t, opts.Context.Value(seth.ContextErrorKey{}), "transaction options should have no error")
+
+	unsigned := types.NewTx(&types.DynamicFeeTx{Nonce: opts.Nonce.Uint64(), GasFeeCap: opts.GasFeeCap, GasTipCap: opts.GasTipCap, Gas: opts.GasLimit, To: &accessListContract, Value: big.NewInt(0)})
+	signed, err := opts.Signer(opts.From, unsigned)
+	require.NoError(t, err, "failed to sign transaction")
+
+	require.Equal(t, 1, node.accessListCalls, "access list should be created once")
+	require.Equal(t, uint8(types.DynamicFeeTxType), signed.Type(), "transaction type should not change")
+	require.Len(t, signed.AccessList(), 1, "access list should be attached")
+	require.Equal(t, accessListToken, signed.AccessList()[0].Address, "incorrect access list address")
+	require.Equal(t, unsigned.Gas(), signed.Gas(), "gas limit should not change")
+	require.Equal(t, unsigned.GasFeeCap(), signed.GasFeeCap(), "gas fee cap should not change")
+
+	intrinsicGas := params.TxGas + uint64(len(signed.AccessList()))*params.TxAccessListAddressGas + uint64(signed.AccessList().StorageKeys())*params.TxAccessListStorageKeyGas
+	require.GreaterOrEqual(t, signed.Gas(), intrinsicGas, "gas limit should cover intrinsic gas of the transaction with access list")
+
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(c.ChainID)), signed)
+	require.NoError(t, err, "failed to recover sender")
+	require.Equal(t, c.Addresses[0], sender, "transaction should be signed by the key")
+}
+
+func TestAccessListReplacesLegacyTransaction(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withTokenAccessList(false))
+
+	opts := c.NewTXOpts(seth.WithAccessList(true))
+	unsigned := types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64(), GasPrice: opts.GasPrice, Gas: opts.GasLimit, To: &accessListContract, Value: big.NewInt(0)})
+	signed, err := opts.Signer(opts.From, unsigned)
+	require.NoError(t, err, "failed to sign transaction")
+
+	require.Equal(t, 1, node.accessListCalls, "access list should be created once")
+	require.Equal(t, uint8(types.AccessListTxType), signed.Type(), "legacy transaction should be replaced with access list transaction")
+	require.Equal(t, unsigned.GasPrice(), signed.GasPrice(), "gas price should not change")
+	require.Equal(t, big.NewInt(c.ChainID), signed.ChainId(), "incorrect chain id")
+	require.Len(t, signed.AccessList(), 1, "access list should be attached")
+}
+
+func TestAccessListCanBeDisabledPerTransaction(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withTokenAccessList(true))
+
+	opts := c.NewTXOpts(seth.WithAccessList(false))
+	unsigned := types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64(), GasPrice: opts.GasPrice, Gas: opts.GasLimit, To: &accessListContract, Value: big.NewInt(0)})
+	signed, err := opts.Signer(opts.From, unsigned)
+	require.NoError(t, err, "failed to sign transaction")
+
+	require.Equal(t, 0, node.accessListCalls, "access list should not be created")
+	require.Equal(t, uint8(types.LegacyTxType), signed.Type(), "transaction type should not change")
+	require.Empty(t, signed.AccessList(), "access list should be empty")
+}
+
+func TestAccessListIncreasesGasLimitWhenItCostsMoreThanItSaves(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withTokenAccessList(true), withEIP1559(true), withNode(func(n *fakeNode) {
+		// called contract is always warm, so its entry costs 2400 gas and its only storage key saves just 100 gas
+		n.accessList = types.AccessList{{Address: accessListContract, StorageKeys: []common.Hash{common.HexToHash("0x01")}}}
+	}))
+	require.Equal(t, int64(-2300), seth.AccessListGasSaving(node.accessList, c.Addresses[0], accessListContract), "access list should cost more than it saves")
+
+	opts := c.NewTXOpts(seth.WithGasLimit(50_000))
+	unsigned := types.NewTx(&types.DynamicFeeTx{Nonce: opts.Nonce.Uint64(), GasFeeCap: opts.GasFeeCap, GasTipCap: opts.GasTipCap, Gas: opts.GasLimit, To: &accessListContract, Value: big.NewInt(0)})
+	signed, err := opts.Signer(opts.From, unsigned)
+	require.NoError(t, err, "failed to sign transaction")
+
+	require.Len(t, signed.AccessList(), 1, "access list should be attached")
+	accessListCost := params.TxAccessListAddressGas + params.TxAccessListStorageKeyGas - (params.ColdSloadCostEIP2929 - params.WarmStorageReadCostEIP2929)
+	require.Equal(t, 50_000+accessListCost, signed.Gas(), "gas limit should cover the cost of the access list")
+}
@@ -0,0 +1,305 @@
+package seth
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultActivityWatcherPollInterval = 2 * time.Second
+	activityWatcherRPCTimeout          = 30 * time.Second
+)
+
+// ActivityFilter narrows down activity reported by ActivityWatcher. Empty fields match everything.
+type ActivityFilter struct {
+	// Contracts are names from the contract map or addresses. If set, only activity involving these contracts is
+	// reported, otherwise all contracts from the contract map and all client's keys are watched
+	Contracts []string
+	// Methods are names or signatures of called methods, e.g. transfer or transfer(address,uint256)
+	Methods []string
+	// Events are names or signatures of emitted events, e.g. Transfer or Transfer(address,address,uint256)
+	Events []string
+}
+
+// Activity is a mined transaction involving watched addresses, decoded with ABIs from the Contract Store
+type Activity struct {
+	BlockNumber uint64          `json:"block_number"`
+	TxHash      string          `json:"tx_hash"`
+	From        common.Address  `json:"from"`
+	To          *common.Address `json:"to,omitempty"`
+	Reverted    bool            `json:"reverted"`
+	// RevertReason is set only for reverted transactions, if it could be retrieved
+	RevertReason string `json:"revert_reason,omitempty"`
+	// Decoded contains decoded inputs and events. Method is UNKNOWN if no ABI has the called method
+	Decoded *DecodedTransaction `json:"decoded"`
+	// Trace is set only for reverted transactions, if debug API is available and tracing is enabled
+	Trace []*DecodedCall `json:"trace,omitempty"`
+}
+
+// ActivityWatcher follows new heads and reports mined transactions sent from or to client's keys or contracts from
+// the contract map, as well as transactions that made these contracts emit logs. Reverted transactions are traced
+// if tracing isn't disabled and debug API is available.
+type ActivityWatcher struct {
+	Client       *Client
+	PollInterval time.Duration
+
+	filter    ActivityFilter
+	watched   map[common.Address]struct{}
+	contracts []common.Address
+	trace     bool
+	started   bool
+	lastBlock uint64
+}
+
+// NewActivityWatcher creates a new ActivityWatcher. Contracts from the filter are resolved using the contract map
+func NewActivityWatcher(c *Client, filter ActivityFilter) (*ActivityWatcher, error) {
+	w := &ActivityWatcher{
+		Client:       c,
+		PollInterval: DefaultActivityWatcherPollInterval,
+		filter:       filter,
+		watched:      make(map[common.Address]struct{}),
+		trace:        c.Tracer != nil && c.Cfg.TracingLevel != TracingLevel_None,
+	}
+
+	if len(filter.Contracts) > 0 {
+		for _, contract := range filter.Contracts {
+			if common.IsHexAddress(contract) {
+				w.watchContract(common.HexToAddress(contract))
+				continue
+			}
+			address := c.ContractAddressToNameMap.GetContractAddress(contract)
+			if address == UNKNOWN {
+				return nil, errors.Errorf("%s: %s", ErrUnknownContract, contract)
+			}
+			w.watchContract(common.HexToAddress(address))
+		}
+		return w, nil
+	}
+
+	for address := range c.ContractAddressToNameMap.GetContractMap() {
+		w.watchContract(common.HexToAddress(address))
+	}
+	for _, address := range c.Addresses {
+		w.watched[address] = struct{}{}
+	}
+	if len(w.watched) == 0 {
+		return nil, errors.New("nothing to watch, contract map is empty and no keys are loaded")
+	}
+
+	return w, nil
+}
+
+func (w *ActivityWatcher) watchContract(address common.Address) {
+	if _, ok := w.watched[address]; ok {
+		return
+	}
+	w.watched[address] = struct{}{}
+	w.contracts = append(w.contracts, address)
+}
+
+// WatchedAddresses returns the number of watched addresses
+func (w *ActivityWatcher) WatchedAddresses() int {
+	return len(w.watched)
+}
+
+// Watch polls for new heads and calls handler for every activity matching the filter, until context is cancelled.
+// It starts from the current head. Errors are logged and the block is retried during the next poll.
+func (w *ActivityWatcher) Watch(ctx context.Context, handler func(*Activity)) error {
+	ticker := time.NewTicker(w.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Poll(ctx, handler); err != nil {
+			L.Warn().Err(err).Msg("Failed to process new blocks, will retry")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll processes all blocks mined since the last poll. The first poll processes only the current head
+func (w *ActivityWatcher) Poll(ctx context.Context, handler func(*Activity)) error {
+	headCtx, cancel := context.WithTimeout(ctx, activityWatcherRPCTimeout)
+	head, err := w.Client.Client.HeaderByNumber(headCtx, nil)
+	cancel()
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch latest header")
+	}
+
+	if !w.started {
+		// for genesis it wraps around, so that block 0 is processed next
+		w.lastBlock = head.Number.Uint64() - 1
+		w.started = true
+	}
+	for bn := w.lastBlock + 1; bn <= head.Number.Uint64(); bn++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		activities, err := w.ProcessBlock(ctx, bn)
+		if err != nil {
+			return errors.Wrapf(err, "failed to process block %d", bn)
+		}
+		for _, activity := range activities {
+			handler(activity)
+		}
+		w.lastBlock = bn
+	}
+
+	return nil
+}
+
+// ProcessBlock returns all activity matching the filter in given block
+func (w *ActivityWatcher) ProcessBlock(ctx context.Context, number uint64) ([]*Activity, error) {
+	rpcCtx, cancel := context.WithTimeout(ctx, activityWatcherRPCTimeout)
+	defer cancel()
+
+	block, err := w.Client.Client.BlockByNumber(rpcCtx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, err
+	}
+
+	// transactions that made watched contracts emit logs, even if they weren't sent to them directly
+	emitted := make(map[common.Hash]struct{})
+	if len(w.contracts) > 0 {
+		logs, err := w.Client.Client.FilterLogs(rpcCtx, ethereum.FilterQuery{
+			FromBlock: block.Number(),
+			ToBlock:   block.Number(),
+			Addresses: w.contracts,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to filter logs")
+		}
+		for _, lo := range logs {
+			emitted[lo.TxHash] = struct{}{}
+		}
+	}
+
+	var activities []*Activity
+	for _, tx := range block.Transactions() {
+		from, _ := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		_, isEmitted := emitted[tx.Hash()]
+		if !isEmitted && !w.isWatched(&from) && !w.isWatched(tx.To()) {
+			continue
+		}
+
+		activity, err := w.activity(rpcCtx, block, tx, from)
+		if err != nil {
+			return nil, err
+		}
+		if w.matches(activity) {
+			activities = append(activities, activity)
+		}
+	}
+
+	return activities, nil
+}
+
+func (w *ActivityWatcher) isWatched(address *common.Address) bool {
+	if address == nil {
+		return false
+	}
+	_, ok := w.watched[*address]
+	return ok
+}
+
+// activity decodes mined transaction, gets revert reason and traces it if it was reverted
+func (w *ActivityWatcher) activity(ctx context.Context, block *types.Block, tx *types.Transaction, from common.Address) (*Activity, error) {
+	receipt, err := w.Client.Client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get receipt of transaction %s", tx.Hash().Hex())
+	}
+
+	l := L.With().Str("Transaction", tx.Hash().Hex()).Logger()
+	decoded, err := w.Client.decodeTransaction(l, tx, receipt)
+	if err != nil {
+		l.Debug().Err(err).Msg("Failed to decode transaction")
+	}
+	if decoded.Method == "" {
+		decoded.Method = UNKNOWN
+	}
+	// logs aren't decoded if the called method is unknown, but watched contracts might still have emitted some
+	if len(decoded.Events) == 0 && len(receipt.Logs) > 0 && w.Client.ABIFinder != nil {
+		logs := make([]types.Log, 0, len(receipt.Logs))
+		for _, lo := range receipt.Logs {
+			logs = append(logs, *lo)
+		}
+		decoded.Events = w.Client.decodeContractLogs(l, logs)
+	}
+
+	activity := &Activity{
+		BlockNumber: block.NumberU64(),
+		TxHash:      tx.Hash().Hex(),
+		From:        from,
+		To:          tx.To(),
+		Reverted:    receipt.Status == types.ReceiptStatusFailed,
+		Decoded:     decoded,
+	}
+	if !activity.Reverted {
+		return activity, nil
+	}
+
+	revertErr := w.Client.callAndGetRevertReason(tx, receipt)
+	if revertErr != nil {
+		activity.RevertReason = revertErr.Error()
+	}
+
+	if w.trace {
+		if traceErr := w.Client.Tracer.TraceGethTX(activity.TxHash, revertErr); traceErr != nil {
+			if strings.Contains(traceErr.Error(), "debug_traceTransaction does not exist") {
+				l.Warn().Msg("Debug API is either disabled or not available on the node. Reverted transactions won't be traced")
+				w.trace = false
+			} else {
+				l.Debug().Err(traceErr).Msg("Failed to trace reverted transaction")
+			}
+		} else {
+			activity.Trace = w.Client.Tracer.GetDecodedCalls(activity.TxHash)
+		}
+		// watcher runs until interrupted, decoded calls are already part of the activity
+		w.Client.Tracer.forget(activity.TxHash)
+	}
+
+	return activity, nil
+}
+
+// matches checks if activity matches method and event filters. If events filter is set, only matching events are kept
+func (w *ActivityWatcher) matches(activity *Activity) bool {
+	if len(w.filter.Methods) > 0 && !matchesSignature(activity.Decoded.Method, w.filter.Methods) {
+		return false
+	}
+	if len(w.filter.Events) == 0 {
+		return true
+	}
+
+	var events []DecodedTransactionLog
+	for _, event := range activity.Decoded.Events {
+		if matchesSignature(event.Signature, w.filter.Events) {
+			events = append(events, event)
+		}
+	}
+	activity.Decoded.Events = events
+
+	return len(events) > 0
+}
+
+// matchesSignature checks if signature, e.g. transfer(address,uint256), matches any of given names or signatures
+func matchesSignature(signature string, namesOrSignatures []string) bool {
+	name, _, _ := strings.Cut(signature, "(")
+	for _, expected := range namesOrSignatures {
+		expected = strings.ReplaceAll(expected, " ", "")
+		if expected == signature || expected == name {
+			return true
+		}
+	}
+	return false
+}
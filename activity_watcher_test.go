@@ -0,0 +1,138 @@
+package seth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+var watchTestToken = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+// signWatchTestTx signs a transaction calling "to" with given data
+func signWatchTestTx(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, to common.Address, data []byte) *types.Transaction {
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(fakeNodeChainID)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(fakeNodeChainID),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(fakeNodeGasTipCap),
+		GasFeeCap: big.NewInt(fakeNodeGasFeeCap),
+		Gas:       100_000,
+		To:        &to,
+		Data:      data,
+	})
+	require.NoError(t, err, "failed to sign transaction")
+	return tx
+}
+
+func TestActivityWatcherReportsDecodedActivityOfWatchedAddresses(t *testing.T) {
+	node := newFakeNode(t)
+	node.callErr = errors.New("execution reverted: not enough LINK")
+	c := newFakeNodeClient(t, node, withTracingLevel(seth.TracingLevel_Reverted), withABIDir("./contracts/abi"), withContract(watchTestToken, "LinkTokenModern"))
+	linkABI, ok := c.ContractStore.GetABI("LinkTokenModern")
+	require.True(t, ok, "ABI not found")
+
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err, "failed to generate key")
+	ownKey := c.PrivateKeys[0]
+	ownAddress, otherAddress := c.Addresses[0], crypto.PubkeyToAddress(otherKey.PublicKey)
+	router := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	value := common.BigToHash(big.NewInt(5))
+	transferLog := &types.Log{
+		Address: watchTestToken,
+		Topics:  []common.Hash{linkABI.Events["Transfer"].ID, common.BytesToHash(otherAddress.Bytes()), common.BytesToHash(ownAddress.Bytes())},
+		Data:    value.Bytes(),
+	}
+	transfer, err := linkABI.Pack("transfer", otherAddress, big.NewInt(5))
+	require.NoError(t, err, "failed to pack transfer")
+	approve, err := linkABI.Pack("approve", router, big.NewInt(5))
+	require.NoError(t, err, "failed to pack approve")
+
+	block := node.newBlock()
+	// sent from own key to unknown contract
+	sentByKey := signWatchTestTx(t, ownKey, 0, router, common.FromHex("0xdeadbeef"))
+	node.mine(t, sentByKey, types.ReceiptStatusSuccessful)
+	// sent by someone else to unknown contract, which made watched token emit Transfer
+	emitted := signWatchTestTx(t, otherKey, 0, router, nil)
+	node.mine(t, emitted, types.ReceiptStatusSuccessful, transferLog)
+	// sent by someone else to unknown contract
+	node.mine(t, signWatchTestTx(t, otherKey, 1, router, nil), types.ReceiptStatusSuccessful)
+	// sent by someone else to watched token, reverted
+	reverted := signWatchTestTx(t, otherKey, 2, watchTestToken, transfer)
+	node.mine(t, reverted, types.ReceiptStatusFailed)
+	// sent by someone else to watched token
+	approved := signWatchTestTx(t, otherKey, 3, watchTestToken, approve)
+	node.mine(t, approved, types.ReceiptStatusSuccessful)
+
+	watcher, err := seth.NewActivityWatcher(c, seth.ActivityFilter{})
+	require.NoError(t, err, "failed to create watcher")
+	require.Equal(t, 2, watcher.WatchedAddresses(), "contract from the map and own key should be watched")
+
+	activities, err := watcher.ProcessBlock(context.Background(), block)
+	require.NoError(t, err, "failed to process block")
+	require.Len(t, activities, 4, "transaction not involving watched addresses should be skipped")
+
+	require.Equal(t, sentByKey.Hash().Hex(), activities[0].TxHash, "transaction sent from own key should be reported")
+	require.Equal(t, ownAddress, activities[0].From, "incorrect sender")
+	require.Equal(t, seth.UNKNOWN, activities[0].Decoded.Method, "unknown method should be reported as such")
+
+	require.Equal(t, emitted.Hash().Hex(), activities[1].TxHash, "transaction that made watched contract emit log should be reported")
+	require.Len(t, activities[1].Decoded.Events, 1, "log should be decoded")
+	require.Equal(t, "Transfer(address,address,uint256)", activities[1].Decoded.Events[0].Signature, "incorrect event")
+
+	require.Equal(t, reverted.Hash().Hex(), activities[2].TxHash, "reverted transaction should be reported")
+	require.True(t, activities[2].Reverted, "transaction should be marked as reverted")
+	require.Contains(t, activities[2].RevertReason, "not enough LINK", "revert reason should be retrieved")
+	require.Equal(t, "transfer(address,uint256)", activities[2].Decoded.Method, "input should be decoded")
+	require.Equal(t, otherAddress, activities[2].Decoded.Input["to"], "input should be decoded")
+	require.Empty(t, c.Tracer.GetAllDecodedCalls(), "watcher should not keep decoded calls")
+
+	require.Equal(t, approved.Hash().Hex(), activities[3].TxHash, "transaction sent to watched contract should be reported")
+	require.False(t, activities[3].Reverted, "transaction should not be marked as reverted")
+
+	watcher, err = seth.NewActivityWatcher(c, seth.ActivityFilter{Contracts: []string{"LinkTokenModern"}, Methods: []string{"transfer(address, uint256)", "approve"}})
+	require.NoError(t, err, "failed to create watcher with filters")
+	activities, err = watcher.ProcessBlock(context.Background(), block)
+	require.NoError(t, err, "failed to process block")
+	require.Len(t, activities, 2, "only transactions calling filtered methods should be reported")
+	require.Equal(t, reverted.Hash().Hex(), activities[0].TxHash, "transfer should be reported")
+	require.Equal(t, approved.Hash().Hex(), activities[1].TxHash, "approve should be reported")
+
+	watcher, err = seth.NewActivityWatcher(c, seth.ActivityFilter{Events: []string{"Transfer"}})
+	require.NoError(t, err, "failed to create watcher with filters")
+	activities, err = watcher.ProcessBlock(context.Background(), block)
+	require.NoError(t, err, "failed to process block")
+	require.Len(t, activities, 1, "only transactions emitting filtered events should be reported")
+	require.Equal(t, emitted.Hash().Hex(), activities[0].TxHash, "transaction emitting Transfer should be reported")
+
+	_, err = seth.NewActivityWatcher(c, seth.ActivityFilter{Contracts: []string{"Unknown"}})
+	require.ErrorContains(t, err, seth.ErrUnknownContract, "unknown contract should be rejected")
+}
+
+func TestActivityWatcherStartsFromCurrentHead(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withABIDir("./contracts/abi"), withContract(watchTestToken, "LinkTokenModern"))
+	node.newBlock()
+	tx := signWatchTestTx(t, c.PrivateKeys[0], 0, watchTestToken, nil)
+	node.mine(t, tx, types.ReceiptStatusSuccessful)
+
+	watcher, err := seth.NewActivityWatcher(c, seth.ActivityFilter{})
+	require.NoError(t, err, "failed to create watcher")
+	watcher.PollInterval = 10 * time.Millisecond
+
+	var reported []string
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.NoError(t, watcher.Watch(ctx, func(a *seth.Activity) {
+		reported = append(reported, a.TxHash)
+	}), "watch should stop without error")
+	require.Equal(t, []string{tx.Hash().Hex()}, reported, "head block should be processed exactly once")
+}
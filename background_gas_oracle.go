@@ -0,0 +1,408 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	DefaultBackgroundGasOraclePollInterval = 1 * time.Second
+	DefaultBackgroundGasOracleMaxStaleness = 1 * time.Minute
+	backgroundGasOracleHeadTimeout         = 10 * time.Second
+	backgroundGasOracleRefreshTimeout      = 30 * time.Second
+)
+
+// BackgroundGasOracleConfig controls background gas oracle, which refreshes gas suggestions once per new block
+// instead of calculating them for every transaction
+type BackgroundGasOracleConfig struct {
+	Enabled bool `toml:"enabled"`
+	// PollInterval is how often we check for a new head
+	PollInterval *Duration `toml:"poll_interval"`
+	// MaxStaleness is the maximum age of cached suggestion, older ones are recalculated on demand
+	MaxStaleness *Duration `toml:"max_staleness"`
+}
+
+// BackgroundGasOracleEnabled returns true if background gas oracle is enabled
+func (c *Config) BackgroundGasOracleEnabled() bool {
+	return c.BackgroundGasOracle != nil && c.BackgroundGasOracle.Enabled
+}
+
+// HeaderReader is implemented by clients that can read block headers, e.g. ethclient.Client
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// BackgroundGasOracleStats describes how fresh gas suggestions served by BackgroundGasOracle are
+type BackgroundGasOracleStats struct {
+	// HeadBlock is the latest block seen by the oracle
+	HeadBlock uint64
+	// LastUpdateBlock is the block, for which suggestions were last refreshed successfully
+	LastUpdateBlock uint64
+	// LastUpdate is the time of the last successful refresh
+	LastUpdate time.Time
+	// Staleness is the time elapsed since the last successful refresh
+	Staleness time.Duration
+	// BlocksBehind is the number of blocks between the head and the last successful refresh
+	BlocksBehind uint64
+	// Updates is the number of successful refreshes
+	Updates uint64
+	// FailedUpdates is the number of refreshes that failed for at least one suggestion
+	FailedUpdates uint64
+	// CacheHits is the number of requests served from the cache
+	CacheHits uint64
+	// CacheMisses is the number of requests that had to be calculated on demand
+	CacheMisses uint64
+}
+
+type gasSuggestionKey struct {
+	priority string
+	eip1559  bool
+}
+
+type cachedGasSuggestion struct {
+	mu        *sync.Mutex
+	gasPrice  *big.Int
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+	updatedAt time.Time
+	// calculating is closed, when on-demand calculation in progress finishes, nil if there's none
+	calculating chan struct{}
+}
+
+// BackgroundGasOracle wraps another GasOracle and refreshes its suggestions in the background once per new block.
+// All concurrent callers are served cached suggestions, so pricing a transaction doesn't require any RPC calls.
+// Suggestions for given priority and transaction type are calculated on demand the first time they are requested
+// (or when cached ones are older than MaxStaleness) and from then on refreshed with every new head. New heads are
+// also added to the header cache, and headers outside the estimation window are removed from it, so that congestion
+// calculation only needs to fetch headers it hasn't seen yet.
+type BackgroundGasOracle struct {
+	oracle       GasOracle
+	headReader   HeaderReader
+	headerCache  *LFUHeaderCache
+	pollInterval time.Duration
+	maxStaleness time.Duration
+
+	mu          *sync.Mutex
+	suggestions map[gasSuggestionKey]*cachedGasSuggestion
+	stats       BackgroundGasOracleStats
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewBackgroundGasOracle creates a new BackgroundGasOracle. Header cache is optional. Call Start() to begin background updates.
+func NewBackgroundGasOracle(oracle GasOracle, headReader HeaderReader, headerCache *LFUHeaderCache, cfg *BackgroundGasOracleConfig) *BackgroundGasOracle {
+	o := &BackgroundGasOracle{
+		oracle:       oracle,
+		headReader:   headReader,
+		headerCache:  headerCache,
+		pollInterval: DefaultBackgroundGasOraclePollInterval,
+		maxStaleness: DefaultBackgroundGasOracleMaxStaleness,
+		mu:           &sync.Mutex{},
+		suggestions:  make(map[gasSuggestionKey]*cachedGasSuggestion),
+	}
+
+	if cfg != nil {
+		if cfg.PollInterval != nil && cfg.PollInterval.Duration() > 0 {
+			o.pollInterval = cfg.PollInterval.Duration()
+		}
+		if cfg.MaxStaleness != nil && cfg.MaxStaleness.Duration() > 0 {
+			o.maxStaleness = cfg.MaxStaleness.Duration()
+		}
+	}
+
+	return o
+}
+
+func (o *BackgroundGasOracle) Name() string {
+	return fmt.Sprintf("background (%s)", o.oracle.Name())
+}
+
+// Start starts polling for new heads until Stop() is called or context is cancelled
+func (o *BackgroundGasOracle) Start(ctx context.Context) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.cancel != nil {
+		return
+	}
+
+	ctx, o.cancel = context.WithCancel(ctx)
+	o.done = make(chan struct{})
+
+	go func() {
+		defer close(o.done)
+
+		ticker := time.NewTicker(o.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			o.Poll(ctx)
+
+			select {
+			case <-ctx.Done():
+				L.Debug().Msg("Background gas oracle stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	L.Debug().
+		Str("Gas oracle", o.oracle.Name()).
+		Str("Poll interval", o.pollInterval.String()).
+		Str("Max staleness", o.maxStaleness.String()).
+		Msg("Started background gas oracle")
+}
+
+// Stop stops background updates and waits for the update in progress to finish
+func (o *BackgroundGasOracle) Stop() {
+	o.mu.Lock()
+	cancel, done := o.cancel, o.done
+	o.cancel = nil
+	o.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Poll checks if there's a new head and, if so, refreshes all cached suggestions. It's called periodically after Start().
+func (o *BackgroundGasOracle) Poll(ctx context.Context) {
+	headCtx, cancel := context.WithTimeout(ctx, backgroundGasOracleHeadTimeout)
+	defer cancel()
+
+	head, err := o.headReader.HeaderByNumber(headCtx, nil)
+	if err != nil {
+		L.Debug().Err(err).Msg("Background gas oracle failed to fetch latest header")
+		return
+	}
+	if head == nil || head.Number == nil {
+		return
+	}
+
+	o.mu.Lock()
+	isNew := head.Number.Uint64() > o.stats.HeadBlock
+	if isNew {
+		o.stats.HeadBlock = head.Number.Uint64()
+	}
+	o.mu.Unlock()
+
+	if !isNew {
+		return
+	}
+
+	if o.headerCache != nil {
+		o.headerCache.EvictOlderThan(head.Number.Int64() - int64(o.headerCache.Capacity()) + 1)
+		_ = o.headerCache.Set(head)
+	}
+
+	o.refresh(ctx, head.Number.Uint64())
+}
+
+// refresh recalculates all suggestions that were requested so far. Previous suggestions are served until new ones are ready.
+func (o *BackgroundGasOracle) refresh(ctx context.Context, blockNumber uint64) {
+	ctx, cancel := context.WithTimeout(ctx, backgroundGasOracleRefreshTimeout)
+	defer cancel()
+
+	o.mu.Lock()
+	keys := make([]gasSuggestionKey, 0, len(o.suggestions))
+	for key := range o.suggestions {
+		keys = append(keys, key)
+	}
+	o.mu.Unlock()
+
+	var failed bool
+	for _, key := range keys {
+		if err := o.update(ctx, key, o.entry(key)); err != nil {
+			failed = true
+			L.Warn().
+				Err(err).
+				Str("Priority", key.priority).
+				Bool("EIP-1559", key.eip1559).
+				Uint64("Block", blockNumber).
+				Msg("Background gas oracle failed to refresh suggestion. Previous one will be used until it becomes stale")
+		}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if failed {
+		o.stats.FailedUpdates++
+		return
+	}
+	o.stats.Updates++
+	o.stats.LastUpdate = time.Now()
+	o.stats.LastUpdateBlock = blockNumber
+}
+
+func (o *BackgroundGasOracle) SuggestEIP1559Fees(ctx context.Context, priority string) (*big.Int, *big.Int, error) {
+	s, err := o.get(ctx, gasSuggestionKey{priority: priority, eip1559: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.gasFeeCap, s.gasTipCap, nil
+}
+
+func (o *BackgroundGasOracle) SuggestLegacyFees(ctx context.Context, priority string) (*big.Int, error) {
+	s, err := o.get(ctx, gasSuggestionKey{priority: priority, eip1559: false})
+	if err != nil {
+		return nil, err
+	}
+	return s.gasPrice, nil
+}
+
+// Stats returns current staleness metrics
+func (o *BackgroundGasOracle) Stats() BackgroundGasOracleStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	stats := o.stats
+	if !stats.LastUpdate.IsZero() {
+		stats.Staleness = time.Since(stats.LastUpdate)
+	}
+	if stats.HeadBlock > stats.LastUpdateBlock {
+		stats.BlocksBehind = stats.HeadBlock - stats.LastUpdateBlock
+	}
+
+	return stats
+}
+
+// get returns a copy of cached suggestion, calculating it first if it's missing or stale. Concurrent callers
+// requesting the same missing suggestion wait for a single calculation. No lock is held while calculating.
+func (o *BackgroundGasOracle) get(ctx context.Context, key gasSuggestionKey) (cachedGasSuggestion, error) {
+	entry := o.entry(key)
+
+	for {
+		entry.mu.Lock()
+		if !entry.updatedAt.IsZero() && time.Since(entry.updatedAt) <= o.maxStaleness {
+			s := entry.copy()
+			entry.mu.Unlock()
+
+			o.mu.Lock()
+			o.stats.CacheHits++
+			o.mu.Unlock()
+
+			return s, nil
+		}
+
+		calculating := entry.calculating
+		if calculating == nil {
+			// entry's lock is still held, it's released once we mark calculation as in progress
+			break
+		}
+		entry.mu.Unlock()
+
+		select {
+		case <-calculating:
+		case <-ctx.Done():
+			return cachedGasSuggestion{}, ctx.Err()
+		}
+	}
+
+	calculating := make(chan struct{})
+	entry.calculating = calculating
+	entry.mu.Unlock()
+
+	o.mu.Lock()
+	o.stats.CacheMisses++
+	o.mu.Unlock()
+
+	s, err := o.calculate(ctx, key)
+
+	entry.mu.Lock()
+	if err == nil {
+		entry.store(s)
+	}
+	entry.calculating = nil
+	close(calculating)
+	entry.mu.Unlock()
+
+	if err != nil {
+		return cachedGasSuggestion{}, err
+	}
+	return s.copy(), nil
+}
+
+func (o *BackgroundGasOracle) entry(key gasSuggestionKey) *cachedGasSuggestion {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry, ok := o.suggestions[key]
+	if !ok {
+		entry = &cachedGasSuggestion{mu: &sync.Mutex{}}
+		o.suggestions[key] = entry
+	}
+
+	return entry
+}
+
+// update calculates a new suggestion and swaps it into the entry
+func (o *BackgroundGasOracle) update(ctx context.Context, key gasSuggestionKey, entry *cachedGasSuggestion) error {
+	s, err := o.calculate(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.store(s)
+
+	return nil
+}
+
+// calculate asks wrapped oracle for a new suggestion
+func (o *BackgroundGasOracle) calculate(ctx context.Context, key gasSuggestionKey) (cachedGasSuggestion, error) {
+	var s cachedGasSuggestion
+	if key.eip1559 {
+		gasFeeCap, gasTipCap, err := o.oracle.SuggestEIP1559Fees(ctx, key.priority)
+		if err != nil {
+			return s, err
+		}
+		s.gasFeeCap, s.gasTipCap = gasFeeCap, gasTipCap
+	} else {
+		gasPrice, err := o.oracle.SuggestLegacyFees(ctx, key.priority)
+		if err != nil {
+			return s, err
+		}
+		s.gasPrice = gasPrice
+	}
+	s.updatedAt = time.Now()
+
+	return s, nil
+}
+
+// store replaces suggested values with the ones from given suggestion. Must be called with entry's lock held.
+func (s *cachedGasSuggestion) store(from cachedGasSuggestion) {
+	s.gasPrice, s.gasFeeCap, s.gasTipCap, s.updatedAt = from.gasPrice, from.gasFeeCap, from.gasTipCap, from.updatedAt
+}
+
+func (s *cachedGasSuggestion) copy() cachedGasSuggestion {
+	c := cachedGasSuggestion{updatedAt: s.updatedAt}
+	if s.gasPrice != nil {
+		c.gasPrice = new(big.Int).Set(s.gasPrice)
+	}
+	if s.gasFeeCap != nil {
+		c.gasFeeCap = new(big.Int).Set(s.gasFeeCap)
+	}
+	if s.gasTipCap != nil {
+		c.gasTipCap = new(big.Int).Set(s.gasTipCap)
+	}
+	return c
+}
+
+// BackgroundGasOracleStats returns staleness metrics of the background gas oracle. Second value is false if background gas oracle is not used.
+func (m *Client) BackgroundGasOracleStats() (BackgroundGasOracleStats, bool) {
+	o, ok := m.GasOracle.(*BackgroundGasOracle)
+	if !ok {
+		return BackgroundGasOracleStats{}, false
+	}
+	return o.Stats(), true
+}
@@ -0,0 +1,266 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+type headReader struct {
+	head atomic.Int64
+}
+
+func (h *headReader) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(h.head.Load()), GasLimit: 30_000_000}, nil
+}
+
+type countingGasOracle struct {
+	calls  atomic.Int64
+	failed atomic.Bool
+}
+
+func (c *countingGasOracle) Name() string {
+	return "counting"
+}
+
+func (c *countingGasOracle) SuggestEIP1559Fees(_ context.Context, _ string) (*big.Int, *big.Int, error) {
+	n := c.calls.Add(1)
+	if c.failed.Load() {
+		return nil, nil, errors.New("oracle failed")
+	}
+	// slow oracle, so that concurrent callers have a chance to pile up
+	time.Sleep(10 * time.Millisecond)
+	return big.NewInt(100 * n), big.NewInt(n), nil
+}
+
+func (c *countingGasOracle) SuggestLegacyFees(_ context.Context, _ string) (*big.Int, error) {
+	n := c.calls.Add(1)
+	if c.failed.Load() {
+		return nil, errors.New("oracle failed")
+	}
+	return big.NewInt(10 * n), nil
+}
+
+func TestBackgroundGasOracleServesCachedSuggestionsToConcurrentCallers(t *testing.T) {
+	inner := &countingGasOracle{}
+	heads := &headReader{}
+	heads.head.Store(10)
+	oracle := seth.NewBackgroundGasOracle(inner, heads, nil, &seth.BackgroundGasOracleConfig{Enabled: true})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feeCap, tipCap, err := oracle.SuggestEIP1559Fees(context.Background(), seth.Priority_Standard)
+			require.NoError(t, err, "failed to get suggested fees")
+			require.Equal(t, big.NewInt(100), feeCap, "incorrect fee cap")
+			require.Equal(t, big.NewInt(1), tipCap, "incorrect tip cap")
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(1), inner.calls.Load(), "suggestion should be calculated only once")
+
+	stats := oracle.Stats()
+	require.Equal(t, uint64(1), stats.CacheMisses, "only first request should be a cache miss")
+	require.Equal(t, uint64(19), stats.CacheHits, "all other requests should be served from cache")
+
+	// new head refreshes all requested suggestions, same head doesn't
+	oracle.Poll(context.Background())
+	oracle.Poll(context.Background())
+	require.Equal(t, int64(2), inner.calls.Load(), "suggestion should be refreshed once per new head")
+
+	feeCap, _, err := oracle.SuggestEIP1559Fees(context.Background(), seth.Priority_Standard)
+	require.NoError(t, err, "failed to get suggested fees")
+	require.Equal(t, big.NewInt(200), feeCap, "refreshed fee cap should be served")
+
+	stats = oracle.Stats()
+	require.Equal(t, uint64(10), stats.HeadBlock, "incorrect head block")
+	require.Equal(t, uint64(10), stats.LastUpdateBlock, "incorrect last update block")
+	require.Equal(t, uint64(0), stats.BlocksBehind, "suggestions should be up to date")
+	require.Equal(t, uint64(1), stats.Updates, "incorrect number of updates")
+}
+
+func TestBackgroundGasOracleReportsStalenessWhenRefreshFails(t *testing.T) {
+	inner := &countingGasOracle{}
+	heads := &headReader{}
+	heads.head.Store(1)
+	oracle := seth.NewBackgroundGasOracle(inner, heads, nil, &seth.BackgroundGasOracleConfig{Enabled: true, MaxStaleness: seth.MustMakeDuration(time.Hour)})
+
+	gasPrice, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to get suggested gas price")
+	oracle.Poll(context.Background())
+
+	inner.failed.Store(true)
+	heads.head.Store(4)
+	oracle.Poll(context.Background())
+
+	cached, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "previous suggestion should be served, when refresh fails")
+	require.Equal(t, big.NewInt(20), cached, "suggestion from the last successful refresh should be used")
+	require.Equal(t, big.NewInt(10), gasPrice, "returned values should not be modified by refresh")
+
+	stats := oracle.Stats()
+	require.Equal(t, uint64(1), stats.LastUpdateBlock, "incorrect last update block")
+	require.Equal(t, uint64(3), stats.BlocksBehind, "incorrect number of blocks behind")
+	require.Equal(t, uint64(1), stats.FailedUpdates, "failed update should be counted")
+	require.Greater(t, stats.Staleness, time.Duration(0), "staleness should be reported")
+}
+
+func TestBackgroundGasOracleKeepsHeaderCacheWithinEstimationWindow(t *testing.T) {
+	cache := seth.NewLFUBlockCache(3)
+	heads := &headReader{}
+	oracle := seth.NewBackgroundGasOracle(&countingGasOracle{}, heads, cache, nil)
+
+	for i := int64(1); i <= 5; i++ {
+		heads.head.Store(i)
+		oracle.Poll(context.Background())
+	}
+
+	for i := int64(1); i <= 2; i++ {
+		_, ok := cache.Get(i)
+		require.False(t, ok, "header %d outside of estimation window should be evicted", i)
+	}
+	for i := int64(3); i <= 5; i++ {
+		_, ok := cache.Get(i)
+		require.True(t, ok, "header %d should be cached", i)
+	}
+}
+
+func TestBackgroundGasOracleStopsBackgroundUpdates(t *testing.T) {
+	heads := &headReader{}
+	heads.head.Store(1)
+	oracle := seth.NewBackgroundGasOracle(&countingGasOracle{}, heads, nil, &seth.BackgroundGasOracleConfig{Enabled: true, PollInterval: seth.MustMakeDuration(5 * time.Millisecond)})
+
+	oracle.Start(context.Background())
+	heads.head.Store(7)
+	require.Eventually(t, func() bool {
+		return oracle.Stats().HeadBlock == 7
+	}, time.Second, 5*time.Millisecond, "new head should be picked up by background updates")
+
+	oracle.Stop()
+	heads.head.Store(8)
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, uint64(7), oracle.Stats().HeadBlock, "no updates should happen after stop")
+}
+
+// blockingGasOracle doesn't return until it's released or context is done
+type blockingGasOracle struct {
+	countingGasOracle
+	blocked atomic.Bool
+	release chan struct{}
+}
+
+func (b *blockingGasOracle) SuggestLegacyFees(ctx context.Context, priority string) (*big.Int, error) {
+	if b.blocked.Load() {
+		select {
+		case <-b.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return b.countingGasOracle.SuggestLegacyFees(ctx, priority)
+}
+
+func TestBackgroundGasOracleServesCachedSuggestionDuringRefresh(t *testing.T) {
+	inner := &blockingGasOracle{release: make(chan struct{})}
+	heads := &headReader{}
+	heads.head.Store(1)
+	oracle := seth.NewBackgroundGasOracle(inner, heads, nil, &seth.BackgroundGasOracleConfig{Enabled: true, MaxStaleness: seth.MustMakeDuration(time.Hour)})
+
+	_, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to get suggested gas price")
+
+	inner.blocked.Store(true)
+	heads.head.Store(2)
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		oracle.Poll(context.Background())
+	}()
+
+	require.Eventually(t, func() bool {
+		return oracle.Stats().HeadBlock == 2
+	}, time.Second, time.Millisecond, "new head should be picked up")
+
+	gasPrice, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "cached suggestion should be served, while refresh is in progress")
+	require.Equal(t, big.NewInt(10), gasPrice, "previous suggestion should be served until refresh finishes")
+
+	close(inner.release)
+	<-polled
+
+	gasPrice, err = oracle.SuggestLegacyFees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to get suggested gas price")
+	require.Equal(t, big.NewInt(20), gasPrice, "refreshed suggestion should be served")
+}
+
+func TestBackgroundGasOracleStopCancelsRefreshInProgress(t *testing.T) {
+	inner := &blockingGasOracle{release: make(chan struct{})}
+	heads := &headReader{}
+	heads.head.Store(1)
+	oracle := seth.NewBackgroundGasOracle(inner, heads, nil, &seth.BackgroundGasOracleConfig{Enabled: true, PollInterval: seth.MustMakeDuration(5 * time.Millisecond)})
+
+	_, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to get suggested gas price")
+
+	// wrapped oracle never returns on its own, so refresh only ends, when its context is cancelled
+	inner.blocked.Store(true)
+	heads.head.Store(2)
+	oracle.Start(context.Background())
+	require.Eventually(t, func() bool {
+		return oracle.Stats().HeadBlock == 2
+	}, time.Second, time.Millisecond, "new head should be picked up")
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		oracle.Stop()
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop should not wait for refresh that never finishes")
+	}
+	require.Equal(t, uint64(1), oracle.Stats().FailedUpdates, "cancelled refresh should be counted as failed")
+}
+
+func TestCongestionMetricOnlyFetchesHeadersMissingFromCache(t *testing.T) {
+	node := newFakeNode(t)
+	node.head = 20
+	c := newFakeNodeClient(t, node, withConfig(func(cfg *seth.Config) {
+		cfg.Network.GasPriceEstimationEnabled = true
+		cfg.Network.GasPriceEstimationBlocks = 10
+	}))
+	blockCalls := func() int {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		return node.blockCalls
+	}
+
+	before := blockCalls()
+	_, err := c.CalculateNetworkCongestionMetric(10, seth.CongestionStrategy_NewestFirst)
+	require.NoError(t, err, "failed to calculate congestion metric")
+	require.Equal(t, 10, blockCalls()-before, "whole window should be fetched the first time")
+
+	before = blockCalls()
+	_, err = c.CalculateNetworkCongestionMetric(10, seth.CongestionStrategy_NewestFirst)
+	require.NoError(t, err, "failed to calculate congestion metric")
+	require.Equal(t, 0, blockCalls()-before, "no headers should be fetched for the same head")
+
+	node.newBlock()
+	before = blockCalls()
+	_, err = c.CalculateNetworkCongestionMetric(10, seth.CongestionStrategy_NewestFirst)
+	require.NoError(t, err, "failed to calculate congestion metric")
+	require.Equal(t, 1, blockCalls()-before, "only new head should be fetched")
+}
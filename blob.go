@@ -0,0 +1,288 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrNoBlobs           = "blob transaction must contain at least one blob"
+	ErrTooManyBlobs      = "too many blobs in a single transaction"
+	ErrBlobCommitment    = "failed to compute KZG commitment"
+	ErrBlobProof         = "failed to compute KZG proof"
+	ErrNoBlobBaseFee     = "latest block has no excess blob gas, network doesn't support blob transactions"
+	ErrBlobTxNoDynamic   = "blob transactions require EIP-1559 dynamic fees to be enabled"
+	ErrSendBlobTx        = "failed to send blob transaction"
+	ErrSignBlobTx        = "failed to sign blob transaction"
+	ErrEstimateBlobTxGas = "failed to estimate gas for blob transaction"
+	ErrBlobTxUint256     = "blob transaction field must be a non-negative integer that fits into 256 bits"
+)
+
+const (
+	// MaxBlobsPerTransaction is the maximum number of blobs that fit into a single block (and thus a transaction)
+	MaxBlobsPerTransaction = params.MaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob
+	// usableBytesPerFieldElement is the number of bytes we can store in a field element without exceeding BLS modulus
+	usableBytesPerFieldElement = params.BlobTxBytesPerFieldElement - 1
+	// BlobDataCapacity is the number of bytes of arbitrary data that EncodeBlobs() can store in a single blob
+	BlobDataCapacity = params.BlobTxFieldElementsPerBlob * usableBytesPerFieldElement
+	// BlobFeeCapMultiplier is used to multiply blob base fee of the next block, when suggesting blob fee cap, so that
+	// transaction remains includable even if blob base fee keeps rising for a few blocks
+	BlobFeeCapMultiplier = 2
+)
+
+// BlobTxRequest describes a blob transaction to be built. If BlobFeeCap is nil, it's suggested based on the latest header.
+type BlobTxRequest struct {
+	To         common.Address
+	Value      *big.Int
+	Data       []byte
+	Blobs      []kzg4844.Blob
+	BlobFeeCap *big.Int
+}
+
+// EncodeBlobs splits arbitrary data into as many blobs as needed. Each 32-byte field element holds 31 bytes of data
+// with the most significant byte set to zero, so that it's always lower than the BLS modulus. Last blob is zero-padded.
+func EncodeBlobs(data []byte) ([]kzg4844.Blob, error) {
+	if len(data) == 0 {
+		return nil, errors.New(ErrNoBlobs)
+	}
+
+	blobCount := (len(data) + BlobDataCapacity - 1) / BlobDataCapacity
+	if blobCount > MaxBlobsPerTransaction {
+		return nil, fmt.Errorf("%s: data needs %d blobs, but at most %d are allowed", ErrTooManyBlobs, blobCount, MaxBlobsPerTransaction)
+	}
+
+	blobs := make([]kzg4844.Blob, blobCount)
+	for i := range blobs {
+		chunk := data[i*BlobDataCapacity:]
+		if len(chunk) > BlobDataCapacity {
+			chunk = chunk[:BlobDataCapacity]
+		}
+		for fe := 0; fe*usableBytesPerFieldElement < len(chunk); fe++ {
+			end := (fe + 1) * usableBytesPerFieldElement
+			if end > len(chunk) {
+				end = len(chunk)
+			}
+			copy(blobs[i][fe*params.BlobTxBytesPerFieldElement+1:], chunk[fe*usableBytesPerFieldElement:end])
+		}
+	}
+
+	return blobs, nil
+}
+
+// NewBlobTxSidecar computes KZG commitments and proofs for given blobs and returns a sidecar ready to be attached to a blob transaction
+func NewBlobTxSidecar(blobs []kzg4844.Blob) (*types.BlobTxSidecar, error) {
+	if len(blobs) == 0 {
+		return nil, errors.New(ErrNoBlobs)
+	}
+	if len(blobs) > MaxBlobsPerTransaction {
+		return nil, fmt.Errorf("%s: got %d blobs, but at most %d are allowed", ErrTooManyBlobs, len(blobs), MaxBlobsPerTransaction)
+	}
+
+	sidecar := &types.BlobTxSidecar{
+		Blobs:       make([]kzg4844.Blob, 0, len(blobs)),
+		Commitments: make([]kzg4844.Commitment, 0, len(blobs)),
+		Proofs:      make([]kzg4844.Proof, 0, len(blobs)),
+	}
+	for i, blob := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(blob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s for blob %d", ErrBlobCommitment, i)
+		}
+		proof, err := kzg4844.ComputeBlobProof(blob, commitment)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s for blob %d", ErrBlobProof, i)
+		}
+		sidecar.Blobs = append(sidecar.Blobs, blob)
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+
+	return sidecar, nil
+}
+
+// toBlobTxUint256 converts value of a blob transaction field to uint256. Unlike uint256.MustFromBig it doesn't panic
+// on overflow and rejects negative values instead of silently wrapping them around.
+func toBlobTxUint256(field string, value *big.Int) (*uint256.Int, error) {
+	if value == nil {
+		return new(uint256.Int), nil
+	}
+	if value.Sign() < 0 {
+		return nil, fmt.Errorf("%s: %s is %s", ErrBlobTxUint256, field, value.String())
+	}
+	converted, overflow := uint256.FromBig(value)
+	if overflow {
+		return nil, fmt.Errorf("%s: %s is %s", ErrBlobTxUint256, field, value.String())
+	}
+	return converted, nil
+}
+
+// CalculateNextBlobBaseFee returns blob base fee of the block following the given one
+func CalculateNextBlobBaseFee(header *types.Header) (*big.Int, error) {
+	if header == nil || header.ExcessBlobGas == nil {
+		return nil, errors.New(ErrNoBlobBaseFee)
+	}
+
+	var blobGasUsed uint64
+	if header.BlobGasUsed != nil {
+		blobGasUsed = *header.BlobGasUsed
+	}
+
+	return eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*header.ExcessBlobGas, blobGasUsed)), nil
+}
+
+// SuggestBlobFeeCap suggests max fee per blob gas based on the latest header
+func (m *Client) SuggestBlobFeeCap(ctx context.Context) (*big.Int, error) {
+	header, err := m.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	blobBaseFee, err := CalculateNextBlobBaseFee(header)
+	if err != nil {
+		return nil, err
+	}
+
+	blobFeeCap := new(big.Int).Mul(blobBaseFee, big.NewInt(BlobFeeCapMultiplier))
+
+	L.Debug().
+		Str("NextBlobBaseFee", fmt.Sprintf("%s wei / %s ether", blobBaseFee.String(), WeiToEther(blobBaseFee).Text('f', -1))).
+		Str("BlobFeeCap", fmt.Sprintf("%s wei / %s ether", blobFeeCap.String(), WeiToEther(blobFeeCap).Text('f', -1))).
+		Msg("Suggested blob fee cap")
+
+	return blobFeeCap, nil
+}
+
+// NewBlobTx builds and signs a blob transaction from key with given number. Nonce, gas fee cap, tip cap and gas limit
+// are set the same way as for any other transaction (see NewTXKeyOpts) and can be overridden with transaction options.
+// Returned transaction contains the sidecar with blobs, commitments and proofs.
+func (m *Client) NewBlobTx(keyNum int, request BlobTxRequest, o ...TransactOpt) (*types.Transaction, error) {
+	if !m.Cfg.Network.EIP1559DynamicFees {
+		return nil, errors.New(ErrBlobTxNoDynamic)
+	}
+
+	sidecar, err := NewBlobTxSidecar(request.Blobs)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := m.NewTXKeyOpts(keyNum, o...)
+	if opts.Context != nil {
+		if err, ok := opts.Context.Value(ContextErrorKey{}).(error); ok {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+
+	value := request.Value
+	if value == nil {
+		value = opts.Value
+	}
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	blobFeeCap := request.BlobFeeCap
+	if blobFeeCap == nil {
+		blobFeeCap, err = m.SuggestBlobFeeCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	gasLimit := opts.GasLimit
+	if gasLimit == 0 {
+		gasLimit, err = m.Client.EstimateGas(ctx, ethereum.CallMsg{
+			From:      opts.From,
+			To:        &request.To,
+			GasFeeCap: opts.GasFeeCap,
+			GasTipCap: opts.GasTipCap,
+			Value:     value,
+			Data:      request.Data,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, ErrEstimateBlobTxGas)
+		}
+	}
+
+	gasTipCap, err := toBlobTxUint256("gas tip cap", opts.GasTipCap)
+	if err != nil {
+		return nil, err
+	}
+	gasFeeCap, err := toBlobTxUint256("gas fee cap", opts.GasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	txValue, err := toBlobTxUint256("value", value)
+	if err != nil {
+		return nil, err
+	}
+	txBlobFeeCap, err := toBlobTxUint256("blob fee cap", blobFeeCap)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID := big.NewInt(m.ChainID)
+	txData := &types.BlobTx{
+		ChainID:    uint256.MustFromBig(chainID),
+		Nonce:      opts.Nonce.Uint64(),
+		GasTipCap:  gasTipCap,
+		GasFeeCap:  gasFeeCap,
+		Gas:        gasLimit,
+		To:         request.To,
+		Value:      txValue,
+		Data:       request.Data,
+		BlobFeeCap: txBlobFeeCap,
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	}
+
+	tx, err := types.SignNewTx(m.PrivateKeys[keyNum], types.LatestSignerForChainID(chainID), txData)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrSignBlobTx)
+	}
+
+	L.Debug().
+		Int("KeyNum", keyNum).
+		Uint64("Nonce", tx.Nonce()).
+		Int("Blobs", len(sidecar.Blobs)).
+		Interface("GasFeeCap", tx.GasFeeCap()).
+		Interface("GasTipCap", tx.GasTipCap()).
+		Interface("BlobFeeCap", tx.BlobGasFeeCap()).
+		Uint64("GasLimit", tx.Gas()).
+		Msg("New blob transaction")
+
+	return tx, nil
+}
+
+// SendBlobTx builds, signs and sends a blob transaction from key with given number and decodes it.
+// If gas bumping is enabled, replacement transactions keep the original sidecar.
+func (m *Client) SendBlobTx(keyNum int, request BlobTxRequest, o ...TransactOpt) (*DecodedTransaction, error) {
+	tx, err := m.NewBlobTx(keyNum, request, o...)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+
+	if err := m.Client.SendTransaction(ctx, tx); err != nil {
+		return m.Decode(nil, errors.Wrap(err, ErrSendBlobTx))
+	}
+	if m.StuckTxWatchdog != nil {
+		m.StuckTxWatchdog.Track(tx)
+	}
+
+	return m.Decode(tx, nil)
+}
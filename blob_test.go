@@ -0,0 +1,152 @@
+package seth_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func TestBlobEncodeBlobs(t *testing.T) {
+	data := bytes.Repeat([]byte{0xff}, seth.BlobDataCapacity+40)
+
+	blobs, err := seth.EncodeBlobs(data)
+	require.NoError(t, err, "failed to encode blobs")
+	require.Len(t, blobs, 2, "data should be split into 2 blobs")
+
+	// every field element has the most significant byte set to zero
+	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
+		require.Equal(t, byte(0), blobs[0][i*params.BlobTxBytesPerFieldElement], "field element %d exceeds BLS modulus", i)
+	}
+	require.Equal(t, bytes.Repeat([]byte{0xff}, 31), blobs[1][1:32], "first field element of the second blob should be full")
+	require.Equal(t, append([]byte{0x00}, bytes.Repeat([]byte{0xff}, 9)...), blobs[1][32:42], "second field element of the second blob should hold the remaining 9 bytes")
+	require.Equal(t, make([]byte, 32), blobs[1][64:96], "rest of the last blob should be zero-padded")
+
+	_, err = seth.EncodeBlobs(make([]byte, seth.MaxBlobsPerTransaction*seth.BlobDataCapacity+1))
+	require.ErrorContains(t, err, seth.ErrTooManyBlobs, "too much data should be rejected")
+
+	_, err = seth.EncodeBlobs(nil)
+	require.EqualError(t, err, seth.ErrNoBlobs, "empty data should be rejected")
+}
+
+func TestBlobNewBlobTxSidecar(t *testing.T) {
+	blobs, err := seth.EncodeBlobs([]byte("rollup batch"))
+	require.NoError(t, err, "failed to encode blobs")
+
+	sidecar, err := seth.NewBlobTxSidecar(blobs)
+	require.NoError(t, err, "failed to create sidecar")
+	require.Len(t, sidecar.Commitments, 1, "there should be one commitment per blob")
+	require.Len(t, sidecar.Proofs, 1, "there should be one proof per blob")
+	require.NoError(t, kzg4844.VerifyBlobProof(sidecar.Blobs[0], sidecar.Commitments[0], sidecar.Proofs[0]), "proof should be valid")
+
+	hashes := sidecar.BlobHashes()
+	require.Len(t, hashes, 1, "there should be one versioned hash per blob")
+	require.Equal(t, byte(0x01), hashes[0][0], "versioned hash should use KZG version")
+
+	// blob with field element bigger than BLS modulus
+	var invalid kzg4844.Blob
+	copy(invalid[:], bytes.Repeat([]byte{0xff}, 32))
+	_, err = seth.NewBlobTxSidecar([]kzg4844.Blob{invalid})
+	require.ErrorContains(t, err, seth.ErrBlobCommitment, "invalid blob should be rejected")
+}
+
+func TestBlobCalculateNextBlobBaseFee(t *testing.T) {
+	_, err := seth.CalculateNextBlobBaseFee(&types.Header{Number: big.NewInt(1)})
+	require.EqualError(t, err, seth.ErrNoBlobBaseFee, "pre-Cancun header should be rejected")
+
+	excess, used := uint64(0), uint64(0)
+	fee, err := seth.CalculateNextBlobBaseFee(&types.Header{ExcessBlobGas: &excess, BlobGasUsed: &used})
+	require.NoError(t, err, "failed to calculate blob base fee")
+	require.Equal(t, big.NewInt(params.BlobTxMinBlobGasprice), fee, "blob base fee without excess blob gas should be minimal")
+
+	excess, used = 10*params.BlobTxTargetBlobGasPerBlock, params.MaxBlobGasPerBlock
+	fee, err = seth.CalculateNextBlobBaseFee(&types.Header{ExcessBlobGas: &excess, BlobGasUsed: &used})
+	require.NoError(t, err, "failed to calculate blob base fee")
+	require.Equal(t, 1, fee.Cmp(big.NewInt(params.BlobTxMinBlobGasprice)), "blob base fee should grow with excess blob gas")
+}
+
+func newBlobTxRequest(t *testing.T) seth.BlobTxRequest {
+	blobs, err := seth.EncodeBlobs([]byte("rollup batch"))
+	require.NoError(t, err, "failed to encode blobs")
+	return seth.BlobTxRequest{
+		To:         common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+		Blobs:      blobs,
+		BlobFeeCap: big.NewInt(10),
+	}
+}
+
+func TestBlobNewBlobTx(t *testing.T) {
+	c := newFakeNodeClient(t, newFakeNode(t), withEIP1559(true), withTxnTimeout(500*time.Millisecond))
+	request := newBlobTxRequest(t)
+
+	tx, err := c.NewBlobTx(0, request)
+	require.NoError(t, err, "failed to create blob transaction")
+	require.Equal(t, uint8(types.BlobTxType), tx.Type(), "incorrect transaction type")
+	require.Equal(t, big.NewInt(fakeNodeGasFeeCap), tx.GasFeeCap(), "incorrect gas fee cap")
+	require.Equal(t, big.NewInt(fakeNodeGasTipCap), tx.GasTipCap(), "incorrect gas tip cap")
+	require.Equal(t, big.NewInt(10), tx.BlobGasFeeCap(), "incorrect blob fee cap")
+	require.Equal(t, big.NewInt(0), tx.Value(), "value should default to zero")
+	require.NotNil(t, tx.BlobTxSidecar(), "transaction should carry the sidecar")
+	require.Equal(t, tx.BlobTxSidecar().BlobHashes(), tx.BlobHashes(), "blob hashes should match the sidecar")
+
+	request.Value = big.NewInt(-1)
+	_, err = c.NewBlobTx(0, request)
+	require.ErrorContains(t, err, seth.ErrBlobTxUint256, "negative value should be rejected")
+
+	request.Value = new(big.Int).Lsh(big.NewInt(1), 256)
+	_, err = c.NewBlobTx(0, request)
+	require.ErrorContains(t, err, seth.ErrBlobTxUint256, "value that doesn't fit into 256 bits should be rejected")
+}
+
+func TestBlobSendBlobTx(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withEIP1559(true), withTxnTimeout(500*time.Millisecond), withAutoMine())
+
+	decoded, err := c.SendBlobTx(0, newBlobTxRequest(t))
+	require.NoError(t, err, "failed to send blob transaction")
+	require.Equal(t, types.ReceiptStatusSuccessful, decoded.Receipt.Status, "transaction should be mined")
+
+	sent := node.sentTxs()
+	require.Len(t, sent, 1, "exactly one transaction should be sent")
+	require.Equal(t, decoded.Transaction.Hash(), sent[0].Hash(), "decoded transaction should be the sent one")
+	require.NotNil(t, sent[0].BlobTxSidecar(), "sidecar should be sent to the node")
+	sidecar := sent[0].BlobTxSidecar()
+	for i := range sidecar.Blobs {
+		require.NoError(t, kzg4844.VerifyBlobProof(sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]), "blob proof should be valid")
+	}
+}
+
+func TestBlobGasBumpKeepsSidecar(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withEIP1559(true), withTxnTimeout(500*time.Millisecond), withGasBump(&seth.GasBumpConfig{Retries: 2}))
+
+	tx, err := c.NewBlobTx(0, newBlobTxRequest(t))
+	require.NoError(t, err, "failed to create blob transaction")
+	require.NoError(t, c.Client.SendTransaction(context.Background(), tx), "failed to send blob transaction")
+	// original transaction stays pending, replacement is mined as soon as it's sent
+	node.mu.Lock()
+	node.autoMine = true
+	node.mu.Unlock()
+
+	decoded, err := c.Decode(tx, nil)
+	require.NoError(t, err, "failed to decode replacement transaction")
+
+	sent := node.sentTxs()
+	require.Len(t, sent, 2, "original and replacement transactions should be sent")
+	replacement := sent[1]
+	require.Equal(t, replacement.Hash(), decoded.Transaction.Hash(), "replacement should be mined")
+	require.Equal(t, tx.Nonce(), replacement.Nonce(), "replacement should use the same nonce")
+	require.Equal(t, 1, replacement.BlobGasFeeCap().Cmp(tx.BlobGasFeeCap()), "blob fee cap should be bumped")
+	require.NotNil(t, replacement.BlobTxSidecar(), "replacement should carry the sidecar")
+	require.Equal(t, tx.BlobTxSidecar().Blobs, replacement.BlobTxSidecar().Blobs, "replacement should keep original blobs")
+	require.Equal(t, tx.BlobHashes(), replacement.BlobHashes(), "replacement should keep original blob hashes")
+}
@@ -40,14 +40,55 @@ func NewBlockStats(c *Client) (*BlockStats, error) {
 	}, nil
 }
 
+// BlockStatsResult contains statistics of blocks from given interval. Durations are formatted as Go durations, e.g. "2.5s"
+type BlockStatsResult struct {
+	StartBlock                 uint64  `toml:"start_block" json:"start_block"`
+	EndBlock                   uint64  `toml:"end_block" json:"end_block"`
+	Blocks                     int     `toml:"blocks" json:"blocks"`
+	Duration                   string  `toml:"duration" json:"duration"`
+	Perc95TPS                  float64 `toml:"perc_95_tps" json:"perc_95_tps"`
+	Perc95BlockDuration        string  `toml:"perc_95_block_duration" json:"perc_95_block_duration"`
+	Perc95BlockGasUsed         uint64  `toml:"perc_95_block_gas_used" json:"perc_95_block_gas_used"`
+	Perc95BlockGasLimit        uint64  `toml:"perc_95_block_gas_limit" json:"perc_95_block_gas_limit"`
+	Perc95BlockBaseFee         uint64  `toml:"perc_95_block_base_fee" json:"perc_95_block_base_fee"`
+	Perc95BlockSize            uint64  `toml:"perc_95_block_size" json:"perc_95_block_size"`
+	AvgTPS                     float64 `toml:"avg_tps" json:"avg_tps"`
+	AvgBlockDuration           string  `toml:"avg_block_duration" json:"avg_block_duration"`
+	AvgBlockGasUsed            uint64  `toml:"avg_block_gas_used" json:"avg_block_gas_used"`
+	AvgBlockGasLimit           uint64  `toml:"avg_block_gas_limit" json:"avg_block_gas_limit"`
+	AvgBlockBaseFee            uint64  `toml:"avg_block_base_fee" json:"avg_block_base_fee"`
+	AvgBlockSize               uint64  `toml:"avg_block_size" json:"avg_block_size"`
+	AvgBlockGasUsagePercentage float64 `toml:"avg_block_gas_usage_percentage" json:"avg_block_gas_usage_percentage"`
+	// RequiredGasBumpPercentage is the ratio of 95th percentile and average base fee, 100% means no bump is required
+	RequiredGasBumpPercentage float64 `toml:"required_gas_bump_percentage" json:"required_gas_bump_percentage"`
+}
+
 // Stats fetches and logs the blocks' statistics from startBlock to endBlock
 func (cs *BlockStats) Stats(startBlock *big.Int, endBlock *big.Int) error {
+	blocks, err := cs.fetchBlocks(startBlock, endBlock)
+	if err != nil {
+		return err
+	}
+	return cs.CalculateBlockDurations(blocks)
+}
+
+// StatsResult fetches blocks from startBlock to endBlock and returns their statistics without logging the summary
+func (cs *BlockStats) StatsResult(startBlock *big.Int, endBlock *big.Int) (*BlockStatsResult, error) {
+	blocks, err := cs.fetchBlocks(startBlock, endBlock)
+	if err != nil {
+		return nil, err
+	}
+	return cs.CalculateBlockStats(blocks)
+}
+
+// fetchBlocks fetches blocks from startBlock to endBlock sorted by number. Negative startBlock is relative to the latest block
+func (cs *BlockStats) fetchBlocks(startBlock *big.Int, endBlock *big.Int) ([]*types.Block, error) {
 	// Get the latest block number if endBlock is nil or if startBlock is negative
 	var latestBlockNumber *big.Int
 	if endBlock == nil || startBlock.Sign() < 0 {
 		header, err := cs.Client.Client.HeaderByNumber(context.Background(), nil)
 		if err != nil {
-			return fmt.Errorf("failed to get the latest block header: %v", err)
+			return nil, fmt.Errorf("failed to get the latest block header: %v", err)
 		}
 		latestBlockNumber = header.Number
 	}
@@ -57,11 +98,11 @@ func (cs *BlockStats) Stats(startBlock *big.Int, endBlock *big.Int) error {
 		startBlock = new(big.Int).Add(latestBlockNumber, startBlock)
 	}
 
-	if endBlock.Int64() == 0 {
+	if endBlock == nil || endBlock.Int64() == 0 {
 		endBlock = latestBlockNumber
 	}
-	if endBlock != nil && startBlock.Int64() > endBlock.Int64() {
-		return fmt.Errorf("start block is less than the end block")
+	if startBlock.Int64() > endBlock.Int64() {
+		return nil, fmt.Errorf("start block is less than the end block")
 	}
 	L.Info().
 		Int64("EndBlock", endBlock.Int64()).
@@ -96,18 +137,87 @@ func (cs *BlockStats) Stats(startBlock *big.Int, endBlock *big.Int) error {
 		})
 	}
 	if err := eg.Wait(); err != nil {
-		return err
+		return nil, err
 	}
 	sort.SliceStable(blocks, func(i, j int) bool {
 		return blocks[i].Number().Int64() < blocks[j].Number().Int64()
 	})
-	return cs.CalculateBlockDurations(blocks)
+	return blocks, nil
 }
 
 // CalculateBlockDurations calculates and logs the duration, TPS, gas used, and gas limit between each consecutive block
 func (cs *BlockStats) CalculateBlockDurations(blocks []*types.Block) error {
-	if len(blocks) == 0 {
-		return fmt.Errorf("no blocks no analyze")
+	result, err := cs.CalculateBlockStats(blocks)
+	if err != nil {
+		return err
+	}
+
+	L.Debug().
+		Int("Blocks", result.Blocks).
+		Float64("AverageTPS", result.AvgTPS).
+		Str("AvgBlockDuration", result.AvgBlockDuration).
+		Uint64("AvgBlockGasUsed", result.AvgBlockGasUsed).
+		Uint64("AvgBlockGasLimit", result.AvgBlockGasLimit).
+		Uint64("AvgBlockBaseFee", result.AvgBlockBaseFee).
+		Uint64("AvgBlockSize", result.AvgBlockSize).
+		Str("95thBlockDuration", result.Perc95BlockDuration).
+		Float64("95thTPS", result.Perc95TPS).
+		Uint64("95thBlockGasUsed", result.Perc95BlockGasUsed).
+		Uint64("95thBlockGasLimit", result.Perc95BlockGasLimit).
+		Uint64("95thBlockBaseFee", result.Perc95BlockBaseFee).
+		Uint64("95thBlockSize", result.Perc95BlockSize).
+		Float64("RequiredGasBumpPercentage", result.RequiredGasBumpPercentage).
+		Msg("Summary")
+
+	type performanceTestStats struct {
+		Duration                 string  `toml:"duration"`
+		GasInitialValue          uint64  `toml:"avg_block_gas_base_fee_initial_value"`
+		GasBaseFeeBumpPercentage string  `toml:"avg_block_gas_base_fee_bump_percentage"`
+		GasUsagePercentage       string  `toml:"avg_block_gas_usage_percentage"`
+		TPSStable                float64 `toml:"avg_tps"`
+		TPSMax                   float64 `toml:"max_tps"`
+	}
+
+	var bumpMsg string
+	if result.RequiredGasBumpPercentage == 100.0 {
+		bumpMsg = fmt.Sprintf("%.2f%% (no bump required)", result.RequiredGasBumpPercentage)
+	} else {
+		bumpMsg = fmt.Sprintf("%.2f%% (multiply)", result.RequiredGasBumpPercentage)
+	}
+	var blockGasUsagePercentageMsg string
+	if result.AvgBlockGasUsagePercentage >= 100 {
+		blockGasUsagePercentageMsg = fmt.Sprintf("%.8f%% gas used (network is congested)", result.AvgBlockGasUsagePercentage)
+	} else {
+		blockGasUsagePercentageMsg = fmt.Sprintf("%.8f%% gas used (no congestion)", result.AvgBlockGasUsagePercentage)
+	}
+
+	perfStats := performanceTestStats{
+		Duration:                 result.Duration,
+		GasInitialValue:          result.AvgBlockBaseFee,
+		TPSStable:                math.Ceil(result.AvgTPS),
+		TPSMax:                   math.Ceil(result.Perc95TPS),
+		GasUsagePercentage:       blockGasUsagePercentageMsg,
+		GasBaseFeeBumpPercentage: bumpMsg,
+	}
+
+	marshalled, err := toml.Marshal(result)
+	if err != nil {
+		return err
+	}
+	L.Info().Msgf("Stats:\n%s", string(marshalled))
+
+	marshalled, err = toml.Marshal(perfStats)
+	if err != nil {
+		return err
+	}
+	L.Info().Msgf("Recommended performance/chaos test parameters:\n%s", string(marshalled))
+	return nil
+}
+
+// CalculateBlockStats calculates the duration, TPS, gas used, gas limit, base fee and size statistics between each consecutive block
+func (cs *BlockStats) CalculateBlockStats(blocks []*types.Block) (*BlockStatsResult, error) {
+	if len(blocks) < 2 {
+		return nil, fmt.Errorf("at least 2 blocks are needed to calculate stats, got %d", len(blocks))
 	}
 	var (
 		durations          []time.Duration
@@ -168,7 +278,11 @@ func (cs *BlockStats) CalculateBlockDurations(blocks []*types.Block) error {
 	}
 
 	// Calculate average values
-	averageTPS := float64(totalTransactions) / totalDuration.Seconds()
+	// blocks might share the same timestamp on some L2s, Inf can't be marshalled to JSON
+	var averageTPS float64
+	if totalDuration.Seconds() > 0 {
+		averageTPS = float64(totalTransactions) / totalDuration.Seconds()
+	}
 	averageDuration := totalDuration / time.Duration(len(durations))
 	averageGasUsed := totalGasUsed / uint64(len(gasUsedValues))
 	averageGasLimit := totalGasLimit / uint64(len(gasLimitValues))
@@ -192,100 +306,26 @@ func (cs *BlockStats) CalculateBlockDurations(blocks []*types.Block) error {
 	percentile95BlockBaseFee := blockBaseFeeValues[index95]
 	percentile95BlockSize := blockSizeValues[index95]
 
-	L.Debug().
-		Int("Blocks", len(blocks)).
-		Float64("AverageTPS", averageTPS).
-		Dur("AvgBlockDuration", averageDuration).
-		Uint64("AvgBlockGasUsed", averageGasUsed).
-		Uint64("AvgBlockGasLimit", averageGasLimit).
-		Uint64("AvgBlockBaseFee", averageBlockBaseFee).
-		Uint64("AvgBlockSize", averageBlockSize).
-		Dur("95thBlockDuration", percentile95Duration).
-		Float64("95thTPS", percentile95TPS).
-		Uint64("95thBlockGasUsed", percentile95GasUsed).
-		Uint64("95thBlockGasLimit", percentile95GasLimit).
-		Uint64("95thBlockBaseFee", percentile95BlockBaseFee).
-		Uint64("95thBlockSize", percentile95BlockSize).
-		Float64("RequiredGasBumpPercentage", calculateRatioPercentage(percentile95BlockBaseFee, averageBlockBaseFee)).
-		Msg("Summary")
-
-	type stats struct {
-		Blocks              int     `toml:"blocks"`
-		Perc95TPS           float64 `toml:"perc_95_tps"`
-		Perc95BlockDuration string  `toml:"perc_95_block_duration"`
-		Perc95BlockGasUsed  uint64  `toml:"perc_95_block_gas_used"`
-		Perc95BlockGasLimit uint64  `toml:"perc_95_block_gas_limit"`
-		Perc95BlockBaseFee  uint64  `toml:"perc_95_block_base_fee"`
-		Perc95BlockSize     uint64  `toml:"perc_95_block_size"`
-		AvgTPS              float64 `toml:"avg_tps"`
-		AvgBlockDuration    string  `toml:"avg_block_duration"`
-		AvgBlockGasUsed     uint64  `toml:"avg_block_gas_used"`
-		AvgBlockGasLimit    uint64  `toml:"avg_block_gas_limit"`
-		AvgBlockBaseFee     uint64  `toml:"avg_block_base_fee"`
-		AvgBlockSize        uint64  `toml:"avg_block_size"`
-	}
-
-	type performanceTestStats struct {
-		Duration                 string  `toml:"duration"`
-		GasInitialValue          uint64  `toml:"avg_block_gas_base_fee_initial_value"`
-		GasBaseFeeBumpPercentage string  `toml:"avg_block_gas_base_fee_bump_percentage"`
-		GasUsagePercentage       string  `toml:"avg_block_gas_usage_percentage"`
-		TPSStable                float64 `toml:"avg_tps"`
-		TPSMax                   float64 `toml:"max_tps"`
-	}
-
-	tomlCfg := stats{
-		Blocks:              len(blocks),
-		Perc95TPS:           percentile95TPS,
-		Perc95BlockDuration: percentile95Duration.String(),
-		Perc95BlockGasUsed:  percentile95GasUsed,
-		Perc95BlockGasLimit: percentile95GasLimit,
-		Perc95BlockBaseFee:  percentile95BlockBaseFee,
-		Perc95BlockSize:     percentile95BlockSize,
-		AvgTPS:              averageTPS,
-		AvgBlockDuration:    averageDuration.String(),
-		AvgBlockGasUsed:     averageGasUsed,
-		AvgBlockGasLimit:    averageGasLimit,
-		AvgBlockBaseFee:     averageBlockBaseFee,
-		AvgBlockSize:        averageBlockSize,
-	}
-
-	var bumpMsg string
-	bump := calculateRatioPercentage(percentile95BlockBaseFee, averageBlockBaseFee)
-	if bump == 100.0 {
-		bumpMsg = fmt.Sprintf("%.2f%% (no bump required)", bump)
-	} else {
-		bumpMsg = fmt.Sprintf("%.2f%% (multiply)", bump)
-	}
-	var blockGasUsagePercentageMsg string
-	blockGasUsagePerc := calculateRatioPercentage(averageGasUsed, averageGasLimit)
-	if blockGasUsagePerc >= 100 {
-		blockGasUsagePercentageMsg = fmt.Sprintf("%.8f%% gas used (network is congested)", blockGasUsagePerc)
-	} else {
-		blockGasUsagePercentageMsg = fmt.Sprintf("%.8f%% gas used (no congestion)", blockGasUsagePerc)
-	}
-
-	perfStats := performanceTestStats{
-		Duration:                 totalDuration.String(),
-		GasInitialValue:          averageBlockBaseFee,
-		TPSStable:                math.Ceil(averageTPS),
-		TPSMax:                   math.Ceil(percentile95TPS),
-		GasUsagePercentage:       blockGasUsagePercentageMsg,
-		GasBaseFeeBumpPercentage: bumpMsg,
-	}
-
-	marshalled, err := toml.Marshal(tomlCfg)
-	if err != nil {
-		return err
-	}
-	L.Info().Msgf("Stats:\n%s", string(marshalled))
-
-	marshalled, err = toml.Marshal(perfStats)
-	if err != nil {
-		return err
-	}
-	L.Info().Msgf("Recommended performance/chaos test parameters:\n%s", string(marshalled))
-	return nil
+	return &BlockStatsResult{
+		StartBlock:                 blocks[0].NumberU64(),
+		EndBlock:                   blocks[len(blocks)-1].NumberU64(),
+		Blocks:                     len(blocks),
+		Duration:                   totalDuration.String(),
+		Perc95TPS:                  percentile95TPS,
+		Perc95BlockDuration:        percentile95Duration.String(),
+		Perc95BlockGasUsed:         percentile95GasUsed,
+		Perc95BlockGasLimit:        percentile95GasLimit,
+		Perc95BlockBaseFee:         percentile95BlockBaseFee,
+		Perc95BlockSize:            percentile95BlockSize,
+		AvgTPS:                     averageTPS,
+		AvgBlockDuration:           averageDuration.String(),
+		AvgBlockGasUsed:            averageGasUsed,
+		AvgBlockGasLimit:           averageGasLimit,
+		AvgBlockBaseFee:            averageBlockBaseFee,
+		AvgBlockSize:               averageBlockSize,
+		AvgBlockGasUsagePercentage: calculateRatioPercentage(averageGasUsed, averageGasLimit),
+		RequiredGasBumpPercentage:  calculateRatioPercentage(percentile95BlockBaseFee, averageBlockBaseFee),
+	}, nil
 }
 
 // calculateRatioPercentage calculates the ratio between two uint64 values and returns it as a percentage
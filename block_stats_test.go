@@ -0,0 +1,59 @@
+package seth_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/ratelimit"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func TestBlockStatsResultIsCalculatedFromReplayedBlocks(t *testing.T) {
+	c := newFeeHistoryReplaySethClient(t, newFeeHistoryTestFixture())
+	bs := &seth.BlockStats{Limiter: ratelimit.NewUnlimited(), Client: c}
+
+	result, err := bs.StatsResult(big.NewInt(-10), big.NewInt(0))
+	require.NoError(t, err, "failed to calculate block stats")
+	require.Equal(t, uint64(feeHistoryTestLatestBlock-10), result.StartBlock, "incorrect start block")
+	require.Equal(t, uint64(feeHistoryTestLatestBlock-1), result.EndBlock, "incorrect end block")
+	require.Equal(t, 10, result.Blocks, "incorrect number of blocks")
+	require.Equal(t, "1m48s", result.Duration, "incorrect duration")
+	require.Equal(t, "12s", result.AvgBlockDuration, "incorrect average block duration")
+	require.Equal(t, "12s", result.Perc95BlockDuration, "incorrect 95th percentile block duration")
+	require.Equal(t, uint64(feeHistoryTestBaseFee), result.AvgBlockBaseFee, "incorrect average base fee")
+	require.Equal(t, uint64(feeHistoryTestGasLimit), result.AvgBlockGasUsed, "incorrect average gas used")
+	require.Equal(t, float64(100), result.AvgBlockGasUsagePercentage, "all blocks are full")
+	require.Equal(t, float64(100), result.RequiredGasBumpPercentage, "base fee is constant, no bump is required")
+	require.Zero(t, result.AvgTPS, "there are no transactions")
+
+	marshalled, err := json.Marshal(result)
+	require.NoError(t, err, "failed to marshal block stats")
+	var asMap map[string]interface{}
+	require.NoError(t, json.Unmarshal(marshalled, &asMap), "failed to unmarshal block stats")
+	require.Equal(t, "12s", asMap["avg_block_duration"], "JSON should use snake case names")
+
+	_, err = bs.CalculateBlockStats(nil)
+	require.Error(t, err, "stats can't be calculated without blocks")
+}
+
+func TestGasSuggestionsAreReturnedFromReplayedFeeHistory(t *testing.T) {
+	c := newFeeHistoryReplaySethClient(t, newFeeHistoryTestFixture())
+
+	suggestions, err := seth.NewGasEstimator(c).Stats(10, 99)
+	require.NoError(t, err, "failed to get gas suggestions")
+	require.Equal(t, float64(feeHistoryTestBaseFee), suggestions.GasPrice.Perc50, "incorrect median base fee")
+	require.Equal(t, float64(feeHistoryTestTip), suggestions.TipCap.Max, "incorrect max tip")
+	require.Equal(t, big.NewInt(feeHistoryTestBaseFee+feeHistoryTestTip), suggestions.SuggestedGasPrice, "incorrect suggested gas price")
+
+	marshalled, err := json.Marshal(suggestions)
+	require.NoError(t, err, "failed to marshal gas suggestions")
+	require.JSONEq(t, `{
+		"gas_price": {"max": 1e10, "perc_99": 1e10, "perc_75": 1e10, "perc_50": 1e10, "perc_25": 1e10},
+		"tip_cap": {"max": 2e9, "perc_99": 2e9, "perc_75": 2e9, "perc_50": 2e9, "perc_25": 2e9},
+		"suggested_gas_price": 12000000000,
+		"suggested_gas_tip_cap": 1000000000
+	}`, string(marshalled), "incorrect JSON")
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/avast/retry-go"
@@ -23,7 +24,6 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
-	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -37,6 +37,8 @@ const (
 	ErrRpcHealthCheckFailed     = "RPC health check failed ¯\\_(ツ)_/¯"
 	ErrContractDeploymentFailed = "contract deployment failed"
 
+	WarnGasLimitSet = "Gas limit is set, this will override the gas limit set by the network. This option should be used **ONLY** if node is incapable of estimating gas limit itself, which happens only with very old versions"
+
 	ContractMapFilePattern          = "deployed_contracts_%s_%s.toml"
 	RevertedTransactionsFilePattern = "reverted_transactions_%s_%s.json"
 )
@@ -71,6 +73,16 @@ type Client struct {
 	ContractAddressToNameMap ContractMap
 	ABIFinder                *ABIFinder
 	HeaderCache              *LFUHeaderCache
+	ValueFormatter           *ValueFormatter
+	GasOracle                GasOracle
+	StuckTxWatchdog          *StuckTxWatchdog
+	CostLedger               *CostLedger
+	Metrics                  *Metrics
+	// fees of transactions before they were bumped for the first time
+	replacementFeeOrigins sync.Map
+	// gas limits set by Seth for transactions that weren't mined yet
+	gasLimitEstimates sync.Map
+	blockGasLimit     blockGasLimitCache
 }
 
 // NewClientWithConfig creates a new seth client with all deps setup from config
@@ -111,6 +123,10 @@ func NewClientWithConfig(cfg *Config) (*Client, error) {
 		return nil, errors.Wrap(err, ErrCreateNonceManager)
 	}
 
+	if cfg.SharedContractMap && cfg.ContractMapFile == "" {
+		cfg.ContractMapFile = cfg.GenerateSharedContractMapFileName()
+	}
+
 	if !cfg.IsSimulatedNetwork() && cfg.SaveDeployedContractsMap && cfg.ContractMapFile == "" {
 		cfg.ContractMapFile = cfg.GenerateContractMapFileName()
 	}
@@ -119,7 +135,12 @@ func NewClientWithConfig(cfg *Config) (*Client, error) {
 	// so that both the tracer and client have references to the same map
 	contractAddressToNameMap := NewEmptyContractMap()
 	contractAddressToNameMap.addressMap = make(map[string]string)
-	if !cfg.IsSimulatedNetwork() {
+	if cfg.SharedContractMap {
+		contractAddressToNameMap, err = NewSharedContractMap(NewContractMapStore(cfg.SharedContractMapFilePath()))
+		if err != nil {
+			return nil, errors.Wrap(err, ErrReadContractMap)
+		}
+	} else if !cfg.IsSimulatedNetwork() {
 		contractAddressToNameMap.addressMap, err = LoadDeployedContracts(cfg.ContractMapFile)
 		if err != nil {
 			return nil, errors.Wrap(err, ErrReadContractMap)
@@ -149,33 +170,95 @@ func NewClientWithConfig(cfg *Config) (*Client, error) {
 	)
 }
 
+// networkValidators check settings of a single network, keys are relative to the network table
+var networkValidators = []struct {
+	key      string
+	validate func(network *Network) error
+}{
+	{"gas_price_estimation_enabled", validateGasPriceEstimation},
+	{"gas_oracle", validateGasOracle},
+	{"chain_type", validateChainType},
+}
+
+// configValidators check settings that don't belong to any network, they are shared by ValidateConfig and ValidateConfigFile
+var configValidators = []struct {
+	key      string
+	validate func(cfg *Config) error
+}{
+	{"gas_bump", func(cfg *Config) error { return validateGasBumpConfig(cfg.GasBump) }},
+	{"gas_limit_estimation", func(cfg *Config) error { return validateGasLimitEstimationConfig(cfg.GasLimitEstimation) }},
+	{"metrics", func(cfg *Config) error { return validateMetricsConfig(cfg.Metrics) }},
+	{"tracing_level", validateTracingLevel},
+	{"trace_outputs", func(cfg *Config) error { return validateTraceOutputs(cfg.TraceOutputs) }},
+}
+
 func ValidateConfig(cfg *Config) error {
-	if cfg.Network.GasPriceEstimationEnabled {
-		if cfg.Network.GasPriceEstimationBlocks == 0 {
-			return errors.New("when automating gas estimation is enabled blocks must be greater than 0. fix it or disable gas estimation")
+	for _, v := range networkValidators {
+		if err := v.validate(cfg.Network); err != nil {
+			return err
 		}
-		cfg.Network.GasPriceEstimationTxPriority = strings.ToLower(cfg.Network.GasPriceEstimationTxPriority)
+	}
 
-		if cfg.Network.GasPriceEstimationTxPriority == "" {
-			cfg.Network.GasPriceEstimationTxPriority = Priority_Standard
+	for _, v := range configValidators {
+		if err := v.validate(cfg); err != nil {
+			return err
 		}
+	}
 
-		switch cfg.Network.GasPriceEstimationTxPriority {
-		case Priority_Degen:
-		case Priority_Fast:
-		case Priority_Standard:
-		case Priority_Slow:
-		default:
-			return errors.New("when automating gas estimation is enabled priority must be fast, standard or slow. fix it or disable gas estimation")
-		}
+	if cfg.Network.GasLimit != 0 {
+		L.Warn().Msg(WarnGasLimitSet)
+	}
 
+	if cfg.Network.DialTimeout == nil {
+		cfg.Network.DialTimeout = &Duration{D: DefaultDialTimeout}
 	}
 
-	if cfg.Network.GasLimit != 0 {
-		L.Warn().
-			Msg("Gas limit is set, this will override the gas limit set by the network. This option should be used **ONLY** if node is incapable of estimating gas limit itself, which happens only with very old versions")
+	return nil
+}
+
+// validateGasPriceEstimation checks estimation settings and sets default priority, if estimation is enabled
+func validateGasPriceEstimation(network *Network) error {
+	if !network.GasPriceEstimationEnabled {
+		return nil
+	}
+	if network.GasPriceEstimationBlocks == 0 {
+		return errors.New("when automating gas estimation is enabled blocks must be greater than 0. fix it or disable gas estimation")
 	}
+	network.GasPriceEstimationTxPriority = strings.ToLower(network.GasPriceEstimationTxPriority)
 
+	if network.GasPriceEstimationTxPriority == "" {
+		network.GasPriceEstimationTxPriority = Priority_Standard
+	}
+
+	switch network.GasPriceEstimationTxPriority {
+	case Priority_Degen:
+	case Priority_Fast:
+	case Priority_Standard:
+	case Priority_Slow:
+	default:
+		return errors.New("when automating gas estimation is enabled priority must be fast, standard or slow. fix it or disable gas estimation")
+	}
+
+	return nil
+}
+
+// validateGasOracle normalises gas oracle name and checks if it's supported
+func validateGasOracle(network *Network) error {
+	network.GasOracle = strings.ToLower(network.GasOracle)
+	switch network.GasOracle {
+	case "":
+	case GasOracle_Congestion:
+	case GasOracle_Fixed:
+	case GasOracle_FeeHistory:
+	default:
+		return errors.New("gas oracle must be one of: congestion, fixed, fee_history")
+	}
+
+	return nil
+}
+
+// validateTracingLevel normalises tracing level, sets the default one and checks if it's supported
+func validateTracingLevel(cfg *Config) error {
 	if cfg.TracingLevel == "" {
 		cfg.TracingLevel = TracingLevel_Reverted
 	}
@@ -190,7 +273,12 @@ func ValidateConfig(cfg *Config) error {
 		return errors.New("tracing level must be one of: NONE, REVERTED, ALL")
 	}
 
-	for _, output := range cfg.TraceOutputs {
+	return nil
+}
+
+// validateTraceOutputs checks if all trace outputs are supported
+func validateTraceOutputs(outputs []string) error {
+	for _, output := range outputs {
 		switch strings.ToLower(output) {
 		case TraceOutput_Console:
 		case TraceOutput_JSON:
@@ -200,10 +288,6 @@ func ValidateConfig(cfg *Config) error {
 		}
 	}
 
-	if cfg.Network.DialTimeout == nil {
-		cfg.Network.DialTimeout = &Duration{D: DefaultDialTimeout}
-	}
-
 	return nil
 }
 
@@ -229,13 +313,20 @@ func NewClientRaw(
 	if len(cfg.Network.URLs) > 1 {
 		L.Warn().Msg("Multiple RPC URLs provided, only the first one will be used")
 	}
+	// metrics are created before dialing, because RPC requests are recorded by HTTP transport
+	var metrics *Metrics
+	transport := NewLoggingTransport()
+	if cfg.MetricsEnabled() {
+		metrics = NewMetrics(cfg.Network.Name)
+		transport = metrics.RPCTransport(transport)
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Network.DialTimeout.Duration())
 	defer cancel()
 	rpcClient, err := rpc.DialOptions(ctx,
 		cfg.FirstNetworkURL(),
 		rpc.WithHeaders(cfg.RPCHeaders),
 		rpc.WithHTTPClient(&http.Client{
-			Transport: NewLoggingTransport(),
+			Transport: transport,
 		}),
 	)
 	if err != nil {
@@ -262,14 +353,35 @@ func NewClientRaw(
 		ChainID:     int64(cID),
 		Context:     ctx,
 		CancelFunc:  cancelFunc,
+		Metrics:     metrics,
 	}
 	for _, o := range opts {
 		o(c)
 	}
 
+	if c.GasOracle == nil {
+		c.GasOracle, err = NewGasOracle(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	L.Debug().Str("Gas oracle", c.GasOracle.Name()).Msg("Using gas oracle")
+
 	if c.ContractAddressToNameMap.addressMap == nil {
 		c.ContractAddressToNameMap = NewEmptyContractMap()
-		if !cfg.IsSimulatedNetwork() {
+		if cfg.SharedContractMap {
+			if cfg.ContractMapFile == "" {
+				cfg.ContractMapFile = cfg.GenerateSharedContractMapFileName()
+			}
+			c.ContractAddressToNameMap, err = NewSharedContractMap(NewContractMapStore(cfg.SharedContractMapFilePath()))
+			if err != nil {
+				return nil, errors.Wrap(err, ErrReadContractMap)
+			}
+			L.Info().
+				Int("Size", c.ContractAddressToNameMap.Size()).
+				Str("File name", cfg.SharedContractMapFilePath()).
+				Msg("No contract map provided, using shared contract map")
+		} else if !cfg.IsSimulatedNetwork() {
 			c.ContractAddressToNameMap.addressMap, err = LoadDeployedContracts(cfg.ContractMapFile)
 			if err != nil {
 				return nil, errors.Wrap(err, ErrReadContractMap)
@@ -323,28 +435,9 @@ func NewClientRaw(
 		Msg("Created new client")
 
 	if cfg.ephemeral {
-		gasPrice, err := c.GetSuggestedLegacyFees(context.Background(), Priority_Standard)
-		if err != nil {
-			gasPrice = big.NewInt(c.Cfg.Network.GasPrice)
-		}
-
-		bd, err := c.CalculateSubKeyFunding(*cfg.EphemeralAddrs, gasPrice.Int64(), *cfg.RootKeyFundsBuffer)
-		if err != nil {
-			return nil, err
-		}
 		L.Warn().Msg("Ephemeral mode, all funds will be lost!")
-
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-		eg, egCtx := errgroup.WithContext(ctx)
 		// root key is element 0 in ephemeral
-		for _, addr := range c.Addresses[1:] {
-			addr := addr
-			eg.Go(func() error {
-				return c.TransferETHFromKey(egCtx, 0, addr.Hex(), bd.AddrFunding, gasPrice)
-			})
-		}
-		if err := eg.Wait(); err != nil {
+		if err := SplitFunds(c, *cfg.RootKeyFundsBuffer); err != nil {
 			return nil, err
 		}
 	}
@@ -369,6 +462,13 @@ func NewClientRaw(
 		c.Tracer = tr
 	}
 
+	if c.Cfg.ValueFormatterEnabled() && c.ValueFormatter == nil {
+		c.ValueFormatter = NewValueFormatter(c.ContractAddressToNameMap, c.Addresses, c.Client, c.Cfg.ValueFormatting)
+	}
+	if c.Tracer != nil && c.Tracer.ValueFormatter == nil {
+		c.Tracer.ValueFormatter = c.ValueFormatter
+	}
+
 	now := time.Now().Format("2006-01-02-15-04-05")
 	c.Cfg.revertedTransactionsFile = filepath.Join(c.Cfg.ArtifactsDir, fmt.Sprintf(RevertedTransactionsFilePattern, c.Cfg.Network.Name, now))
 
@@ -377,6 +477,13 @@ func NewClientRaw(
 		L.Debug().Msg("Initializing LFU block header cache")
 		c.HeaderCache = NewLFUBlockCache(c.Cfg.Network.GasPriceEstimationBlocks)
 
+		if c.Cfg.BackgroundGasOracleEnabled() && !c.Cfg.IsSimulatedNetwork() {
+			L.Debug().Msg("Starting background gas oracle")
+			backgroundOracle := NewBackgroundGasOracle(c.GasOracle, c.Client, c.HeaderCache, c.Cfg.BackgroundGasOracle)
+			backgroundOracle.Start(c.Context)
+			c.GasOracle = backgroundOracle
+		}
+
 		if c.Cfg.Network.EIP1559DynamicFees {
 			L.Debug().Msg("Checking if EIP-1559 is supported by the network")
 			c.CalculateGasEstimations(GasEstimationRequest{
@@ -390,7 +497,8 @@ func NewClientRaw(
 	}
 
 	// if gas bumping is enabled, but no strategy is set, we set the default one; otherwise we set the no-op strategy (defensive programming to avoid NPE)
-	if c.Cfg.GasBump != nil && c.Cfg.GasBump.StrategyFn == nil {
+	// declarative strategies from TOML are applied without a strategy function
+	if c.Cfg.GasBump != nil && c.Cfg.GasBump.StrategyFn == nil && !c.Cfg.GasBump.HasDeclarativeStrategy() {
 		if c.Cfg.GasBumpRetries() != 0 {
 			c.Cfg.GasBump.StrategyFn = PriorityBasedGasBumpingStrategyFn(c.Cfg.Network.GasPriceEstimationTxPriority)
 		} else {
@@ -398,6 +506,32 @@ func NewClientRaw(
 		}
 	}
 
+	if c.Cfg.StuckTxWatchdogEnabled() {
+		L.Debug().Msg("Starting stuck tx watchdog")
+		c.StuckTxWatchdog = NewStuckTxWatchdog(c, c.Cfg.StuckTxWatchdog)
+		c.StuckTxWatchdog.Start(c.Context)
+	}
+
+	if c.Cfg.CostLedgerEnabled() {
+		L.Debug().Msg("Cost ledger is enabled")
+		c.CostLedger = NewCostLedger()
+	}
+
+	// metrics server is started last, so that its address isn't left bound when creating the client fails. It's stopped
+	// together with other background tasks of the client by CancelFunc
+	if c.Metrics != nil && cfg.Metrics.ListenAddress != "" {
+		stopMetrics, err := c.Metrics.Serve(cfg.Metrics.ListenAddress)
+		if err != nil {
+			c.CancelFunc()
+			return nil, err
+		}
+		cancelFunc := c.CancelFunc
+		c.CancelFunc = func() {
+			cancelFunc()
+			_ = stopMetrics()
+		}
+	}
+
 	return c, nil
 }
 
@@ -406,7 +540,7 @@ func (m *Client) checkRPCHealth() error {
 	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
 	defer cancel()
 
-	gasPrice, err := m.GetSuggestedLegacyFees(context.Background(), Priority_Standard)
+	gasPrice, err := m.gasOracle().SuggestLegacyFees(context.Background(), Priority_Standard)
 	if err != nil {
 		gasPrice = big.NewInt(m.Cfg.Network.GasPrice)
 	}
@@ -426,6 +560,7 @@ func (m *Client) checkRPCHealth() error {
 // If transaction was reverted the error returned will be revert error, not decoding error (that one, if any, will be logged).
 // At the same time we also return decoded transaction, so contrary to go convention you might get both error and result.
 // Last, but not least, if gas bumps are enabled, we will try to bump gas on transaction timeout and resubmit it with higher gas.
+// If stuck tx watchdog is enabled, gas is bumped only by the watchdog and we wait for the latest replacement it sent instead.
 func (m *Client) Decode(tx *types.Transaction, txErr error) (*DecodedTransaction, error) {
 	if len(m.Errors) > 0 {
 		return nil, verr.Join(m.Errors...)
@@ -453,42 +588,20 @@ func (m *Client) Decode(tx *types.Transaction, txErr error) (*DecodedTransaction
 
 	l := L.With().Str("Transaction", tx.Hash().Hex()).Logger()
 
-	// if transaction was not mined, we will retry it with gas bumping, but only if gas bumping is enabled
-	// and if the transaction was not mined in time, other errors will be returned as is
+	// if stuck tx watchdog is enabled, it's the one bumping gas and we only follow its replacements
 	var receipt *types.Receipt
-	err := retry.Do(
-		func() error {
-			var err error
-			ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
-			receipt, err = m.WaitMined(ctx, l, m.Client, tx)
-			cancel()
-
-			return err
-		}, retry.OnRetry(func(i uint, retryErr error) {
-			replacementTx, replacementErr := prepareReplacementTransaction(m, tx)
-			if replacementErr != nil {
-				L.Debug().Str("Replacement error", replacementErr.Error()).Str("Current error", retryErr.Error()).Uint("Attempt", i).Msg("Failed to prepare replacement transaction. Retrying without the original one")
-				return
-			} else {
-				L.Debug().Str("Current error", retryErr.Error()).Uint("Attempt", i).Msg("Waiting for transaction to be confirmed after gas bump")
-			}
-			tx = replacementTx
-		}),
-		retry.DelayType(retry.FixedDelay),
-		// unless attempts is at least 1 retry.Do won't execute at all
-		retry.Attempts(func() uint {
-			if m.Cfg.GasBumpRetries() == 0 {
-				return 1
-			} else {
-				return m.Cfg.GasBumpRetries()
-			}
-		}()),
-		retry.RetryIf(func(err error) bool {
-			return m.Cfg.GasBumpRetries() != 0 && errors.Is(err, context.DeadlineExceeded)
-		}),
-	)
+	var err error
+	waitStart := time.Now()
+	if m.StuckTxWatchdog != nil {
+		tx, receipt, err = m.waitMinedFollowingWatchdog(l, tx)
+	} else {
+		tx, receipt, err = m.waitMinedWithGasBumps(l, tx)
+	}
 
 	if err != nil {
+		if sender, senderErr := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); senderErr == nil {
+			m.forgetGasLimitEstimate(sender, tx.Nonce())
+		}
 		L.Trace().
 			Err(err).
 			Msg("Skipping decoding, because transaction was not minted. Nothing to decode")
@@ -501,6 +614,13 @@ func (m *Client) Decode(tx *types.Transaction, txErr error) (*DecodedTransaction
 	}
 
 	decoded, decodeErr := m.decodeTransaction(l, tx, receipt)
+	var method string
+	if decoded != nil {
+		method = decoded.Method
+		m.recordTransactionCost(tx, receipt, "", decoded.Method)
+	}
+	m.recordTransactionMined(tx, receipt, "", method, waitStart)
+	m.logGasLimitEstimate(tx, receipt)
 
 	if decodeErr != nil && errors.Is(decodeErr, errors.New(ErrNoABIMethod)) {
 		if m.Cfg.hasOutput(TraceOutput_JSON) {
@@ -540,7 +660,7 @@ func (m *Client) Decode(tx *types.Transaction, txErr error) (*DecodedTransaction
 					Err(traceErr).
 					Msg("Failed to trace call, but decoding was successful. Saving decoded data as JSON")
 
-				path, saveErr := saveAsJson(decoded, filepath.Join(m.Cfg.ArtifactsDir, "traces"), decoded.Hash)
+				path, saveErr := saveAsJson(m.ValueFormatter.FormatTransaction(decoded), filepath.Join(m.Cfg.ArtifactsDir, "traces"), decoded.Hash)
 				if saveErr != nil {
 					L.Warn().
 						Err(saveErr).
@@ -565,8 +685,13 @@ func (m *Client) Decode(tx *types.Transaction, txErr error) (*DecodedTransaction
 			return decoded, revertErr
 		}
 
+		// receipt doesn't contain outputs, only the trace of the main call does
+		if calls := m.Tracer.GetDecodedCalls(decoded.Hash); len(calls) > 0 {
+			decoded.Output = calls[0].Output
+		}
+
 		if m.Cfg.hasOutput(TraceOutput_JSON) {
-			path, saveErr := saveAsJson(m.Tracer.GetDecodedCalls(decoded.Hash), filepath.Join(m.Cfg.ArtifactsDir, "traces"), decoded.Hash)
+			path, saveErr := saveAsJson(m.ValueFormatter.FormatCalls(m.Tracer.GetDecodedCalls(decoded.Hash)), filepath.Join(m.Cfg.ArtifactsDir, "traces"), decoded.Hash)
 			if saveErr != nil {
 				L.Warn().
 					Err(saveErr).
@@ -593,20 +718,27 @@ func (m *Client) TransferETHFromKey(ctx context.Context, fromKeyNum int, to stri
 	if fromKeyNum > len(m.PrivateKeys) || fromKeyNum > len(m.Addresses) {
 		return errors.Wrap(errors.New(ErrNoKeyLoaded), fmt.Sprintf("requested key: %d", fromKeyNum))
 	}
-	toAddr := common.HexToAddress(to)
-	chainID, err := m.Client.NetworkID(context.Background())
-	if err != nil {
-		return errors.Wrap(err, "failed to get network ID")
-	}
 
 	var gasLimit int64
 	gasLimitRaw, err := m.EstimateGasLimitForFundTransfer(m.Addresses[fromKeyNum], common.HexToAddress(to), value)
 	if err != nil {
+		m.recordEstimationFallback(GasEstimationFallback_GasLimit)
 		gasLimit = m.Cfg.Network.TransferGasFee
 	} else {
 		gasLimit = int64(gasLimitRaw)
 	}
 
+	return m.transferETHFromKeyWithGasLimit(ctx, fromKeyNum, to, value, gasPrice, uint64(gasLimit))
+}
+
+// transferETHFromKeyWithGasLimit sends and waits for a transfer with given gas limit, e.g. the one used to reserve the transfer fee
+func (m *Client) transferETHFromKeyWithGasLimit(ctx context.Context, fromKeyNum int, to string, value *big.Int, gasPrice *big.Int, gasLimit uint64) error {
+	toAddr := common.HexToAddress(to)
+	chainID, err := m.Client.NetworkID(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to get network ID")
+	}
+
 	if gasPrice == nil {
 		gasPrice = big.NewInt(m.Cfg.Network.GasPrice)
 	}
@@ -615,7 +747,7 @@ func (m *Client) TransferETHFromKey(ctx context.Context, fromKeyNum int, to stri
 		Nonce:    m.NonceManager.NextNonce(m.Addresses[fromKeyNum]).Uint64(),
 		To:       &toAddr,
 		Value:    value,
-		Gas:      uint64(gasLimit),
+		Gas:      gasLimit,
 		GasPrice: gasPrice,
 	}
 	L.Debug().Interface("TransferTx", rawTx).Send()
@@ -636,10 +768,13 @@ func (m *Client) TransferETHFromKey(ctx context.Context, fromKeyNum int, to stri
 		Str("To", to).
 		Interface("Value", value).
 		Msg("Send ETH")
-	_, err = m.WaitMined(ctx, l, m.Client, signedTx)
+	waitStart := time.Now()
+	receipt, err := m.WaitMined(ctx, l, m.Client, signedTx)
 	if err != nil {
 		return err
 	}
+	m.recordTransactionCost(signedTx, receipt, "", CostLedgerMethod_Transfer)
+	m.recordTransactionMined(signedTx, receipt, "", CostLedgerMethod_Transfer, waitStart)
 	return err
 }
 
@@ -676,6 +811,50 @@ func (m *Client) WaitMined(ctx context.Context, l zerolog.Logger, b bind.DeployB
 	}
 }
 
+// waitMinedWithGasBumps waits for transaction to be mined. If it wasn't mined in time, we will retry it with gas bumping, but only
+// if gas bumping is enabled. Other errors will be returned as is. It returns the transaction that was mined, which might be a replacement.
+func (m *Client) waitMinedWithGasBumps(l zerolog.Logger, tx *types.Transaction) (*types.Transaction, *types.Receipt, error) {
+	var receipt *types.Receipt
+	err := retry.Do(
+		func() error {
+			var err error
+			ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+			receipt, err = m.WaitMined(ctx, l, m.Client, tx)
+			cancel()
+
+			return err
+		}, retry.OnRetry(func(i uint, retryErr error) {
+			replacementTx, replacementErr := prepareReplacementTransaction(m, tx)
+			if replacementErr != nil {
+				L.Debug().Str("Replacement error", replacementErr.Error()).Str("Current error", retryErr.Error()).Uint("Attempt", i).Msg("Failed to prepare replacement transaction. Retrying without the original one")
+				return
+			} else {
+				L.Debug().Str("Current error", retryErr.Error()).Uint("Attempt", i).Msg("Waiting for transaction to be confirmed after gas bump")
+			}
+			tx = replacementTx
+		}),
+		retry.DelayType(retry.FixedDelay),
+		// unless attempts is at least 1 retry.Do won't execute at all
+		retry.Attempts(func() uint {
+			if m.Cfg.GasBumpRetries() == 0 {
+				return 1
+			} else {
+				return m.Cfg.GasBumpRetries()
+			}
+		}()),
+		retry.RetryIf(func(err error) bool {
+			return m.Cfg.GasBumpRetries() != 0 && errors.Is(err, context.DeadlineExceeded)
+		}),
+	)
+
+	// nonce was either mined or we gave up on it, so it won't be bumped anymore
+	if sender, senderErr := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); senderErr == nil {
+		m.forgetOriginalFees(sender, tx.Nonce())
+	}
+
+	return tx, receipt, err
+}
+
 /* ClientOpts client functional options */
 
 // ClientOpt is a client functional option
@@ -716,6 +895,13 @@ func WithTracer(t *Tracer) ClientOpt {
 	}
 }
 
+// WithGasOracle GasOracle functional option
+func WithGasOracle(o GasOracle) ClientOpt {
+	return func(c *Client) {
+		c.GasOracle = o
+	}
+}
+
 /* CallOpts function options */
 
 // CallOpt is a functional option for bind.CallOpts
@@ -832,7 +1018,7 @@ func (m *Client) NewTXOpts(o ...TransactOpt) *bind.TransactOpts {
 // NewTXKeyOpts returns a new transaction options wrapper,
 // sets opts.GasPrice and opts.GasLimit from seth.toml or override with options
 func (m *Client) NewTXKeyOpts(keyNum int, o ...TransactOpt) *bind.TransactOpts {
-	if keyNum > len(m.Addresses) || keyNum < 0 {
+	if keyNum >= len(m.Addresses) || keyNum < 0 {
 		errText := fmt.Sprintf("keyNum is out of range. Expected %d-%d. Got: %d", 0, len(m.Addresses)-1, keyNum)
 		if keyNum == TimeoutKeyNum {
 			errText += " (this is a probably because, we didn't manage to find any synced key before timeout)"
@@ -880,8 +1066,8 @@ type GasEstimations struct {
 }
 
 type NonceStatus struct {
-	LastNonce    uint64
-	PendingNonce uint64
+	LastNonce    uint64 `json:"last_nonce"`
+	PendingNonce uint64 `json:"pending_nonce"`
 }
 
 func (m *Client) getNonceStatus(address common.Address) (NonceStatus, error) {
@@ -983,7 +1169,7 @@ func (m *Client) NewDefaultGasEstimationRequest() GasEstimationRequest {
 	}
 }
 
-// CalculateGasEstimations calculates gas estimations (price, tip/cap) or uses hardcoded values if estimation is disabled,
+// CalculateGasEstimations calculates gas estimations (price, tip/cap) using client's GasOracle or uses hardcoded values if estimation is disabled,
 // estimation errors or network is a simulated one.
 func (m *Client) CalculateGasEstimations(request GasEstimationRequest) GasEstimations {
 	estimations := GasEstimations{}
@@ -1007,10 +1193,11 @@ func (m *Client) CalculateGasEstimations(request GasEstimationRequest) GasEstima
 	}
 
 	var calculateLegacyFees = func() {
-		gasPrice, err := m.GetSuggestedLegacyFees(ctx, request.Priority)
+		gasPrice, err := m.gasOracle().SuggestLegacyFees(ctx, request.Priority)
 		if err != nil {
 			disableEstimationsIfNeeded(err)
 			L.Warn().Err(err).Msg("Failed to get suggested Legacy fees. Using hardcoded values")
+			m.recordEstimationFallback(GasEstimationFallback_LegacyFees)
 			estimations.GasPrice = big.NewInt(request.FallbackGasPrice)
 		} else {
 			estimations.GasPrice = gasPrice
@@ -1018,9 +1205,10 @@ func (m *Client) CalculateGasEstimations(request GasEstimationRequest) GasEstima
 	}
 
 	if m.Cfg.Network.EIP1559DynamicFees {
-		maxFee, priorityFee, err := m.GetSuggestedEIP1559Fees(ctx, request.Priority)
+		maxFee, priorityFee, err := m.gasOracle().SuggestEIP1559Fees(ctx, request.Priority)
 		if err != nil {
 			L.Warn().Err(err).Msg("Failed to get suggested EIP1559 fees. Using hardcoded values")
+			m.recordEstimationFallback(GasEstimationFallback_EIP1559Fees)
 			estimations.GasFeeCap = big.NewInt(request.FallbackGasFeeCap)
 			estimations.GasTipCap = big.NewInt(request.FallbackGasTipCap)
 
@@ -1080,6 +1268,15 @@ func (m *Client) configureTransactionOpts(
 	for _, f := range o {
 		f(opts)
 	}
+	if opts.Signer != nil && m.accessListEnabled(opts) {
+		opts.Signer = m.accessListSigner(opts.Signer)
+	}
+	if opts.Signer != nil && m.Cfg.GasLimitEstimationEnabled() {
+		opts.Signer = m.gasLimitSigner(opts, opts.Signer)
+	}
+	if opts.Signer != nil && m.StuckTxWatchdog != nil {
+		opts.Signer = m.StuckTxWatchdog.trackingSigner(opts.Signer)
+	}
 	return opts
 }
 
@@ -1123,9 +1320,12 @@ func (m *Client) DeployContract(auth *bind.TransactOpts, name string, abi abi.AB
 
 	address, tx, contract, err := bind.DeployContract(auth, abi, bytecode, m.Client, params...)
 	if err != nil {
+		// deployment might have failed during gas estimation due to a revert in the constructor
+		if reason, decodingErr := m.DecodeCustomABIErr(err); decodingErr == nil && reason != "" {
+			err = errors.Wrap(err, reason)
+		}
 		return DeploymentData{}, wrapErrInMessageWithASuggestion(err)
 	}
-
 	L.Info().
 		Str("Address", address.Hex()).
 		Str("TXHash", tx.Hash().Hex()).
@@ -1137,6 +1337,18 @@ func (m *Client) DeployContract(auth *bind.TransactOpts, name string, abi abi.AB
 		m.ContractStore.AddABI(name, abi)
 	}
 
+	waitStart := time.Now()
+	var receipt *types.Receipt
+	// with stuck tx watchdog enabled, it's the one bumping gas, so we wait for whichever transaction with this nonce gets mined
+	if m.StuckTxWatchdog != nil {
+		minedTx, minedReceipt, err := m.waitMinedFollowingWatchdog(L.With().Str("Transaction", tx.Hash().Hex()).Logger(), tx)
+		if err != nil {
+			_, _ = m.Decode(tx, errors.New(ErrContractDeploymentFailed))
+			return DeploymentData{}, wrapErrInMessageWithASuggestion(m.rewriteDeploymentError(err))
+		}
+		tx, receipt = minedTx, minedReceipt
+	}
+
 	// retry is needed both for gas bumping and for waiting for deployment to finish (sometimes there's no code at address the first time we check)
 	if err := retry.Do(
 		func() error {
@@ -1147,14 +1359,21 @@ func (m *Client) DeployContract(auth *bind.TransactOpts, name string, abi abi.AB
 			// let's make sure that deployment transaction was successful, before retrying
 			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
 				ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
-				receipt, mineErr := bind.WaitMined(ctx, m.Client, tx)
+				minedReceipt, mineErr := bind.WaitMined(ctx, m.Client, tx)
 				if mineErr != nil {
 					cancel()
 					return mineErr
 				}
 				cancel()
 
-				if receipt.Status == 0 {
+				if minedReceipt.Status == 0 {
+					// reverted deployment still has to be paid for
+					m.recordTransactionCost(tx, minedReceipt, name, CostLedgerMethod_Deployment)
+					m.recordTransactionMined(tx, minedReceipt, name, CostLedgerMethod_Deployment, waitStart)
+					m.logGasLimitEstimate(tx, minedReceipt)
+					if revertErr := m.callAndGetRevertReason(tx, minedReceipt); revertErr != nil {
+						return errors.Wrap(revertErr, "deployment transaction was reverted")
+					}
 					return errors.New("deployment transaction was reverted")
 				}
 			}
@@ -1162,7 +1381,7 @@ func (m *Client) DeployContract(auth *bind.TransactOpts, name string, abi abi.AB
 			return err
 		}, retry.OnRetry(func(i uint, retryErr error) {
 			switch {
-			case errors.Is(retryErr, context.DeadlineExceeded):
+			case errors.Is(retryErr, context.DeadlineExceeded) && m.StuckTxWatchdog == nil:
 				replacementTx, replacementErr := prepareReplacementTransaction(m, tx)
 				if replacementErr != nil {
 					L.Debug().Str("Current error", retryErr.Error()).Str("Replacement error", replacementErr.Error()).Uint("Attempt", i+1).Msg("Failed to prepare replacement transaction for contract deployment. Retrying with the original one")
@@ -1201,11 +1420,31 @@ func (m *Client) DeployContract(auth *bind.TransactOpts, name string, abi abi.AB
 		Str("TXHash", tx.Hash().Hex()).
 		Msgf("Deployed %s contract", name)
 
+	// receipt is fetched once and shared, because waiting for deployment doesn't return it
+	if receipt == nil && (m.CostLedger != nil || m.Metrics != nil || m.Cfg.GasLimitEstimationEnabled()) {
+		ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+		receipt, err = m.Client.TransactionReceipt(ctx, tx.Hash())
+		cancel()
+		if err != nil {
+			L.Debug().Err(err).Str("TxHash", tx.Hash().Hex()).Msg("Failed to get deployment receipt")
+			receipt = nil
+		}
+	}
+	m.recordTransactionCost(tx, receipt, name, CostLedgerMethod_Deployment)
+	m.recordTransactionMined(tx, receipt, name, CostLedgerMethod_Deployment, waitStart)
+	m.logGasLimitEstimate(tx, receipt)
+
 	if !m.Cfg.ShouldSaveDeployedContractMap() {
 		return DeploymentData{Address: address, Transaction: tx, BoundContract: contract}, nil
 	}
 
-	if err := SaveDeployedContract(m.Cfg.ContractMapFile, name, address.Hex()); err != nil {
+	if m.ContractAddressToNameMap.IsShared() {
+		err = m.ContractAddressToNameMap.SaveContract(address.Hex(), name)
+	} else {
+		err = SaveDeployedContract(m.Cfg.ContractMapFile, name, address.Hex())
+	}
+
+	if err != nil {
 		L.Warn().
 			Err(err).
 			Msg("Failed to save deployed contract address to file")
@@ -1296,33 +1535,47 @@ func (t TransactionLog) GetData() []byte {
 	return t.Data
 }
 
-func (m *Client) decodeContractLogs(l zerolog.Logger, logs []types.Log, a abi.ABI) ([]DecodedTransactionLog, error) {
+// decodeContractLogs decodes each log with the ABI of the contract that emitted it. Logs that can't be decoded are skipped.
+func (m *Client) decodeContractLogs(l zerolog.Logger, logs []types.Log) []DecodedTransactionLog {
 	l.Trace().Msg("Decoding events")
 	var eventsParsed []DecodedTransactionLog
 	for _, lo := range logs {
-		for _, evSpec := range a.Events {
-			if evSpec.ID.Hex() == lo.Topics[0].Hex() {
-				d := TransactionLog{lo.Topics, lo.Data}
-				l.Trace().Str("Name", evSpec.RawName).Str("Signature", evSpec.Sig).Msg("Unpacking event")
-				eventsMap, topicsMap, err := decodeEventFromLog(l, a, evSpec, d)
-				if err != nil {
-					return nil, errors.Wrap(err, ErrDecodeLog)
-				}
-				parsedEvent := decodedLogFromMaps(&DecodedTransactionLog{}, eventsMap, topicsMap)
-				if decodedTransactionLog, ok := parsedEvent.(*DecodedTransactionLog); ok {
-					decodedTransactionLog.Signature = evSpec.Sig
-					m.mergeLogMeta(decodedTransactionLog, lo)
-					eventsParsed = append(eventsParsed, *decodedTransactionLog)
-					l.Trace().Interface("Log", parsedEvent).Msg("Transaction log")
-				} else {
-					l.Trace().
-						Str("Actual type", fmt.Sprintf("%T", decodedTransactionLog)).
-						Msg("Failed to cast decoded event to DecodedCommonLog")
-				}
-			}
+		// each log is decoded with the ABI of the contract that emitted it, which might be different from the one that was called
+		abiResult, err := m.ABIFinder.FindABIByEvent(lo.Address.Hex(), lo.Topics)
+		if err != nil {
+			l.Trace().
+				Err(err).
+				Str("Address", lo.Address.Hex()).
+				Msg("Event not found in any ABI. Skipping it")
+			continue
+		}
+		evSpec := *abiResult.Event
+		d := TransactionLog{lo.Topics, lo.Data}
+		l.Trace().Str("Name", evSpec.RawName).Str("Signature", evSpec.Sig).Msg("Unpacking event")
+		eventsMap, topicsMap, err := decodeEventFromLog(l, abiResult.ABI, evSpec, d)
+		if err != nil {
+			// one malformed log (e.g. emitted by a contract with a colliding event signature) shouldn't hide all other events
+			l.Debug().
+				Err(errors.Wrap(err, ErrDecodeLog)).
+				Str("Address", lo.Address.Hex()).
+				Str("Signature", evSpec.Sig).
+				Uint("Index", lo.Index).
+				Msg("Failed to decode log. Skipping it")
+			continue
+		}
+		parsedEvent := decodedLogFromMaps(&DecodedTransactionLog{}, eventsMap, topicsMap)
+		if decodedTransactionLog, ok := parsedEvent.(*DecodedTransactionLog); ok {
+			decodedTransactionLog.Signature = evSpec.Sig
+			m.mergeLogMeta(decodedTransactionLog, lo)
+			eventsParsed = append(eventsParsed, *decodedTransactionLog)
+			l.Trace().Interface("Log", parsedEvent).Msg("Transaction log")
+		} else {
+			l.Trace().
+				Str("Actual type", fmt.Sprintf("%T", decodedTransactionLog)).
+				Msg("Failed to cast decoded event to DecodedCommonLog")
 		}
 	}
-	return eventsParsed, nil
+	return eventsParsed
 }
 
 // WaitUntilNoPendingTxForRootKey waits until there's no pending transaction for root key. If after timeout there are still pending transactions, it returns error.
@@ -1333,8 +1586,8 @@ func (m *Client) WaitUntilNoPendingTxForRootKey(timeout time.Duration) error {
 // WaitUntilNoPendingTxFoKeyNum waits until there's no pending transaction for key at index `keyNum`. If index is out of range or
 // if after timeout there are still pending transactions, it returns error.
 func (m *Client) WaitUntilNoPendingTxFoKeyNum(keyNum int, timeout time.Duration) error {
-	if keyNum > len(m.Addresses)-1 || keyNum < 0 {
-		return fmt.Errorf("keyNum is out of range. Expected %d-%d. Got: %d", 0, len(m.Addresses)-1, keyNum)
+	if err := m.validateKeyNum(keyNum); err != nil {
+		return err
 	}
 	return m.WaitUntilNoPendingTx(m.Addresses[keyNum], timeout)
 }
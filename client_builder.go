@@ -103,6 +103,79 @@ func (c *ClientBuilder) WithGasPriceEstimations(enabled bool, estimationBlocks u
 	return c
 }
 
+// WithGasOracle selects built-in gas oracle used by gas price estimations. Following oracles are supported: "congestion", "fixed" and "fee_history".
+// Default value is "congestion".
+func (c *ClientBuilder) WithGasOracle(name string) *ClientBuilder {
+	c.config.Network.GasOracle = name
+	// defensive programming
+	if len(c.config.Networks) == 0 {
+		c.config.Networks = append(c.config.Networks, c.config.Network)
+	} else {
+		c.config.Networks[0].GasOracle = name
+	}
+	return c
+}
+
+// WithCustomGasOracle sets your own gas oracle, which will be used instead of the built-in one. Gas price estimations need to be enabled for it to be used.
+// Default value is nil (built-in oracle is used).
+func (c *ClientBuilder) WithCustomGasOracle(oracle GasOracle) *ClientBuilder {
+	c.config.CustomGasOracle = oracle
+	return c
+}
+
+// WithBackgroundGasOracle enables or disables background gas oracle, which refreshes gas suggestions once per new block (checked every poll interval)
+// and serves them from cache to all transactions. Cached suggestions older than max staleness are recalculated on demand. Gas price estimations need to be enabled for it to be used.
+// Default value is false (suggestions are calculated for every transaction).
+func (c *ClientBuilder) WithBackgroundGasOracle(enabled bool, pollInterval, maxStaleness time.Duration) *ClientBuilder {
+	c.config.BackgroundGasOracle = &BackgroundGasOracleConfig{
+		Enabled:      enabled,
+		PollInterval: MustMakeDuration(pollInterval),
+		MaxStaleness: MustMakeDuration(maxStaleness),
+	}
+	return c
+}
+
+// WithStuckTxWatchdog enables or disables stuck transaction watchdog, which replaces every transaction signed by Seth that's pending longer than
+// transaction timeout (checked every check interval), even if it wasn't passed to Decode(). Gas price is bumped using gas bumping strategy and max gas price.
+// At most max replacements are sent for a single nonce. Default value is false.
+func (c *ClientBuilder) WithStuckTxWatchdog(enabled bool, checkInterval time.Duration, maxReplacements uint) *ClientBuilder {
+	c.config.StuckTxWatchdog = &StuckTxWatchdogConfig{
+		Enabled:         enabled,
+		CheckInterval:   MustMakeDuration(checkInterval),
+		MaxReplacements: maxReplacements,
+	}
+	return c
+}
+
+// WithCostLedger enables or disables cost ledger, which records gas used, effective gas price and L1 fee (where available) of every mined transaction
+// seen by Seth. Call `client.SaveCostLedger()` at the end to save the summary to the artifacts dir. Default value is false.
+func (c *ClientBuilder) WithCostLedger(enabled bool) *ClientBuilder {
+	c.config.CostLedger = &CostLedgerConfig{Enabled: enabled}
+	return c
+}
+
+// WithGasLimitEstimation enables or disables adjusting of gas limit estimated by eth_estimateGas. Estimate is multiplied by the multiplier
+// (1.2 if 0 is passed) or replaced with the override for the called method and never exceeds block gas limit. It's used only
+// for transactions without gas limit set in the network config or transaction options. Default value is false.
+func (c *ClientBuilder) WithGasLimitEstimation(enabled bool, multiplier float64, overrides map[string]uint64) *ClientBuilder {
+	c.config.GasLimitEstimation = &GasLimitEstimationConfig{
+		Enabled:    enabled,
+		Multiplier: multiplier,
+		Overrides:  overrides,
+	}
+	return c
+}
+
+// WithMetrics enables or disables Prometheus metrics of client activity. If listen address is set (e.g. ":9090"), metrics are served at /metrics,
+// otherwise register `client.Metrics` in your own registry. Default value is false.
+func (c *ClientBuilder) WithMetrics(enabled bool, listenAddress string) *ClientBuilder {
+	c.config.Metrics = &MetricsConfig{
+		Enabled:       enabled,
+		ListenAddress: listenAddress,
+	}
+	return c
+}
+
 // WithEIP1559DynamicFees enables or disables EIP-1559 dynamic fees. If enabled, you should set gas fee cap and gas tip cap with `WithDynamicGasPrices()`
 // Default value is true.
 func (c *ClientBuilder) WithEIP1559DynamicFees(enabled bool) *ClientBuilder {
@@ -116,6 +189,33 @@ func (c *ClientBuilder) WithEIP1559DynamicFees(enabled bool) *ClientBuilder {
 	return c
 }
 
+// WithAccessLists enables or disables automatic EIP-2930 access list generation. When enabled, `eth_createAccessList` is called for every
+// transaction before signing and the returned access list is attached to it. It can be overridden per transaction with `WithAccessList()` option.
+// Default value is false.
+func (c *ClientBuilder) WithAccessLists(enabled bool) *ClientBuilder {
+	c.config.Network.AccessListsEnabled = enabled
+	// defensive programming
+	if len(c.config.Networks) == 0 {
+		c.config.Networks = append(c.config.Networks, c.config.Network)
+	} else {
+		c.config.Networks[0].AccessListsEnabled = enabled
+	}
+	return c
+}
+
+// WithChainType sets the type of the chain, which is used to calculate L1 data fees on L2 networks when funding keys, returning funds
+// and recording costs. Supported values are "ethereum", "optimism" (OP stack) and "arbitrum". Default value is "ethereum".
+func (c *ClientBuilder) WithChainType(chainType string) *ClientBuilder {
+	c.config.Network.ChainType = chainType
+	// defensive programming
+	if len(c.config.Networks) == 0 {
+		c.config.Networks = append(c.config.Networks, c.config.Network)
+	} else {
+		c.config.Networks[0].ChainType = chainType
+	}
+	return c
+}
+
 // WithLegacyGasPrice sets the gas price for legacy transactions that will be used only if EIP-1559 dynamic fees are disabled.
 // Default value is 1 gwei.
 func (c *ClientBuilder) WithLegacyGasPrice(gasPrice int64) *ClientBuilder {
@@ -161,11 +261,36 @@ func (c *ClientBuilder) WithTransferGasFee(gasFee int64) *ClientBuilder {
 // If the gas price is bumped to a value higher than max gas price, no more gas bumping will be attempted and previous gas price will be used by all subsequent attempts. If set to 0 max price is not checked.
 // Default value is 10 retries, no max gas price and a default bumping strategy (with gas increase % based on gas_price_estimation_tx_priority)
 func (c *ClientBuilder) WithGasBumping(retries uint, maxGasPrice int64, customBumpingStrategy GasBumpStrategyFn) *ClientBuilder {
-	c.config.GasBump = &GasBumpConfig{
-		Retries:     retries,
-		MaxGasPrice: maxGasPrice,
-		StrategyFn:  customBumpingStrategy,
+	if c.config.GasBump == nil {
+		c.config.GasBump = &GasBumpConfig{}
+	}
+	c.config.GasBump.Retries = retries
+	c.config.GasBump.MaxGasPrice = maxGasPrice
+	c.config.GasBump.StrategyFn = customBumpingStrategy
+	return c
+}
+
+// WithGasBumpStrategy selects declarative gas bumping strategy ("priority", "linear", "exponential", "fixed" or "network") with its default settings.
+// It's used only if no custom bumping strategy was passed to WithGasBumping(). Declarative strategies always bump fees by at least 10%.
+// Default value is "priority".
+func (c *ClientBuilder) WithGasBumpStrategy(strategy string) *ClientBuilder {
+	if c.config.GasBump == nil {
+		c.config.GasBump = &GasBumpConfig{}
+	}
+	c.config.GasBump.Strategy = strategy
+	return c
+}
+
+// WithGasBumpFeeCaps sets separate caps for gas tip cap, gas fee cap and blob fee cap of replacement transactions. Bumped fees higher than their cap
+// are lowered to the cap, unless it's lower than what nodes accept as a replacement, in which case no more gas bumping is attempted. 0 means no cap.
+// Default value is no caps.
+func (c *ClientBuilder) WithGasBumpFeeCaps(maxGasTipCap, maxGasFeeCap, maxBlobFeeCap int64) *ClientBuilder {
+	if c.config.GasBump == nil {
+		c.config.GasBump = &GasBumpConfig{}
 	}
+	c.config.GasBump.MaxGasTipCap = maxGasTipCap
+	c.config.GasBump.MaxGasFeeCap = maxGasFeeCap
+	c.config.GasBump.MaxBlobFeeCap = maxBlobFeeCap
 	return c
 }
 
@@ -227,6 +352,17 @@ func (c *ClientBuilder) WithArtifactsFolder(folder string) *ClientBuilder {
 	return c
 }
 
+// WithSharedContractMap enables contract map shared by all Seth clients using the same file, even if they run in different processes.
+// If filename is empty "deployed_contracts_<network_name>.toml" is used. Relative paths are resolved against the config directory.
+// Default value is false (no shared contract map).
+func (c *ClientBuilder) WithSharedContractMap(enabled bool, filename string) *ClientBuilder {
+	c.config.SharedContractMap = enabled
+	if enabled {
+		c.config.ContractMapFile = filename
+	}
+	return c
+}
+
 // WithNonceManager sets the rate limit for key sync, number of retries, timeout and retry delay.
 // Default values are 10 calls per second, 3 retires, 60s timeout and 5s retry delay.
 func (c *ClientBuilder) WithNonceManager(rateLimitSec int, retries uint, timeout, retryDelay time.Duration) *ClientBuilder {
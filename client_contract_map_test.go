@@ -2,13 +2,19 @@ package seth_test
 
 import (
 	"crypto/ecdsa"
+	"fmt"
 	"github.com/barkimedes/go-deepcopy"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/smartcontractkit/seth"
 	"github.com/smartcontractkit/seth/test_utils"
 	"github.com/stretchr/testify/require"
+	"math/big"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestContractMapSavesDeployedContractsToFileAndReadsThem(t *testing.T) {
@@ -163,3 +169,96 @@ func TestContractMapNewClientIsNotCreatedWhenCorruptedContractMapFileExists_Inva
 	require.Contains(t, err.Error(), seth.ErrReadContractMap, "expected error reading invalid contract address")
 	require.Nil(t, newClient, "expected new client to be nil")
 }
+
+func TestContractMapSharedStoreMergesConcurrentWrites(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deployed_contracts_shared.toml")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// each store instance stands for a separate process using the same file
+			store := seth.NewContractMapStore(filename)
+			address := common.BigToAddress(big.NewInt(int64(i + 1))).Hex()
+			err := store.SaveContract(fmt.Sprintf("Contract%d", i), address)
+			require.NoError(t, err, "failed to save contract")
+		}(i)
+	}
+	wg.Wait()
+
+	contracts, err := seth.NewContractMapStore(filename).Load()
+	require.NoError(t, err, "failed to load shared contract map")
+	require.Equal(t, 20, len(contracts), "expected all concurrently saved contracts to be present")
+
+	_, err = os.Stat(filename + ".lock")
+	require.True(t, os.IsNotExist(err), "lock file should be removed")
+}
+
+func TestContractMapSharedMapSeesContractsSavedByOtherClients(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deployed_contracts_shared.toml")
+
+	first, err := seth.NewSharedContractMap(seth.NewContractMapStore(filename))
+	require.NoError(t, err, "failed to create shared contract map")
+	second, err := seth.NewSharedContractMap(seth.NewContractMapStore(filename))
+	require.NoError(t, err, "failed to create shared contract map")
+
+	address := "0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82"
+	require.False(t, second.IsKnownAddress(address), "address should not be known yet")
+
+	err = first.SaveContract(address, "NetworkDebugContract.abi")
+	require.NoError(t, err, "failed to save contract")
+
+	require.True(t, second.IsKnownAddress(address), "address saved by other client should be known")
+	require.Equal(t, "NetworkDebugContract", second.GetContractName(address), "incorrect contract name")
+	require.Equal(t, strings.ToLower(address), second.GetContractAddress("NetworkDebugContract"), "incorrect contract address")
+
+	contracts, err := seth.LoadDeployedContracts(filename)
+	require.NoError(t, err, "shared contract map should be readable as a regular contract map file")
+	require.Equal(t, map[string]string{address: "NetworkDebugContract"}, contracts)
+}
+
+func TestContractMapSharedStoreRemovesStaleLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "deployed_contracts_shared.toml")
+	lockFile := filename + ".lock"
+	err := os.WriteFile(lockFile, []byte("12345"), 0600)
+	require.NoError(t, err, "failed to create lock file")
+	old := time.Now().Add(-2 * seth.ContractMapStaleLockAge)
+	err = os.Chtimes(lockFile, old, old)
+	require.NoError(t, err, "failed to change lock file time")
+
+	err = seth.NewContractMapStore(filename).SaveContract("contractName", "0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	require.NoError(t, err, "failed to save contract with stale lock present")
+}
+
+func TestContractMapSharedStoreTakeOverOfStaleLockLeavesNoFilesBehind(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "deployed_contracts_shared.toml")
+	lockFile := filename + ".lock"
+	err := os.WriteFile(lockFile, []byte("12345"), 0600)
+	require.NoError(t, err, "failed to create lock file")
+	old := time.Now().Add(-2 * seth.ContractMapStaleLockAge)
+	err = os.Chtimes(lockFile, old, old)
+	require.NoError(t, err, "failed to change lock file time")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			address := common.BigToAddress(big.NewInt(int64(i + 1))).Hex()
+			err := seth.NewContractMapStore(filename).SaveContract(fmt.Sprintf("Contract%d", i), address)
+			require.NoError(t, err, "failed to save contract with stale lock present")
+		}(i)
+	}
+	wg.Wait()
+
+	contracts, err := seth.NewContractMapStore(filename).Load()
+	require.NoError(t, err, "failed to load shared contract map")
+	require.Equal(t, 5, len(contracts), "expected all contracts to be saved")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err, "failed to read directory")
+	require.Equal(t, 1, len(entries), "expected only the contract map file to be left")
+	require.Equal(t, filepath.Base(filename), entries[0].Name(), "expected only the contract map file to be left")
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/smartcontractkit/seth/contracts/bind/link_token_interface"
 	"github.com/stretchr/testify/require"
@@ -465,7 +466,18 @@ func TestTraceContractTracingUnknownAbi(t *testing.T) {
 			Input:     make(map[string]interface{}),
 			Output:    make(map[string]interface{}),
 		},
-		Events:  []seth.DecodedCommonLog{},
+		// event is still decoded, because it's defined also in NetworkDebugSubContract's ABI
+		Events: []seth.DecodedCommonLog{
+			{
+				Signature: "OneIndexEvent(uint256)",
+				EventData: map[string]interface{}{"a": big.NewInt(x)},
+				Address:   TestEnv.DebugContractAddress,
+				Topics: []string{
+					"0xeace1be0b97ec11f959499c07b9f60f0cc47bf610b28fda8fb0e970339cf3b35",
+					"0x0000000000000000000000000000000000000000000000000000000000000002",
+				},
+			},
+		},
 		Comment: seth.CommentMissingABI,
 	}
 
@@ -1237,10 +1249,11 @@ func TestTraceContractAll(t *testing.T) {
 	removeGasDataFromDecodedCalls(c.Tracer.GetAllDecodedCalls())
 
 	expectedCall := &seth.DecodedCall{
-		FromAddress: strings.ToLower(c.Addresses[0].Hex()),
-		ToAddress:   strings.ToLower(TestEnv.DebugContractAddress.Hex()),
-		From:        "you",
-		To:          "NetworkDebugContract",
+		FromAddress:  strings.ToLower(c.Addresses[0].Hex()),
+		ToAddress:    strings.ToLower(TestEnv.DebugContractAddress.Hex()),
+		From:         "you",
+		To:           "NetworkDebugContract",
+		RevertReason: "error type: CustomErr, error values: [12 21]",
 		CommonData: seth.CommonData{
 			Signature: "5e9c80d6",
 			CallType:  "CALL",
@@ -1290,10 +1303,11 @@ func TestTraceContractOnlyReverted(t *testing.T) {
 	require.Equal(t, 1, len(c.Tracer.GetAllDecodedCalls()), "expected 1 decoded transacton")
 
 	expectedCall := &seth.DecodedCall{
-		FromAddress: strings.ToLower(c.Addresses[0].Hex()),
-		ToAddress:   strings.ToLower(TestEnv.DebugContractAddress.Hex()),
-		From:        "you",
-		To:          "NetworkDebugContract",
+		FromAddress:  strings.ToLower(c.Addresses[0].Hex()),
+		ToAddress:    strings.ToLower(TestEnv.DebugContractAddress.Hex()),
+		From:         "you",
+		To:           "NetworkDebugContract",
+		RevertReason: "error type: CustomErr, error values: [12 21]",
 		CommonData: seth.CommonData{
 			Signature: "5e9c80d6",
 			CallType:  "CALL",
@@ -1343,10 +1357,11 @@ func TestTraceContractRevertedErrNoValues(t *testing.T) {
 	require.Equal(t, 1, len(c.Tracer.GetAllDecodedCalls()), "expected 1 decoded transacton")
 
 	expectedCall := &seth.DecodedCall{
-		FromAddress: strings.ToLower(c.Addresses[0].Hex()),
-		ToAddress:   strings.ToLower(TestEnv.DebugContractAddress.Hex()),
-		From:        "you",
-		To:          "NetworkDebugContract",
+		FromAddress:  strings.ToLower(c.Addresses[0].Hex()),
+		ToAddress:    strings.ToLower(TestEnv.DebugContractAddress.Hex()),
+		From:         "you",
+		To:           "NetworkDebugContract",
+		RevertReason: "error type: CustomErrNoValues, error values: []",
 		CommonData: seth.CommonData{
 			Signature: "b600141f",
 			CallType:  "CALL",
@@ -1375,10 +1390,11 @@ func TestTraceCallRevertFunctionInTheContract(t *testing.T) {
 	require.Equal(t, 1, len(c.Tracer.GetAllDecodedCalls()), "expected 1 decoded transacton")
 
 	expectedCall := &seth.DecodedCall{
-		FromAddress: strings.ToLower(c.Addresses[0].Hex()),
-		ToAddress:   strings.ToLower(TestEnv.DebugContractAddress.Hex()),
-		From:        "you",
-		To:          "NetworkDebugContract",
+		FromAddress:  strings.ToLower(c.Addresses[0].Hex()),
+		ToAddress:    strings.ToLower(TestEnv.DebugContractAddress.Hex()),
+		From:         "you",
+		To:           "NetworkDebugContract",
+		RevertReason: "error type: CustomErr, error values: [12 21]",
 		CommonData: seth.CommonData{
 			Signature: "9349d00b",
 			CallType:  "CALL",
@@ -1409,10 +1425,11 @@ func TestTraceCallRevertFunctionInSubContract(t *testing.T) {
 	require.Equal(t, 1, len(c.Tracer.GetAllDecodedCalls()), "expected 1 decoded transaction")
 
 	expectedCall := &seth.DecodedCall{
-		FromAddress: strings.ToLower(c.Addresses[0].Hex()),
-		ToAddress:   strings.ToLower(TestEnv.DebugContractAddress.Hex()),
-		From:        "you",
-		To:          "NetworkDebugContract",
+		FromAddress:  strings.ToLower(c.Addresses[0].Hex()),
+		ToAddress:    strings.ToLower(TestEnv.DebugContractAddress.Hex()),
+		From:         "you",
+		To:           "NetworkDebugContract",
+		RevertReason: "error type: CustomErr, error values: [1001 2]",
 		CommonData: seth.CommonData{
 			Signature: "11b3c478",
 			CallType:  "CALL",
@@ -1495,10 +1512,11 @@ func TestTraceeRevertReasonNonRootSender(t *testing.T) {
 	require.Equal(t, 1, len(c.Tracer.GetAllDecodedCalls()), "expected 1 decoded transaction")
 
 	expectedCall := &seth.DecodedCall{
-		FromAddress: strings.ToLower(c.Addresses[1].Hex()),
-		ToAddress:   strings.ToLower(TestEnv.DebugContractAddress.Hex()),
-		From:        "you",
-		To:          "NetworkDebugContract",
+		FromAddress:  strings.ToLower(c.Addresses[1].Hex()),
+		ToAddress:    strings.ToLower(TestEnv.DebugContractAddress.Hex()),
+		From:         "you",
+		To:           "NetworkDebugContract",
+		RevertReason: "error type: CustomErr, error values: [1001 2]",
 		CommonData: seth.CommonData{
 			Signature: "11b3c478",
 			CallType:  "CALL",
@@ -1794,3 +1812,67 @@ func removeGasDataFromDecodedCalls(decodedCall map[string][]*seth.DecodedCall) {
 		}
 	}
 }
+
+func TestTraceDecodesLogsByEmitterAddressAndKeepsUndecodedOnes(t *testing.T) {
+	cs, err := seth.NewContractStore("./contracts/abi", "")
+	require.NoError(t, err, "failed to create contract store")
+
+	caller := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	target := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	// address of a contract, which we don't know, e.g. a library called via DELEGATECALL
+	emitter := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+
+	contractMap := seth.NewEmptyContractMap()
+	contractMap.AddContract(target.Hex(), "NetworkDebugSubContract")
+	abiFinder := seth.NewABIFinder(contractMap, cs)
+
+	cfg := &seth.Config{
+		Network: &seth.Network{URLs: []string{"http://localhost:8545"}, DialTimeout: seth.MustMakeDuration(time.Second)},
+	}
+	tracer, err := seth.NewTracer(cs, &abiFinder, cfg, contractMap, []common.Address{caller})
+	require.NoError(t, err, "failed to create tracer")
+
+	subABI, ok := cs.GetABI("NetworkDebugSubContract")
+	require.True(t, ok, "missing NetworkDebugSubContract ABI")
+	input, err := subABI.Pack("traceOneInt", big.NewInt(2))
+	require.NoError(t, err, "failed to pack input")
+
+	callbackTopic := crypto.Keccak256Hash([]byte("CallbackEvent(int256)")).Hex()
+	unknownTopic := crypto.Keccak256Hash([]byte("Unknown(uint256)")).Hex()
+	valueTopic := "0x0000000000000000000000000000000000000000000000000000000000000007"
+
+	trace := seth.Trace{
+		TxHash: "0x1",
+		CallTrace: &seth.TXCallTraceOutput{
+			Call: seth.Call{
+				From:  strings.ToLower(caller.Hex()),
+				To:    strings.ToLower(target.Hex()),
+				Input: hexutil.Encode(input),
+				Type:  "CALL",
+				Logs: []seth.TraceLog{
+					{Address: strings.ToLower(emitter.Hex()), Topics: []string{callbackTopic, valueTopic}, Data: "0x"},
+					{Address: strings.ToLower(emitter.Hex()), Topics: []string{unknownTopic}, Data: "0x01"},
+				},
+			},
+		},
+	}
+
+	decodedCalls, err := tracer.DecodeTrace(seth.L, trace)
+	require.NoError(t, err, "failed to decode trace")
+	require.Len(t, decodedCalls, 1, "expected 1 decoded call")
+
+	require.EqualValues(t, []seth.DecodedCommonLog{
+		{
+			Signature: "CallbackEvent(int256)",
+			EventData: map[string]interface{}{"a": big.NewInt(7)},
+			Address:   emitter,
+			Topics:    []string{callbackTopic, valueTopic},
+		},
+		{
+			Signature: unknownTopic,
+			EventData: map[string]interface{}{"data": "0x01"},
+			Address:   emitter,
+			Topics:    []string{unknownTopic},
+		},
+	}, decodedCalls[0].Events, "logs should be decoded by emitter address or kept raw")
+}
@@ -0,0 +1,134 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// configCommand validates and shows TOML config pointed to by SETH_CONFIG_PATH
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "config",
+		HelpName:    "config",
+		Description: fmt.Sprintf("validate and show TOML config pointed to by %s", seth.CONFIG_FILE_ENV_VAR),
+		Subcommands: []*cli.Command{
+			{
+				Name:        "validate",
+				HelpName:    "validate",
+				Description: "report all problems in the config with file:line pointers: syntax, unknown keys, invalid settings of all networks and missing abi_dir, bin_dir or contract_map_file. If network is selected, also check that its RPC answers with expected chain ID",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "network", Usage: "network whose RPC should be checked, the one selected with -n or -u by default"},
+					&cli.Int64Flag{Name: "chain-id", Usage: "chain ID RPC should answer with, any by default"},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: OutputText, Usage: "output format: text or json"},
+				},
+				Action: func(cCtx *cli.Context) error {
+					output := cCtx.String("output")
+					if output != OutputText && output != OutputJSON {
+						return fmt.Errorf("unknown output format %s, use %s or %s", output, OutputText, OutputJSON)
+					}
+
+					cfgPath := os.Getenv(seth.CONFIG_FILE_ENV_VAR)
+					problems, err := seth.ValidateConfigFile(cfgPath)
+					if err != nil {
+						return err
+					}
+
+					var chainID int64
+					networkName, url := selectedNetwork(cCtx)
+					if networkName != "" || url != "" {
+						ctx, cancel := context.WithTimeout(context.Background(), seth.DefaultDialTimeout)
+						defer cancel()
+						id, rpcProblems, err := seth.CheckConfigRPC(ctx, cfgPath, networkName, url, cCtx.Int64("chain-id"))
+						if err != nil {
+							return err
+						}
+						problems = append(problems, rpcProblems...)
+						if id != nil {
+							chainID = id.Int64()
+						}
+					}
+
+					var errorCount int
+					for _, p := range problems {
+						if !p.Warning {
+							errorCount++
+						}
+					}
+
+					if output == OutputJSON {
+						if problems == nil {
+							problems = []seth.ConfigProblem{}
+						}
+						if err := printJSON(struct {
+							Problems []seth.ConfigProblem `json:"problems"`
+							ChainID  int64                `json:"chain_id,omitempty"`
+						}{problems, chainID}); err != nil {
+							return err
+						}
+					} else {
+						for _, p := range problems {
+							fmt.Println(p)
+						}
+						if chainID != 0 {
+							fmt.Printf("RPC answered with chain ID %d\n", chainID)
+						}
+						fmt.Printf("%d error(s), %d warning(s)\n", errorCount, len(problems)-errorCount)
+					}
+
+					if errorCount > 0 {
+						return fmt.Errorf("config %s has %d error(s)", cfgPath, errorCount)
+					}
+					return nil
+				},
+			},
+			{
+				Name:        "show",
+				HelpName:    "show",
+				Description: "print effective config of the selected network, with defaults applied and RPC URLs, private keys and RPC headers redacted",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "network", Usage: "network to show, the one selected with -n or -u by default"},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: OutputTOML, Usage: "output format: toml or json"},
+				},
+				Action: func(cCtx *cli.Context) error {
+					output := cCtx.String("output")
+					if output != OutputTOML && output != OutputJSON {
+						return fmt.Errorf("unknown output format %s, use %s or %s", output, OutputTOML, OutputJSON)
+					}
+
+					cfg, err := seth.ReadConfigFile(os.Getenv(seth.CONFIG_FILE_ENV_VAR))
+					if err != nil {
+						return err
+					}
+					networkName, url := selectedNetwork(cCtx)
+					if err := cfg.SelectNetwork(networkName, url); err != nil {
+						return err
+					}
+					// root key is optional here, but if it's set, it's part of the effective config
+					if rootPrivateKey := os.Getenv(seth.ROOT_PRIVATE_KEY_ENV_VAR); rootPrivateKey != "" {
+						cfg.Network.PrivateKeys = append(cfg.Network.PrivateKeys, rootPrivateKey)
+					}
+					if err := seth.ValidateConfig(cfg); err != nil {
+						return errors.Wrap(err, "config is invalid, run 'seth config validate' to see all problems")
+					}
+
+					return printOutput(output, cfg.Redacted())
+				},
+			},
+		},
+	}
+}
+
+// selectedNetwork returns network name from --network flag or the one selected with -n, and URL selected with -u
+func selectedNetwork(cCtx *cli.Context) (string, string) {
+	networkName := cCtx.String("network")
+	if networkName == "" {
+		networkName = os.Getenv(seth.NETWORK_ENV_VAR)
+	}
+	return networkName, os.Getenv(seth.URL_ENV_VAR)
+}
@@ -0,0 +1,134 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// contractFlags are flags shared by call and send commands
+func contractFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "abi", Usage: "name of ABI from abi_dir, needed only if address isn't in the contract map"},
+		&cli.IntFlag{Name: "from-key", Usage: "number of the key used as sender (0 is the root key)"},
+		&cli.StringFlag{Name: "keyfile", Aliases: []string{"k"}},
+	}
+}
+
+// callCommand runs a read-only contract call and prints decoded outputs
+func callCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "call",
+		HelpName:    "call",
+		ArgsUsage:   "<contract|address> <method> [args...]",
+		Description: "call contract method without sending a transaction and print decoded outputs. Contract can be a name from the contract map or an address, method a name or signature",
+		Flags: append(contractFlags(),
+			&cli.Uint64Flag{Name: "block", Aliases: []string{"b"}, Usage: "block number to call at, latest by default"},
+		),
+		Action: func(cCtx *cli.Context) error {
+			client, call, err := newContractMethodCall(cCtx)
+			if err != nil {
+				return err
+			}
+
+			var callOpts []seth.CallOpt
+			if block := cCtx.Uint64("block"); block != 0 {
+				callOpts = append(callOpts, seth.WithBlockNumber(block))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), client.Cfg.Network.TxnTimeout.Duration())
+			defer cancel()
+			outputs, err := client.CallContractMethod(ctx, call, client.NewCallKeyOpts(cCtx.Int("from-key"), callOpts...))
+			if err != nil {
+				return errors.Wrapf(err, "failed to call %s.%s", call.ContractName, call.Method.Sig)
+			}
+
+			return printJSON(valueFormatter(client).FormatMap(outputs, call.Address))
+		},
+	}
+}
+
+// sendCommand sends a transaction calling contract method and decodes it
+func sendCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "send",
+		HelpName:    "send",
+		ArgsUsage:   "<contract|address> <method> [args...]",
+		Description: "send transaction calling contract method using gas settings of the selected network, wait for it to be mined and print its decoded outputs and events. Outputs are known only if the transaction was traced (see tracing_level). Contract can be a name from the contract map or an address, method a name or signature",
+		Flags: append(contractFlags(),
+			&cli.Float64Flag{Name: "value", Usage: "ETH sent with the transaction"},
+			&cli.Uint64Flag{Name: "gas-limit", Usage: "gas limit, estimated by default"},
+		),
+		Action: func(cCtx *cli.Context) error {
+			client, call, err := newContractMethodCall(cCtx)
+			if err != nil {
+				return err
+			}
+
+			var txOpts []seth.TransactOpt
+			if value := cCtx.Float64("value"); value > 0 {
+				txOpts = append(txOpts, seth.WithValue(seth.EtherToWei(big.NewFloat(value))))
+			}
+			if gasLimit := cCtx.Uint64("gas-limit"); gasLimit > 0 {
+				txOpts = append(txOpts, seth.WithGasLimit(gasLimit))
+			}
+
+			decoded, err := client.SendContractMethod(call, client.NewTXKeyOpts(cCtx.Int("from-key"), txOpts...))
+			if err != nil {
+				return errors.Wrapf(err, "failed to send %s.%s", call.ContractName, call.Method.Sig)
+			}
+
+			formatted := valueFormatter(client).FormatTransaction(decoded)
+			return printJSON(sentTransaction{
+				Hash:   formatted.Hash,
+				Method: formatted.Method,
+				Output: formatted.Output,
+				Events: formatted.Events,
+			})
+		},
+	}
+}
+
+// sentTransaction is what send command prints
+type sentTransaction struct {
+	Hash   string                       `json:"hash"`
+	Method string                       `json:"method"`
+	Output map[string]interface{}       `json:"output,omitempty"`
+	Events []seth.DecodedTransactionLog `json:"events,omitempty"`
+}
+
+// valueFormatter returns client's value formatter or a default one, if formatting is not configured
+func valueFormatter(client *seth.Client) *seth.ValueFormatter {
+	if client.ValueFormatter != nil {
+		return client.ValueFormatter
+	}
+	return seth.NewValueFormatter(client.ContractAddressToNameMap, client.Addresses, nil, nil)
+}
+
+// newContractMethodCall creates client and resolves contract, method and arguments passed to call or send command
+func newContractMethodCall(cCtx *cli.Context) (*seth.Client, *seth.ContractMethodCall, error) {
+	if cCtx.Args().Len() < 2 {
+		return nil, nil, fmt.Errorf("contract and method are required, ex.: 'seth -n Geth %s LinkToken balanceOf 0x...'", cCtx.Command.Name)
+	}
+
+	client, err := newKeysClient(cCtx.String("keyfile"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if keyNum := cCtx.Int("from-key"); keyNum < 0 || keyNum >= len(client.Addresses) {
+		return nil, nil, fmt.Errorf("key %d not loaded, there are %d keys", keyNum, len(client.Addresses))
+	}
+
+	call, err := client.NewContractMethodCall(cCtx.Args().Get(0), cCtx.String("abi"), cCtx.Args().Get(1), cCtx.Args().Slice()[2:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, call, nil
+}
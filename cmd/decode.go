@@ -0,0 +1,224 @@
+package seth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// decodeFlags are flags shared by all decode subcommands
+func decodeFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "abi-dir", Usage: "directory with ABI files, abi_dir from config (SETH_CONFIG_PATH) by default"},
+		&cli.StringFlag{Name: "contract-map", Usage: "contract map file used to pick the right ABI for known addresses, contract_map_file from config by default"},
+		&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: OutputText, Usage: "output format: text or json"},
+	}
+}
+
+// decodeCommand decodes calldata, logs and revert data offline, using only ABIs from abi_dir
+func decodeCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "decode",
+		HelpName:    "decode",
+		Aliases:     []string{"dec"},
+		Description: "decode hex calldata, logs and revert data using ABIs from abi_dir, without connecting to any network",
+		Subcommands: []*cli.Command{
+			{
+				Name:        "input",
+				HelpName:    "input",
+				ArgsUsage:   "<hex>",
+				Description: "decode transaction or call input. If --to is a known address its ABI is used, otherwise all ABIs are searched for the method selector",
+				Flags: append(decodeFlags(),
+					&cli.StringFlag{Name: "to", Usage: "address of called contract"},
+				),
+				Action: func(cCtx *cli.Context) error {
+					data, err := hexArgument(cCtx)
+					if err != nil {
+						return err
+					}
+					decoder, contractMap, err := newOfflineDecoder(cCtx)
+					if err != nil {
+						return err
+					}
+					decoded, err := decoder.DecodeInput(cCtx.String("to"), data)
+					if err != nil {
+						return errors.Wrap(err, seth.ErrDecodeInput)
+					}
+					decoded.Input = seth.NewValueFormatter(contractMap, nil, nil, nil).FormatMap(decoded.Input, common.Address{})
+
+					if cCtx.String("output") == OutputJSON {
+						return printJSON(decoded)
+					}
+					fmt.Printf("Contract:  %s\n", decoded.Contract)
+					fmt.Printf("Method:    %s\n", decoded.Method)
+					fmt.Printf("Signature: 0x%s\n", decoded.Signature)
+					if decoded.DuplicateCount > 0 {
+						fmt.Printf("Warning:   %d other ABI(s) have the same method selector, pass --to to pick the right one\n", decoded.DuplicateCount)
+					}
+					printValues("Input", decoded.Input)
+					return nil
+				},
+			},
+			{
+				Name:        "log",
+				HelpName:    "log",
+				ArgsUsage:   "<hex>",
+				Description: "decode log data and topics (topic0 first). If --to is a known address its ABI is used, otherwise all ABIs are searched for the event",
+				Flags: append(decodeFlags(),
+					&cli.StringFlag{Name: "to", Aliases: []string{"address"}, Usage: "address of contract that emitted the log"},
+					&cli.StringSliceFlag{Name: "topics", Aliases: []string{"t"}, Required: true, Usage: "comma separated log topics, topic0 first"},
+				),
+				Action: func(cCtx *cli.Context) error {
+					// log without non-indexed fields has no data
+					var data []byte
+					if cCtx.Args().Len() > 0 {
+						var err error
+						data, err = hexArgument(cCtx)
+						if err != nil {
+							return err
+						}
+					}
+					var topics []common.Hash
+					for _, topic := range cCtx.StringSlice("topics") {
+						decodedTopic, err := hexutil.Decode(strings.TrimSpace(topic))
+						if err != nil || len(decodedTopic) != common.HashLength {
+							return fmt.Errorf("invalid topic %s, expected 32 bytes hex", topic)
+						}
+						topics = append(topics, common.BytesToHash(decodedTopic))
+					}
+					decoder, contractMap, err := newOfflineDecoder(cCtx)
+					if err != nil {
+						return err
+					}
+					decoded, err := decoder.DecodeLog(cCtx.String("to"), topics, data)
+					if err != nil {
+						return errors.Wrap(err, seth.ErrDecodeLog)
+					}
+					decoded.EventData = seth.NewValueFormatter(contractMap, nil, nil, nil).FormatMap(decoded.EventData, common.Address{})
+
+					if cCtx.String("output") == OutputJSON {
+						return printJSON(decoded)
+					}
+					fmt.Printf("Contract:  %s\n", decoded.Contract)
+					fmt.Printf("Event:     %s\n", decoded.Signature)
+					printValues("Event data", decoded.EventData)
+					return nil
+				},
+			},
+			{
+				Name:        "error",
+				HelpName:    "error",
+				ArgsUsage:   "<hex>",
+				Description: "decode revert data using custom errors from all ABIs, Error(string) and Panic(uint256)",
+				Flags:       decodeFlags(),
+				Action: func(cCtx *cli.Context) error {
+					data, err := hexArgument(cCtx)
+					if err != nil {
+						return err
+					}
+					decoder, _, err := newOfflineDecoder(cCtx)
+					if err != nil {
+						return err
+					}
+					reason, err := decoder.DecodeError(data)
+					if err != nil {
+						return err
+					}
+
+					if cCtx.String("output") == OutputJSON {
+						return printJSON(map[string]string{"selector": hexutil.Encode(data[:4]), "revert_reason": reason})
+					}
+					fmt.Println(reason)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// newOfflineDecoder creates decoder with ABIs from --abi-dir or abi_dir from config and contract map from --contract-map
+// or contract_map_file from config. Config is optional, network isn't needed.
+func newOfflineDecoder(cCtx *cli.Context) (*seth.OfflineDecoder, seth.ContractMap, error) {
+	if output := cCtx.String("output"); output != OutputText && output != OutputJSON {
+		return nil, seth.ContractMap{}, fmt.Errorf("unknown output format %s, use %s or %s", output, OutputText, OutputJSON)
+	}
+
+	abiDir := cCtx.String("abi-dir")
+	contractMapFile := cCtx.String("contract-map")
+	if cfgPath := os.Getenv(seth.CONFIG_FILE_ENV_VAR); cfgPath != "" {
+		cfg, err := seth.ReadConfigFile(cfgPath)
+		if err != nil {
+			return nil, seth.ContractMap{}, err
+		}
+		if abiDir == "" && cfg.ABIDir != "" {
+			abiDir = filepath.Join(cfg.ConfigDir, cfg.ABIDir)
+		}
+		if contractMapFile == "" {
+			contractMapFile = cfg.ContractMapFile
+		}
+	}
+	if abiDir == "" {
+		return nil, seth.ContractMap{}, fmt.Errorf("no ABIs to decode with, set abi_dir in config (%s) or use --abi-dir", seth.CONFIG_FILE_ENV_VAR)
+	}
+
+	cs, err := seth.NewContractStore(abiDir, "")
+	if err != nil {
+		return nil, seth.ContractMap{}, errors.Wrap(err, seth.ErrCreateABIStore)
+	}
+
+	contractMap := seth.NewEmptyContractMap()
+	if contractMapFile != "" {
+		contracts, err := seth.LoadDeployedContracts(contractMapFile)
+		if err != nil {
+			return nil, seth.ContractMap{}, errors.Wrap(err, seth.ErrReadContractMap)
+		}
+		for address, name := range contracts {
+			contractMap.AddContract(address, name)
+		}
+	}
+
+	return seth.NewOfflineDecoder(cs, contractMap), contractMap, nil
+}
+
+// hexArgument decodes the first argument as hex
+func hexArgument(cCtx *cli.Context) ([]byte, error) {
+	if cCtx.Args().Len() != 1 {
+		return nil, fmt.Errorf("expected exactly one hex argument, ex.: 'seth decode %s 0xa9059cbb...'", cCtx.Command.Name)
+	}
+	arg := strings.TrimSpace(cCtx.Args().First())
+	if !strings.HasPrefix(arg, "0x") {
+		arg = "0x" + arg
+	}
+	data, err := hexutil.Decode(arg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid hex %s", cCtx.Args().First())
+	}
+	return data, nil
+}
+
+// printValues prints decoded values sorted by name, indented one level deeper than the title
+func printValues(title string, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	indent := title[:len(title)-len(strings.TrimLeft(title, " "))]
+	fmt.Printf("%s:\n", title)
+	for _, name := range names {
+		fmt.Printf("%s  %s: %v\n", indent, name, values[name])
+	}
+}
@@ -0,0 +1,61 @@
+package seth
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// deployCommand deploys contract from the Contract Store (abi_dir and bin_dir), using gas settings of the selected network
+func deployCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "deploy",
+		HelpName:    "deploy",
+		Aliases:     []string{"d"},
+		ArgsUsage:   "<Name> [args...]",
+		Description: "deploy contract from abi_dir/bin_dir, constructor arguments are parsed according to ABI input types (arrays and tuples as JSON). Prints contract address and saves it to the contract map",
+		Flags: []cli.Flag{
+			&cli.IntFlag{Name: "from-key", Usage: "number of the key used to sign the deployment (0 is the root key)"},
+			&cli.StringFlag{Name: "keyfile", Aliases: []string{"k"}},
+		},
+		Action: func(cCtx *cli.Context) error {
+			name := cCtx.Args().First()
+			if name == "" {
+				return errors.New("contract name is required, ex.: 'seth -n Geth deploy LinkToken'")
+			}
+
+			cfg, err := readConfigWithKeyFile(cCtx.String("keyfile"))
+			if err != nil {
+				return err
+			}
+			cfg.SaveDeployedContractsMap = true
+			client, err := seth.NewClientWithConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			if keyNum := cCtx.Int("from-key"); keyNum < 0 || keyNum >= len(client.Addresses) {
+				return fmt.Errorf("key %d not loaded, there are %d keys", keyNum, len(client.Addresses))
+			}
+
+			params, err := client.ParseConstructorArguments(name, cCtx.Args().Tail())
+			if err != nil {
+				return errors.Wrapf(err, "failed to parse %s constructor arguments", name)
+			}
+
+			data, err := client.DeployContractFromContractStore(client.NewTXKeyOpts(cCtx.Int("from-key")), name, params...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to deploy %s", name)
+			}
+			if !client.Cfg.ShouldSaveDeployedContractMap() {
+				seth.L.Warn().Msg("Contract map isn't saved for simulated networks, unless shared contract map is enabled")
+			}
+
+			fmt.Println(data.Address.Hex())
+			return nil
+		},
+	}
+}
@@ -0,0 +1,159 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// keysCommand manages the root key (SETH_ROOT_PRIVATE_KEY) and keys loaded from a key file
+func keysCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "keys",
+		HelpName:    "keys",
+		Aliases:     []string{"k"},
+		Description: "create, fund and inspect keys. Root key is read from SETH_ROOT_PRIVATE_KEY, other keys from the key file (-k) and network's private_keys_secret",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "keyfile", Aliases: []string{"k"}},
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:        "new",
+				HelpName:    "new",
+				Description: "create N new keys and save them to a key file",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "count", Aliases: []string{"c"}, Required: true},
+					&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Required: true},
+				},
+				Action: func(cCtx *cli.Context) error {
+					path := cCtx.String("file")
+					if _, err := os.Stat(path); err == nil {
+						return fmt.Errorf("key file %s already exists, refusing to overwrite it", path)
+					}
+					kf, err := seth.NewKeyFile(cCtx.Int("count"))
+					if err != nil {
+						return err
+					}
+					if err := kf.Save(path); err != nil {
+						return errors.Wrap(err, "failed to save key file")
+					}
+					seth.L.Info().
+						Int("Keys", len(kf.Keys)).
+						Str("File", path).
+						Msg("Saved new keys")
+					return nil
+				},
+			},
+			{
+				Name:        "balances",
+				HelpName:    "balances",
+				Description: "print balances of all keys",
+				Action: func(cCtx *cli.Context) error {
+					client, err := newKeysClient(cCtx.String("keyfile"))
+					if err != nil {
+						return err
+					}
+					balances, err := client.KeyBalances(context.Background())
+					if err != nil {
+						return err
+					}
+					w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(w, "KEY\tADDRESS\tBALANCE (ETH)\tBALANCE (WEI)")
+					total := big.NewInt(0)
+					for _, kb := range balances {
+						fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", kb.KeyNum, kb.Address.Hex(), seth.WeiToEther(kb.Balance).Text('f', -1), kb.Balance.String())
+						total.Add(total, kb.Balance)
+					}
+					fmt.Fprintf(w, "TOTAL\t\t%s\t%s\n", seth.WeiToEther(total).Text('f', -1), total.String())
+					return w.Flush()
+				},
+			},
+			{
+				Name:        "fund",
+				HelpName:    "fund",
+				Description: "fund all keys from the root key. By default root key's balance is split equally (leaving a buffer of -b ETH), with -a each key is topped up to given amount of ETH",
+				Flags: []cli.Flag{
+					&cli.Float64Flag{Name: "amount", Aliases: []string{"a"}},
+					&cli.Int64Flag{Name: "buffer", Aliases: []string{"b"}},
+				},
+				Action: func(cCtx *cli.Context) error {
+					client, err := newKeysClient(cCtx.String("keyfile"))
+					if err != nil {
+						return err
+					}
+					if amount := cCtx.Float64("amount"); amount > 0 {
+						return seth.TopUpFunds(client, seth.EtherToWei(big.NewFloat(amount)))
+					}
+					return seth.SplitFunds(client, cCtx.Int64("buffer"))
+				},
+			},
+			{
+				Name:        "return",
+				HelpName:    "return",
+				Description: "return funds from all keys to the root key or to given address",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "to", Aliases: []string{"t"}},
+				},
+				Action: func(cCtx *cli.Context) error {
+					client, err := newKeysClient(cCtx.String("keyfile"))
+					if err != nil {
+						return err
+					}
+					return seth.ReturnFunds(client, cCtx.String("to"))
+				},
+			},
+			{
+				Name:        "nonces",
+				HelpName:    "nonces",
+				Description: "print latest and pending nonces of all keys, same as 'nonce status'",
+				Action: func(cCtx *cli.Context) error {
+					client, err := newKeysClient(cCtx.String("keyfile"))
+					if err != nil {
+						return err
+					}
+					statuses, err := nonceStatuses(client)
+					if err != nil {
+						return err
+					}
+					return printNonceStatuses(statuses)
+				},
+			},
+		},
+	}
+}
+
+// newKeysClient creates client with the root key and keys from the key file, without ephemeral keys. RPC health check is
+// disabled, because it sends a transaction from the root key, which might be the stuck key that is inspected or repaired
+func newKeysClient(keyFile string) (*seth.Client, error) {
+	cfg, err := readConfigWithKeyFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.CheckRpcHealthOnStart = false
+	return seth.NewClientWithConfig(cfg)
+}
+
+// readConfigWithKeyFile reads config with the root key and appends keys from the key file (if set). Ephemeral keys are disabled
+func readConfigWithKeyFile(keyFile string) (*seth.Config, error) {
+	cfg, err := seth.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	zero := int64(0)
+	cfg.EphemeralAddrs = &zero
+	if keyFile != "" {
+		kf, err := seth.LoadKeyFile(keyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load key file %s", keyFile)
+		}
+		cfg.Network.PrivateKeys = append(cfg.Network.PrivateKeys, kf.PrivateKeys()...)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,138 @@
+package seth
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// nonceCommand inspects and repairs nonces of the root key and keys loaded from a key file
+func nonceCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "nonce",
+		HelpName:    "nonce",
+		Description: "inspect nonces of all keys and replace stuck pending transactions. Queued transactions (gaps in nonces) are detected only if node exposes txpool API",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "keyfile", Aliases: []string{"k"}},
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:        "status",
+				HelpName:    "status",
+				Description: "print latest and pending nonce and queued transactions of all keys",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: OutputText, Usage: "output format: text or json"},
+				},
+				Action: func(cCtx *cli.Context) error {
+					output := cCtx.String("output")
+					if output != OutputText && output != OutputJSON {
+						return fmt.Errorf("unknown output format %s, use %s or %s", output, OutputText, OutputJSON)
+					}
+					client, err := newKeysClient(cCtx.String("keyfile"))
+					if err != nil {
+						return err
+					}
+
+					statuses, err := nonceStatuses(client)
+					if err != nil {
+						return err
+					}
+
+					if output == OutputJSON {
+						return printJSON(statuses)
+					}
+					return printNonceStatuses(statuses)
+				},
+			},
+			{
+				Name:        "fix",
+				HelpName:    "fix",
+				Description: "replace pending transactions with bumped no-op self-transfers and fill gaps before queued transactions, until pending nonce equals latest nonce",
+				Flags: []cli.Flag{
+					&cli.IntFlag{Name: "key", Value: -1, Usage: "index of the key to fix (0 is the root key), all keys with pending or queued transactions by default"},
+					&cli.DurationFlag{Name: "timeout", Aliases: []string{"t"}, Value: 5 * time.Minute, Usage: "how long to keep bumping replacements of each key"},
+				},
+				Action: func(cCtx *cli.Context) error {
+					client, err := newKeysClient(cCtx.String("keyfile"))
+					if err != nil {
+						return err
+					}
+
+					keyNums := []int{cCtx.Int("key")}
+					if keyNums[0] < 0 {
+						keyNums = keyNums[:0]
+						for keyNum := range client.Addresses {
+							keyNums = append(keyNums, keyNum)
+						}
+					}
+
+					var failed int
+					for _, keyNum := range keyNums {
+						status, err := client.GetNonceFixStatus(keyNum)
+						if err != nil {
+							return err
+						}
+						if status.IsClean() {
+							fmt.Printf("Key %d (%s): nothing to fix, nonce %d\n", keyNum, status.Address.Hex(), status.LastNonce)
+							continue
+						}
+						result, err := client.FixNonce(keyNum, cCtx.Duration("timeout"))
+						if err != nil {
+							seth.L.Error().Err(err).Int("KeyNum", keyNum).Msg("Failed to fix nonce")
+							failed++
+						}
+						if result != nil {
+							fmt.Printf("Key %d (%s): replaced %d transaction(s), nonce %d -> %d, pending %d\n", keyNum, status.Address.Hex(), len(result.Replacements), result.Before.LastNonce, result.After.LastNonce, result.After.Pending())
+						}
+					}
+
+					if failed > 0 {
+						return fmt.Errorf("failed to fix nonce of %d key(s)", failed)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// nonceStatuses returns nonce status of all loaded keys, root key first
+func nonceStatuses(client *seth.Client) ([]seth.NonceFixStatus, error) {
+	statuses := make([]seth.NonceFixStatus, 0, len(client.Addresses))
+	for keyNum := range client.Addresses {
+		status, err := client.GetNonceFixStatus(keyNum)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// printNonceStatuses prints nonce statuses as a table
+func printNonceStatuses(statuses []seth.NonceFixStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tADDRESS\tLATEST\tPENDING\tPENDING TXS\tQUEUED")
+	for _, s := range statuses {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%s\n", s.KeyNum, s.Address.Hex(), s.LastNonce, s.PendingNonce, s.Pending(), formatNonces(s.Queued))
+	}
+	return w.Flush()
+}
+
+// formatNonces returns comma-separated nonces or "-" if there are none
+func formatNonces(nonces []uint64) string {
+	if len(nonces) == 0 {
+		return "-"
+	}
+	formatted := make([]string, len(nonces))
+	for i, n := range nonces {
+		formatted[i] = fmt.Sprint(n)
+	}
+	return strings.Join(formatted, ",")
+}
@@ -0,0 +1,94 @@
+package seth
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputCSV  = "csv"
+	OutputTOML = "toml"
+)
+
+// printOutput prints value to stdout in given machine-readable format: json, toml or csv. CSV has a header row with
+// names taken from toml tags, nested structs are flattened, e.g. gas_price_perc_99
+func printOutput(format string, v interface{}) error {
+	switch format {
+	case OutputJSON:
+		return printJSON(v)
+	case OutputTOML:
+		marshalled, err := toml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(marshalled))
+		return nil
+	case OutputCSV:
+		var header, row []string
+		flattenForCSV("", reflect.ValueOf(v), &header, &row)
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return fmt.Errorf("unknown output format %s, use %s, %s or %s", format, OutputJSON, OutputCSV, OutputTOML)
+	}
+}
+
+// flattenForCSV appends names and values of all struct fields, recursing into nested structs
+func flattenForCSV(prefix string, v reflect.Value, header, row *[]string) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		// big.Int and other types with custom formatting are printed as they are
+		if _, ok := v.Interface().(fmt.Stringer); ok {
+			break
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		*header = append(*header, prefix)
+		*row = append(*row, fmt.Sprint(v.Interface()))
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		flattenForCSV(name, v.Field(i), header, row)
+	}
+}
+
+// printJSON prints value as indented JSON to stdout
+func printJSON(v interface{}) error {
+	marshalled, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(marshalled))
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/pkg/errors"
@@ -34,21 +35,30 @@ func RunCLI(args []string) error {
 			&cli.StringFlag{Name: "url", Aliases: []string{"u"}},
 		},
 		Before: func(cCtx *cli.Context) error {
+			command, subcommand := resolveCommand(cCtx.App, cCtx.Args())
+			// creating keys and decoding don't need any network
+			if (command == "keys" && subcommand == "new") || command == "decode" {
+				return nil
+			}
 			networkName := cCtx.String("networkName")
 			url := cCtx.String("url")
-			if networkName == "" && url == "" {
-				return errors.New(ErrNoNetwork)
-			}
 			if networkName != "" {
 				_ = os.Setenv(seth.NETWORK_ENV_VAR, networkName)
 			}
 			if url != "" {
 				_ = os.Setenv(seth.URL_ENV_VAR, url)
 			}
-			if cCtx.Args().Len() > 0 && cCtx.Args().First() != "trace" {
+			// config commands use the network if it's selected, but can also select it with their own flag
+			if command == "config" {
+				return nil
+			}
+			if networkName == "" && url == "" {
+				return errors.New(ErrNoNetwork)
+			}
+			if cCtx.Args().Len() > 0 && command != "trace" {
 				var err error
-				switch cCtx.Args().First() {
-				case "gas", "stats":
+				switch command {
+				case "gas", "stats", "fee_history":
 					var cfg *seth.Config
 					var pk string
 					_, pk, err = seth.NewAddress()
@@ -69,6 +79,19 @@ func RunCLI(args []string) error {
 					if err != nil {
 						return err
 					}
+				case "tx":
+					var cfg *seth.Config
+					cfg, err = seth.ReadConfig()
+					if err != nil {
+						return err
+					}
+					// replacement transactions are sent from the key that sent the original one, there's no need to create and fund ephemeral keys
+					zero := int64(0)
+					cfg.EphemeralAddrs = &zero
+					C, err = seth.NewClientWithConfig(cfg)
+					if err != nil {
+						return err
+					}
 				case "trace":
 					return nil
 				}
@@ -87,6 +110,7 @@ func RunCLI(args []string) error {
 				Flags: []cli.Flag{
 					&cli.Int64Flag{Name: "start_block", Aliases: []string{"s"}},
 					&cli.Int64Flag{Name: "end_block", Aliases: []string{"e"}},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "print stats to stdout as json, csv or toml instead of logging them"},
 				},
 				Action: func(cCtx *cli.Context) error {
 					start := cCtx.Int64("start_block")
@@ -101,6 +125,13 @@ func RunCLI(args []string) error {
 					if err != nil {
 						return err
 					}
+					if output := cCtx.String("output"); output != "" {
+						result, err := cs.StatsResult(big.NewInt(start), big.NewInt(end))
+						if err != nil {
+							return err
+						}
+						return printOutput(output, result)
+					}
 					return cs.Stats(big.NewInt(start), big.NewInt(end))
 				},
 			},
@@ -112,6 +143,7 @@ func RunCLI(args []string) error {
 				Flags: []cli.Flag{
 					&cli.Int64Flag{Name: "blocks", Aliases: []string{"b"}},
 					&cli.Float64Flag{Name: "tipPercentile", Aliases: []string{"tp"}},
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "print gas suggestions to stdout as json, csv or toml instead of logging them"},
 				},
 				Action: func(cCtx *cli.Context) error {
 					ge := seth.NewGasEstimator(C)
@@ -121,6 +153,9 @@ func RunCLI(args []string) error {
 					if err != nil {
 						return err
 					}
+					if output := cCtx.String("output"); output != "" {
+						return printOutput(output, stats)
+					}
 					seth.L.Info().
 						Interface("Max", stats.GasPrice.Max).
 						Interface("99", stats.GasPrice.Perc99).
@@ -164,6 +199,91 @@ func RunCLI(args []string) error {
 					return err
 				},
 			},
+			{
+				Name:        "fee_history",
+				HelpName:    "fee_history",
+				Description: "record headers, fee history and suggested fees of the latest blocks to a JSON fixture, which can be replayed offline",
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{Name: "blocks", Aliases: []string{"b"}},
+					&cli.StringFlag{Name: "dir", Aliases: []string{"d"}, Value: "fee_history_fixtures"},
+				},
+				Action: func(cCtx *cli.Context) error {
+					fixture, err := C.RecordFeeHistoryFixture(context.Background(), cCtx.Uint64("blocks"))
+					if err != nil {
+						return err
+					}
+					path, err := fixture.Save(cCtx.String("dir"))
+					if err != nil {
+						return err
+					}
+					seth.L.Info().
+						Str("File", path).
+						Uint64("From", fixture.OldestBlock()).
+						Uint64("To", fixture.LatestBlock()).
+						Msg("Saved fee history fixture")
+					return nil
+				},
+			},
+			{
+				Name:        "tx",
+				HelpName:    "tx",
+				Description: "cancel or speed up pending transaction sent from the root key (SETH_ROOT_PRIVATE_KEY) or any of the network's private keys from the config",
+				Subcommands: []*cli.Command{
+					{
+						Name:        "cancel",
+						HelpName:    "cancel",
+						Description: "replace pending transaction with a zero-value transfer to self, using the same nonce and bumped gas price",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "txHash", Aliases: []string{"t"}, Required: true},
+						},
+						Action: func(cCtx *cli.Context) error {
+							tx, err := pendingTransaction(cCtx.String("txHash"))
+							if err != nil {
+								return err
+							}
+							replacementTx, err := C.Cancel(tx)
+							if err != nil {
+								return errors.Wrapf(err, "failed to cancel transaction %s", tx.Hash().Hex())
+							}
+							_, err = C.Decode(replacementTx, nil)
+							return err
+						},
+					},
+					{
+						Name:        "speedup",
+						HelpName:    "speedup",
+						Description: "replace pending transaction with the same one, but with bumped gas price. Bumping strategy is selected by priority (slow, standard, fast, degen), by default the one from config is used",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "txHash", Aliases: []string{"t"}, Required: true},
+							&cli.StringFlag{Name: "priority", Aliases: []string{"p"}},
+						},
+						Action: func(cCtx *cli.Context) error {
+							tx, err := pendingTransaction(cCtx.String("txHash"))
+							if err != nil {
+								return err
+							}
+							var strategy seth.GasBumpStrategyFn
+							if priority := cCtx.String("priority"); priority != "" {
+								strategy = seth.PriorityBasedGasBumpingStrategyFn(priority)
+							}
+							replacementTx, err := C.SpeedUp(tx, strategy)
+							if err != nil {
+								return errors.Wrapf(err, "failed to speed up transaction %s", tx.Hash().Hex())
+							}
+							_, err = C.Decode(replacementTx, nil)
+							return err
+						},
+					},
+				},
+			},
+			keysCommand(),
+			deployCommand(),
+			callCommand(),
+			sendCommand(),
+			decodeCommand(),
+			watchCommand(),
+			configCommand(),
+			nonceCommand(),
 			{
 				Name:        "trace",
 				HelpName:    "trace",
@@ -304,3 +424,34 @@ func RunCLI(args []string) error {
 	}
 	return app.Run(args)
 }
+
+// resolveCommand returns names of the command and subcommand run with given arguments, so that they can be matched no matter
+// which alias was used. Subcommand is the first argument, which is a name or an alias of one of command's subcommands.
+func resolveCommand(app *cli.App, args cli.Args) (string, string) {
+	command := app.Command(args.First())
+	if command == nil {
+		return args.First(), ""
+	}
+	for _, arg := range args.Tail() {
+		for _, subcommand := range command.Subcommands {
+			if subcommand.HasName(arg) {
+				return command.Name, subcommand.Name
+			}
+		}
+	}
+	return command.Name, ""
+}
+
+// pendingTransaction fetches transaction by hash and makes sure it's still pending
+func pendingTransaction(txHash string) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), C.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+	tx, isPending, err := C.Client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get transaction %s", txHash)
+	}
+	if !isPending {
+		return nil, fmt.Errorf("transaction %s is not pending", txHash)
+	}
+	return tx, nil
+}
@@ -0,0 +1,126 @@
+package seth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// watchCommand follows new heads and prints decoded activity of contracts from the contract map and configured keys
+func watchCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "watch",
+		HelpName:    "watch",
+		Aliases:     []string{"w"},
+		Description: "follow new heads and print decoded transactions and logs involving contracts from the contract map or configured keys, until interrupted. Reverted transactions are traced if debug API is available",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{Name: "contract", Aliases: []string{"c"}, Usage: "watch only given contracts (names from the contract map or addresses)"},
+			&cli.StringSliceFlag{Name: "method", Aliases: []string{"m"}, Usage: "report only transactions calling given methods (names or signatures)"},
+			&cli.StringSliceFlag{Name: "event", Aliases: []string{"e"}, Usage: "report only transactions emitting given events (names or signatures)"},
+			&cli.StringFlag{Name: "keyfile", Aliases: []string{"k"}, Usage: "key file with additional keys to watch"},
+			&cli.DurationFlag{Name: "interval", Aliases: []string{"i"}, Value: seth.DefaultActivityWatcherPollInterval, Usage: "how often to poll for new heads"},
+			&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Value: OutputText, Usage: "output format: text or json (one object per line)"},
+		},
+		Action: func(cCtx *cli.Context) error {
+			output := cCtx.String("output")
+			if output != OutputText && output != OutputJSON {
+				return fmt.Errorf("unknown output format %s, use %s or %s", output, OutputText, OutputJSON)
+			}
+
+			// nothing is sent, so any root key will do if none was set
+			if os.Getenv(seth.ROOT_PRIVATE_KEY_ENV_VAR) == "" {
+				_, pk, err := seth.NewAddress()
+				if err != nil {
+					return err
+				}
+				if err := os.Setenv(seth.ROOT_PRIVATE_KEY_ENV_VAR, pk); err != nil {
+					return err
+				}
+			}
+			client, err := newKeysClient(cCtx.String("keyfile"))
+			if err != nil {
+				return err
+			}
+			if client.Cfg.IsSimulatedNetwork() && len(client.ContractAddressToNameMap.GetContractMap()) == 0 {
+				seth.L.Warn().Msg("Contract map isn't loaded for simulated networks unless shared contract map is enabled, only activity of configured keys will be reported")
+			}
+
+			watcher, err := seth.NewActivityWatcher(client, seth.ActivityFilter{
+				Contracts: cCtx.StringSlice("contract"),
+				Methods:   cCtx.StringSlice("method"),
+				Events:    cCtx.StringSlice("event"),
+			})
+			if err != nil {
+				return err
+			}
+			watcher.PollInterval = cCtx.Duration("interval")
+
+			formatter := client.ValueFormatter
+			if formatter == nil {
+				formatter = seth.NewValueFormatter(client.ContractAddressToNameMap, client.Addresses, nil, nil)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			seth.L.Info().
+				Int("Addresses", watcher.WatchedAddresses()).
+				Str("Network", client.Cfg.Network.Name).
+				Msg("Watching new heads, press Ctrl+C to stop")
+
+			return watcher.Watch(ctx, func(a *seth.Activity) {
+				printActivity(output, formatter, a)
+			})
+		},
+	}
+}
+
+// printActivity prints activity with formatted values to stdout, either as text or as a single line of JSON
+func printActivity(output string, formatter *seth.ValueFormatter, a *seth.Activity) {
+	formatted := *a
+	formatted.Decoded = formatter.FormatTransaction(a.Decoded)
+	formatted.Trace = formatter.FormatCalls(a.Trace)
+
+	if output == OutputJSON {
+		// raw transaction and receipt are noise in a stream, hash and decoded data are enough
+		decoded := *formatted.Decoded
+		decoded.Transaction = nil
+		decoded.Receipt = nil
+		formatted.Decoded = &decoded
+		marshalled, err := json.Marshal(formatted)
+		if err != nil {
+			seth.L.Error().Err(err).Str("Transaction", a.TxHash).Msg("Failed to marshal activity")
+			return
+		}
+		fmt.Println(string(marshalled))
+		return
+	}
+
+	to := "contract creation"
+	if a.To != nil {
+		to = formatter.FormatAddress(*a.To)
+	}
+	fmt.Printf("Block %d: %s %s -> %s %s\n", a.BlockNumber, a.TxHash, formatter.FormatAddress(a.From), to, formatted.Decoded.Method)
+	if a.Reverted {
+		fmt.Printf("  Reverted: %s\n", a.RevertReason)
+	}
+	printValues("  Input", formatted.Decoded.Input)
+	for _, event := range formatted.Decoded.Events {
+		fmt.Printf("  Event %s emitted by %s\n", event.Signature, formatter.FormatAddress(event.Address))
+		printValues("    Event data", event.EventData)
+	}
+	for _, call := range formatted.Trace {
+		fmt.Printf("  Call %s -> %s %s", call.From, call.To, call.Method)
+		if call.RevertReason != "" {
+			fmt.Printf(" (reverted: %s)", call.RevertReason)
+		}
+		fmt.Println()
+	}
+}
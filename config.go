@@ -46,30 +46,53 @@ type Config struct {
 
 	// external fields
 	// ArtifactDir is the directory where all artifacts generated by seth are stored (e.g. transaction traces)
-	ArtifactsDir                  string            `toml:"artifacts_dir"`
-	EphemeralAddrs                *int64            `toml:"ephemeral_addresses_number"`
-	RootKeyFundsBuffer            *int64            `toml:"root_key_funds_buffer"`
-	ABIDir                        string            `toml:"abi_dir"`
-	BINDir                        string            `toml:"bin_dir"`
-	ContractMapFile               string            `toml:"contract_map_file"`
-	SaveDeployedContractsMap      bool              `toml:"save_deployed_contracts_map"`
-	Network                       *Network          `toml:"network"`
-	Networks                      []*Network        `toml:"networks"`
-	NonceManager                  *NonceManagerCfg  `toml:"nonce_manager"`
-	TracingLevel                  string            `toml:"tracing_level"`
-	TraceOutputs                  []string          `toml:"trace_outputs"`
-	PendingNonceProtectionEnabled bool              `toml:"pending_nonce_protection_enabled"`
-	ConfigDir                     string            `toml:"abs_path"`
-	ExperimentsEnabled            []string          `toml:"experiments_enabled"`
-	CheckRpcHealthOnStart         bool              `toml:"check_rpc_health_on_start"`
-	BlockStatsConfig              *BlockStatsConfig `toml:"block_stats"`
-	GasBump                       *GasBumpConfig    `toml:"gas_bump"`
+	ArtifactsDir                  string                 `toml:"artifacts_dir"`
+	EphemeralAddrs                *int64                 `toml:"ephemeral_addresses_number"`
+	RootKeyFundsBuffer            *int64                 `toml:"root_key_funds_buffer"`
+	ABIDir                        string                 `toml:"abi_dir"`
+	BINDir                        string                 `toml:"bin_dir"`
+	ContractMapFile               string                 `toml:"contract_map_file"`
+	SaveDeployedContractsMap      bool                   `toml:"save_deployed_contracts_map"`
+	SharedContractMap             bool                   `toml:"shared_contract_map"`
+	Network                       *Network               `toml:"network"`
+	Networks                      []*Network             `toml:"networks"`
+	NonceManager                  *NonceManagerCfg       `toml:"nonce_manager"`
+	TracingLevel                  string                 `toml:"tracing_level"`
+	TraceOutputs                  []string               `toml:"trace_outputs"`
+	PendingNonceProtectionEnabled bool                   `toml:"pending_nonce_protection_enabled"`
+	ConfigDir                     string                 `toml:"abs_path"`
+	ExperimentsEnabled            []string               `toml:"experiments_enabled"`
+	CheckRpcHealthOnStart         bool                   `toml:"check_rpc_health_on_start"`
+	BlockStatsConfig              *BlockStatsConfig      `toml:"block_stats"`
+	GasBump                       *GasBumpConfig         `toml:"gas_bump"`
+	ValueFormatting               *ValueFormattingConfig `toml:"value_formatting"`
+	// CustomGasOracle, if set, is used instead of the built-in gas oracle selected by network's `gas_oracle` setting
+	CustomGasOracle GasOracle `toml:"-"`
+	// BackgroundGasOracle, if enabled, refreshes gas suggestions once per new block and serves them from cache
+	BackgroundGasOracle *BackgroundGasOracleConfig `toml:"background_gas_oracle"`
+	// StuckTxWatchdog, if enabled, replaces all transactions signed by Seth that are pending longer than transaction timeout
+	StuckTxWatchdog *StuckTxWatchdogConfig `toml:"stuck_tx_watchdog"`
+	// CostLedger, if enabled, records gas costs of all mined transactions seen by Seth
+	CostLedger *CostLedgerConfig `toml:"cost_ledger"`
+	// GasLimitEstimation, if enabled, multiplies gas limit estimated by eth_estimateGas and clamps it at the block gas limit
+	GasLimitEstimation *GasLimitEstimationConfig `toml:"gas_limit_estimation"`
+	// Metrics, if enabled, records Prometheus metrics of client activity and optionally serves them over HTTP
+	Metrics *MetricsConfig `toml:"metrics"`
 }
 
 type GasBumpConfig struct {
 	Retries     uint              `toml:"retries"`
 	MaxGasPrice int64             `toml:"max_gas_price"`
 	StrategyFn  GasBumpStrategyFn `toml:"-"`
+	// Strategy selects declarative strategy used when StrategyFn is not set: "priority" (default), "linear", "exponential", "fixed" or "network"
+	Strategy          string  `toml:"strategy"`
+	LinearStepPercent int64   `toml:"linear_step_percent"`
+	ExponentialFactor float64 `toml:"exponential_factor"`
+	FixedIncrement    int64   `toml:"fixed_increment"`
+	// caps for individual fees, 0 means no cap
+	MaxGasTipCap  int64 `toml:"max_gas_tip_cap"`
+	MaxGasFeeCap  int64 `toml:"max_gas_fee_cap"`
+	MaxBlobFeeCap int64 `toml:"max_blob_fee_cap"`
 }
 
 // GasBumpRetries returns the number of retries for gas bumping
@@ -108,6 +131,10 @@ type Network struct {
 	GasPriceEstimationEnabled    bool      `toml:"gas_price_estimation_enabled"`
 	GasPriceEstimationBlocks     uint64    `toml:"gas_price_estimation_blocks"`
 	GasPriceEstimationTxPriority string    `toml:"gas_price_estimation_tx_priority"`
+	GasOracle                    string    `toml:"gas_oracle"`
+	AccessListsEnabled           bool      `toml:"access_lists_enabled"`
+	// ChainType selects how transaction fees are calculated: "ethereum" (default), "optimism" (OP stack) or "arbitrum"
+	ChainType string `toml:"chain_type"`
 
 	// derivative vars
 	ChainID string
@@ -121,7 +148,30 @@ func DefaultClient(rpcUrl string, privateKeys []string) (*Client, error) {
 
 // ReadConfig reads the TOML config file from location specified by env var "SETH_CONFIG_PATH" and returns a Config struct
 func ReadConfig() (*Config, error) {
-	cfgPath := os.Getenv(CONFIG_FILE_ENV_VAR)
+	cfg, err := ReadConfigFile(os.Getenv(CONFIG_FILE_ENV_VAR))
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.SelectNetwork(os.Getenv(NETWORK_ENV_VAR), os.Getenv(URL_ENV_VAR)); err != nil {
+		return nil, err
+	}
+
+	rootPrivateKey := os.Getenv(ROOT_PRIVATE_KEY_ENV_VAR)
+	if rootPrivateKey == "" {
+		return nil, errors.Errorf(ErrEmptyRootPrivateKey, ROOT_PRIVATE_KEY_ENV_VAR)
+	} else {
+		cfg.Network.PrivateKeys = append(cfg.Network.PrivateKeys, rootPrivateKey)
+	}
+	if cfg.Network.DialTimeout == nil {
+		cfg.Network.DialTimeout = &Duration{D: DefaultDialTimeout}
+	}
+	L.Trace().Interface("Config", cfg).Msg("Parsed seth config")
+	return cfg, nil
+}
+
+// ReadConfigFile reads the TOML config file without selecting the network or reading any keys. It's enough for things
+// that don't need a network connection, e.g. decoding with ABIs from abi_dir
+func ReadConfigFile(cfgPath string) (*Config, error) {
 	if cfgPath == "" {
 		return nil, errors.New(ErrEmptyConfigPath)
 	}
@@ -139,56 +189,50 @@ func ReadConfig() (*Config, error) {
 		return nil, err
 	}
 	cfg.ConfigDir = filepath.Dir(absPath)
-	snet := os.Getenv(NETWORK_ENV_VAR)
-	if snet != "" {
-		for _, n := range cfg.Networks {
-			if n.Name == snet {
-				cfg.Network = n
+	return cfg, nil
+}
+
+// SelectNetwork selects network with given name from TOML networks. If there's no such network, default network is
+// used with given URL (and name, if it's not empty)
+func (c *Config) SelectNetwork(name, url string) error {
+	if name != "" {
+		for _, n := range c.Networks {
+			if n.Name == name {
+				c.Network = n
 				break
 			}
 		}
 	}
 
-	if cfg.Network == nil {
-		L.Debug().Msgf("Network %s not found in TOML, trying to use URL", snet)
-		url := os.Getenv(URL_ENV_VAR)
+	if c.Network == nil {
+		L.Debug().Msgf("Network %s not found in TOML, trying to use URL", name)
 
 		if url == "" {
-			return nil, fmt.Errorf("network not selected, set %s=... or %s=..., check TOML config for available networks", NETWORK_ENV_VAR, URL_ENV_VAR)
+			return fmt.Errorf("network not selected, set %s=... or %s=..., check TOML config for available networks", NETWORK_ENV_VAR, URL_ENV_VAR)
 		}
 
 		//look for default network
-		for _, n := range cfg.Networks {
+		for _, n := range c.Networks {
 			if n.Name == DefaultNetworkName {
-				cfg.Network = n
-				cfg.Network.Name = snet
-				cfg.Network.URLs = []string{url}
+				c.Network = n
+				c.Network.Name = name
+				c.Network.URLs = []string{url}
 
-				if snet == "" {
+				if name == "" {
 					L.Warn().Msg("No network name provided, using default network")
-					cfg.Network.Name = DefaultNetworkName
+					c.Network.Name = DefaultNetworkName
 				}
 
 				break
 			}
 		}
 
-		if cfg.Network == nil {
-			return nil, fmt.Errorf("default network not defined in the TOML file")
+		if c.Network == nil {
+			return fmt.Errorf("default network not defined in the TOML file")
 		}
 	}
 
-	rootPrivateKey := os.Getenv(ROOT_PRIVATE_KEY_ENV_VAR)
-	if rootPrivateKey == "" {
-		return nil, errors.Errorf(ErrEmptyRootPrivateKey, ROOT_PRIVATE_KEY_ENV_VAR)
-	} else {
-		cfg.Network.PrivateKeys = append(cfg.Network.PrivateKeys, rootPrivateKey)
-	}
-	if cfg.Network.DialTimeout == nil {
-		cfg.Network.DialTimeout = &Duration{D: DefaultDialTimeout}
-	}
-	L.Trace().Interface("Config", cfg).Msg("Parsed seth config")
-	return cfg, nil
+	return nil
 }
 
 // FirstNetworkURL returns first network URL
@@ -230,9 +274,24 @@ func (c *Config) GenerateContractMapFileName() string {
 	return fmt.Sprintf(ContractMapFilePattern, networkName, now)
 }
 
-// ShouldSaveDeployedContractMap returns true if the contract map should be saved (i.e. not a simulated network and functionality is enabled)
+// GenerateSharedContractMapFileName generates a file name for the contract map shared by all clients using the same network
+func (c *Config) GenerateSharedContractMapFileName() string {
+	return fmt.Sprintf(SharedContractMapFilePattern, strings.ToLower(c.Network.Name))
+}
+
+// SharedContractMapFilePath returns the path of the shared contract map file. Relative paths are resolved against
+// the config directory, so that processes started in different working directories use the same file.
+func (c *Config) SharedContractMapFilePath() string {
+	if filepath.IsAbs(c.ContractMapFile) {
+		return c.ContractMapFile
+	}
+	return filepath.Join(c.ConfigDir, c.ContractMapFile)
+}
+
+// ShouldSaveDeployedContractMap returns true if the contract map should be saved (i.e. shared contract map is enabled
+// or it is not a simulated network and functionality is enabled)
 func (c *Config) ShouldSaveDeployedContractMap() bool {
-	return !c.IsSimulatedNetwork() && c.SaveDeployedContractsMap
+	return c.SharedContractMap || (!c.IsSimulatedNetwork() && c.SaveDeployedContractsMap)
 }
 
 func (c *Config) setEphemeralAddrs() {
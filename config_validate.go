@@ -0,0 +1,360 @@
+package seth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrUnexpectedChainID = "RPC answered with unexpected chain ID"
+
+	redactedValue = "***"
+)
+
+// ConfigProblem is a problem found in TOML config, pointing to the line it was found at
+type ConfigProblem struct {
+	File string `json:"file"`
+	// Line is 0 if the problem isn't related to any line in the file
+	Line    int    `json:"line,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+	// Warning is true for problems that don't prevent Seth from working, e.g. unknown keys, which are ignored
+	Warning bool `json:"warning,omitempty"`
+}
+
+// String returns the problem in "file:line: level: key: message" format
+func (p ConfigProblem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	level := "error"
+	if p.Warning {
+		level = "warning"
+	}
+	if p.Key == "" {
+		return fmt.Sprintf("%s: %s: %s", location, level, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", location, level, p.Key, p.Message)
+}
+
+// configValidator collects problems found in a single config file
+type configValidator struct {
+	file     string
+	lines    map[string]int
+	problems []ConfigProblem
+}
+
+func newConfigValidator(cfgPath string, data []byte) *configValidator {
+	return &configValidator{file: cfgPath, lines: tomlKeyLines(data)}
+}
+
+// lineOf returns line where given key is defined. If it isn't defined, line of the closest parent table is returned
+func (v *configValidator) lineOf(key string) int {
+	key = strings.ToLower(key)
+	for key != "" {
+		if line, ok := v.lines[key]; ok {
+			return line
+		}
+		idx := strings.LastIndex(key, ".")
+		if idx == -1 {
+			break
+		}
+		key = key[:idx]
+	}
+	return 0
+}
+
+func (v *configValidator) add(key, message string, warning bool) {
+	v.problems = append(v.problems, ConfigProblem{File: v.file, Line: v.lineOf(key), Key: key, Message: message, Warning: warning})
+}
+
+func (v *configValidator) check(key string, err error) {
+	if err != nil {
+		v.add(key, err.Error(), false)
+	}
+}
+
+// ValidateConfigFile checks TOML config file: syntax, unknown keys, settings of all networks and paths to ABIs, bytecodes
+// and the contract map. Unlike ValidateConfig it doesn't stop at the first problem and points to the line each problem
+// was found at. Error is returned only if the file can't be read.
+func ValidateConfigFile(cfgPath string) ([]ConfigProblem, error) {
+	if cfgPath == "" {
+		return nil, errors.New(ErrEmptyConfigPath)
+	}
+	d, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrReadSethConfig)
+	}
+	v := newConfigValidator(cfgPath, d)
+
+	var cfg *Config
+	if err := toml.NewDecoder(bytes.NewReader(d)).DisallowUnknownFields().Decode(&cfg); err != nil {
+		var strictErr *toml.StrictMissingError
+		var decodeErr *toml.DecodeError
+		switch {
+		case errors.As(err, &strictErr):
+			// the rest of the config was decoded, so it can still be checked
+			for _, e := range strictErr.Errors {
+				row, _ := e.Position()
+				v.problems = append(v.problems, ConfigProblem{File: cfgPath, Line: row, Key: strings.Join(e.Key(), "."), Message: "unknown key, it will be ignored", Warning: true})
+			}
+		case errors.As(err, &decodeErr):
+			row, _ := decodeErr.Position()
+			v.problems = append(v.problems, ConfigProblem{File: cfgPath, Line: row, Key: strings.Join(decodeErr.Key(), "."), Message: decodeErr.Error()})
+			return v.problems, nil
+		default:
+			v.problems = append(v.problems, ConfigProblem{File: cfgPath, Message: err.Error()})
+			return v.problems, nil
+		}
+	}
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	absPath, err := filepath.Abs(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConfigDir = filepath.Dir(absPath)
+
+	for _, c := range configValidators {
+		v.check(c.key, c.validate(cfg))
+	}
+	v.checkPaths(cfg)
+
+	if cfg.Network != nil {
+		v.checkNetwork("network", cfg.Network)
+	}
+	names := make(map[string]struct{})
+	for i, n := range cfg.Networks {
+		key := fmt.Sprintf("networks[%d]", i)
+		v.checkNetwork(key, n)
+		if _, ok := names[n.Name]; ok && n.Name != "" {
+			v.add(key+".name", fmt.Sprintf("network %s is defined more than once, only the first one can be selected", n.Name), false)
+		}
+		names[n.Name] = struct{}{}
+	}
+	if cfg.Network == nil && len(cfg.Networks) == 0 {
+		v.add("networks", "no networks defined", false)
+	}
+
+	return v.problems, nil
+}
+
+// checkPaths checks that ABI and bytecode directories and the contract map file exist
+func (v *configValidator) checkPaths(cfg *Config) {
+	for _, d := range []struct{ key, dir string }{{"abi_dir", cfg.ABIDir}, {"bin_dir", cfg.BINDir}} {
+		key, dir := d.key, d.dir
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(cfg.ConfigDir, dir)
+		if info, err := os.Stat(path); err != nil {
+			v.add(key, fmt.Sprintf("directory %s doesn't exist", path), false)
+		} else if !info.IsDir() {
+			v.add(key, fmt.Sprintf("%s is not a directory", path), false)
+		}
+	}
+
+	if cfg.ContractMapFile == "" {
+		return
+	}
+	path := cfg.ContractMapFile
+	if cfg.SharedContractMap {
+		path = cfg.SharedContractMapFilePath()
+	}
+	if _, err := os.Stat(path); err != nil {
+		// it's created when the first contract is deployed
+		v.add("contract_map_file", fmt.Sprintf("file %s doesn't exist (relative paths are resolved against the working directory, unless shared contract map is enabled)", path), true)
+		return
+	}
+	if _, err := LoadDeployedContracts(path); err != nil {
+		v.add("contract_map_file", errors.Wrap(err, ErrReadContractMap).Error(), false)
+	}
+}
+
+// checkNetwork checks settings of a single network. Network isn't modified
+func (v *configValidator) checkNetwork(key string, network *Network) {
+	n := *network
+	if n.Name == "" {
+		v.add(key+".name", "network has no name", false)
+	}
+	if len(n.URLs) == 0 && n.Name != DefaultNetworkName {
+		v.add(key+".urls_secret", fmt.Sprintf("network %s has no RPC URLs, they have to be set before creating a client", n.Name), true)
+	}
+	for i, k := range n.PrivateKeys {
+		if _, err := crypto.HexToECDSA(k); err != nil {
+			v.add(key+".private_keys_secret", fmt.Sprintf("private key #%d of network %s is invalid: %s", i, n.Name, err), false)
+		}
+	}
+	if n.TxnTimeout == nil {
+		v.add(key+".transaction_timeout", fmt.Sprintf("network %s has no transaction timeout", n.Name), false)
+	}
+	if n.GasLimit != 0 {
+		v.add(key+".gas_limit", WarnGasLimitSet, true)
+	}
+	for _, c := range networkValidators {
+		v.check(key+"."+c.key, c.validate(&n))
+	}
+}
+
+// tomlKeyLines maps dotted paths of all keys and tables in TOML document to lines they are defined at. Array tables
+// are indexed, e.g. networks[1].name. Paths are lower case, since Seth matches keys case-insensitively.
+func tomlKeyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	arrayTables := make(map[string]int)
+	var table string
+
+	var p unstable.Parser
+	p.Reset(data)
+	for p.NextExpression() {
+		expr := p.Expression()
+		var parts []string
+		line := 0
+		it := expr.Key()
+		for it.Next() {
+			n := it.Node()
+			parts = append(parts, strings.ToLower(string(n.Data)))
+			if line == 0 && n.Raw.Length > 0 {
+				line = p.Shape(n.Raw).Start.Line
+			}
+		}
+		key := strings.Join(parts, ".")
+
+		switch expr.Kind {
+		case unstable.KeyValue:
+			if table != "" {
+				key = table + "." + key
+			}
+			lines[key] = line
+		case unstable.Table:
+			table = key
+			lines[table] = line
+		case unstable.ArrayTable:
+			table = fmt.Sprintf("%s[%d]", key, arrayTables[key])
+			arrayTables[key]++
+			lines[table] = line
+		}
+	}
+
+	return lines
+}
+
+// CheckConfigRPC selects network the same way as ReadConfig does, dials its first RPC URL and checks that it answers
+// with expected chain ID (0 means any). It returns the chain ID RPC answered with. Error is returned only if the file
+// can't be read.
+func CheckConfigRPC(ctx context.Context, cfgPath, networkName, rpcURL string, expectedChainID int64) (*big.Int, []ConfigProblem, error) {
+	cfg, err := ReadConfigFile(cfgPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrReadSethConfig)
+	}
+	v := newConfigValidator(cfgPath, d)
+
+	if err := cfg.SelectNetwork(networkName, rpcURL); err != nil {
+		v.add("networks", err.Error(), false)
+		return nil, v.problems, nil
+	}
+	key := "network"
+	for i, n := range cfg.Networks {
+		if n == cfg.Network {
+			key = fmt.Sprintf("networks[%d]", i)
+			break
+		}
+	}
+	key += ".urls_secret"
+	if len(cfg.Network.URLs) == 0 {
+		v.add(key, fmt.Sprintf("network %s has no RPC URLs", cfg.Network.Name), false)
+		return nil, v.problems, nil
+	}
+
+	rpcClient, err := rpc.DialOptions(ctx, cfg.FirstNetworkURL(), rpc.WithHeaders(cfg.RPCHeaders))
+	if err != nil {
+		v.add(key, fmt.Sprintf("failed to connect to RPC of network %s: %s", cfg.Network.Name, redactError(err, cfg.Network.URLs)), false)
+		return nil, v.problems, nil
+	}
+	client := ethclient.NewClient(rpcClient)
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		v.add(key, fmt.Sprintf("failed to get chain ID from RPC of network %s: %s", cfg.Network.Name, redactError(err, cfg.Network.URLs)), false)
+		return nil, v.problems, nil
+	}
+	if expectedChainID != 0 && chainID.Int64() != expectedChainID {
+		v.add(key, fmt.Sprintf("%s for network %s: %s, expected %d", ErrUnexpectedChainID, cfg.Network.Name, chainID, expectedChainID), false)
+	}
+
+	return chainID, v.problems, nil
+}
+
+// Redacted returns a copy of the config with only the selected network, in which RPC URLs, private keys and RPC headers
+// are redacted, so that it can be printed or logged. Private keys are replaced with their addresses.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Networks = nil
+
+	if c.Network != nil {
+		network := *c.Network
+		network.URLs = make([]string, 0, len(c.Network.URLs))
+		for _, u := range c.Network.URLs {
+			network.URLs = append(network.URLs, redactURL(u))
+		}
+		network.PrivateKeys = make([]string, 0, len(c.Network.PrivateKeys))
+		for _, k := range c.Network.PrivateKeys {
+			pk, err := crypto.HexToECDSA(k)
+			if err != nil {
+				network.PrivateKeys = append(network.PrivateKeys, redactedValue+" (invalid key)")
+				continue
+			}
+			network.PrivateKeys = append(network.PrivateKeys, fmt.Sprintf("%s (%s)", redactedValue, crypto.PubkeyToAddress(pk.PublicKey).Hex()))
+		}
+		redacted.Network = &network
+	}
+
+	if c.RPCHeaders != nil {
+		redacted.RPCHeaders = make(map[string][]string, len(c.RPCHeaders))
+		for name := range c.RPCHeaders {
+			redacted.RPCHeaders[name] = []string{redactedValue}
+		}
+	}
+
+	return &redacted
+}
+
+// redactURL keeps only scheme and host of the URL, since API keys are usually passed in path, query or as user info
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return redactedValue
+	}
+	if u.User == nil && (u.Path == "" || u.Path == "/") && u.RawQuery == "" {
+		return fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	}
+	return fmt.Sprintf("%s://%s/%s", u.Scheme, u.Host, redactedValue)
+}
+
+// redactError replaces all URLs in error message with their redacted versions
+func redactError(err error, urls []string) string {
+	msg := err.Error()
+	for _, u := range urls {
+		msg = strings.ReplaceAll(msg, u, redactURL(u))
+	}
+	return msg
+}
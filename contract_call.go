@@ -0,0 +1,140 @@
+package seth
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrUnknownContract = "contract not found in contract map, pass its address together with ABI name"
+	ErrMethodNotFound  = "method not found in ABI"
+	ErrAmbiguousMethod = "method is overloaded, use its signature instead, e.g. transfer(address,uint256)"
+)
+
+// ContractMethodCall is a contract method together with arguments parsed from strings, ready to be called or sent as a transaction
+type ContractMethodCall struct {
+	ContractName string
+	Address      common.Address
+	ABI          *abi.ABI
+	Method       *abi.Method
+	Args         []interface{}
+}
+
+// NewContractMethodCall resolves contract passed either as name from the contract map or as an address, finds its ABI in the Contract Store
+// (abiName is needed only if address isn't in the contract map), finds method by name or signature and parses its arguments (see ParseABIArguments)
+func (m *Client) NewContractMethodCall(contract, abiName, method string, args []string) (*ContractMethodCall, error) {
+	if m.ContractStore == nil {
+		return nil, errors.New("ABIStore is nil")
+	}
+
+	var address common.Address
+	name := strings.TrimSuffix(abiName, ".abi")
+	if common.IsHexAddress(contract) {
+		address = common.HexToAddress(contract)
+		if name == "" {
+			name = m.ContractAddressToNameMap.GetContractName(address.Hex())
+		}
+	} else {
+		mapped := m.ContractAddressToNameMap.GetContractAddress(contract)
+		if mapped == UNKNOWN {
+			return nil, errors.Errorf("%s: %s", ErrUnknownContract, contract)
+		}
+		address = common.HexToAddress(mapped)
+		if name == "" {
+			name = contract
+		}
+	}
+	if name == "" {
+		return nil, errors.Errorf("%s: %s", ErrUnknownContract, contract)
+	}
+
+	contractABI, ok := m.ContractStore.GetABI(name)
+	if !ok {
+		return nil, errors.Errorf("ABI %s not found", name)
+	}
+
+	abiMethod, err := FindABIMethod(contractABI, method)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedArgs, err := ParseABIArguments(abiMethod.Inputs, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s arguments", abiMethod.Sig)
+	}
+
+	// so that transaction and its traces can be decoded
+	if !m.ContractAddressToNameMap.IsKnownAddress(address.Hex()) {
+		m.ContractAddressToNameMap.AddContract(address.Hex(), name)
+	}
+
+	return &ContractMethodCall{
+		ContractName: name,
+		Address:      address,
+		ABI:          contractABI,
+		Method:       abiMethod,
+		Args:         parsedArgs,
+	}, nil
+}
+
+// FindABIMethod finds method by its name or signature, e.g. "transfer" or "transfer(address,uint256)". Overloaded methods can be found only by signature.
+func FindABIMethod(contractABI *abi.ABI, method string) (*abi.Method, error) {
+	method = strings.ReplaceAll(method, " ", "")
+
+	var byRawName []string
+	for name, abiMethod := range contractABI.Methods {
+		if abiMethod.Sig == method {
+			found := contractABI.Methods[name]
+			return &found, nil
+		}
+		if abiMethod.RawName == method {
+			byRawName = append(byRawName, name)
+		}
+	}
+
+	switch len(byRawName) {
+	case 0:
+		return nil, errors.Errorf("%s: %s", ErrMethodNotFound, method)
+	case 1:
+		found := contractABI.Methods[byRawName[0]]
+		return &found, nil
+	default:
+		var signatures []string
+		for _, name := range byRawName {
+			signatures = append(signatures, contractABI.Methods[name].Sig)
+		}
+		sort.Strings(signatures)
+		return nil, errors.Errorf("%s. Candidates: %s", ErrAmbiguousMethod, strings.Join(signatures, ", "))
+	}
+}
+
+// CallContractMethod runs a read-only call and returns decoded outputs. If the call reverts, error contains decoded revert reason.
+func (m *Client) CallContractMethod(ctx context.Context, call *ContractMethodCall, opts *bind.CallOpts) (map[string]interface{}, error) {
+	data, err := call.ABI.Pack(call.Method.Name, call.Args...)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := m.Client.CallContract(ctx, ethereum.CallMsg{From: opts.From, To: &call.Address, Data: data}, opts.BlockNumber)
+	if err != nil {
+		if reason, decodingErr := m.DecodeCustomABIErr(err); decodingErr == nil && reason != "" {
+			err = errors.Wrap(err, reason)
+		}
+		return nil, err
+	}
+
+	return decodeTxOutputs(L, output, call.Method)
+}
+
+// SendContractMethod sends a transaction calling the method and decodes it, the same way as Decode does
+func (m *Client) SendContractMethod(call *ContractMethodCall, opts *bind.TransactOpts) (*DecodedTransaction, error) {
+	contract := bind.NewBoundContract(call.Address, *call.ABI, m.Client, m.Client, m.Client)
+	return m.Decode(contract.Transact(opts, call.Method.Name, call.Args...))
+}
@@ -0,0 +1,118 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const contractCallTokenABI = `[
+	{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+var contractCallTokenAddress = common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+
+// withContractCallToken deploys the token, which returns 1_000_000 for any call, and adds its ABI to the contract store.
+// Token is not added to the contract map.
+func withContractCallToken() fakeNodeClientOption {
+	return fakeNodeClientOption{
+		node: func(n *fakeNode) {
+			n.callResults[contractCallTokenAddress] = abiWords(1_000_000)
+			n.code[contractCallTokenAddress] = hexutil.Bytes{0x01}
+		},
+		client: withABI("Token", contractCallTokenABI).client,
+	}
+}
+
+func TestContractCallResolvesContractByNameAndAddress(t *testing.T) {
+	c := newFakeNodeClient(t, newFakeNode(t), withContractCallToken())
+
+	_, err := c.NewContractMethodCall("Token", "", "balanceOf", []string{c.Addresses[0].Hex()})
+	require.ErrorContains(t, err, seth.ErrUnknownContract, "contract missing in contract map should be rejected")
+
+	_, err = c.NewContractMethodCall(contractCallTokenAddress.Hex(), "", "balanceOf", []string{c.Addresses[0].Hex()})
+	require.ErrorContains(t, err, seth.ErrUnknownContract, "address without ABI should be rejected")
+
+	call, err := c.NewContractMethodCall(contractCallTokenAddress.Hex(), "Token", "balanceOf", []string{c.Addresses[0].Hex()})
+	require.NoError(t, err, "failed to resolve contract by address and ABI name")
+	require.Equal(t, "balanceOf(address)", call.Method.Sig, "incorrect method")
+	require.Equal(t, "Token", c.ContractAddressToNameMap.GetContractName(contractCallTokenAddress.Hex()), "address should be added to contract map")
+
+	call, err = c.NewContractMethodCall("Token", "", "balanceOf", []string{c.Addresses[0].Hex()})
+	require.NoError(t, err, "failed to resolve contract by name")
+	require.Equal(t, contractCallTokenAddress, call.Address, "address should come from contract map")
+
+	outputs, err := c.CallContractMethod(context.Background(), call, c.NewCallOpts())
+	require.NoError(t, err, "failed to call contract")
+	require.Equal(t, big.NewInt(1_000_000), outputs["0"], "output should be decoded")
+}
+
+func TestContractCallFindsOverloadedMethodsBySignature(t *testing.T) {
+	c := newFakeNodeClient(t, newFakeNode(t), withContractCallToken(), withContract(contractCallTokenAddress, "Token"))
+
+	_, err := c.NewContractMethodCall("Token", "", "transfer", []string{c.Addresses[0].Hex(), "1"})
+	require.ErrorContains(t, err, seth.ErrAmbiguousMethod, "overloaded method should be found only by signature")
+
+	call, err := c.NewContractMethodCall("Token", "", "transfer(address, uint256)", []string{c.Addresses[0].Hex(), "1"})
+	require.NoError(t, err, "failed to find method by signature")
+	require.Equal(t, []interface{}{c.Addresses[0], big.NewInt(1)}, call.Args, "arguments should be parsed")
+
+	_, err = c.NewContractMethodCall("Token", "", "approve", nil)
+	require.ErrorContains(t, err, seth.ErrMethodNotFound, "unknown method should be rejected")
+
+	_, err = c.NewContractMethodCall("Token", "", "balanceOf", nil)
+	require.ErrorContains(t, err, seth.ErrArgumentCount, "missing arguments should be rejected")
+}
+
+func TestContractCallSendsMethodAndDecodesEvents(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withContractCallToken(), withContract(contractCallTokenAddress, "Token"))
+	recipient := common.HexToAddress("0x02")
+
+	call, err := c.NewContractMethodCall("Token", "", "transfer(address,uint256)", []string{recipient.Hex(), "10"})
+	require.NoError(t, err, "failed to resolve method")
+
+	type result struct {
+		decoded *seth.DecodedTransaction
+		err     error
+	}
+	sent := make(chan result, 1)
+	go func() {
+		decoded, err := c.SendContractMethod(call, c.NewTXOpts())
+		sent <- result{decoded, err}
+	}()
+
+	require.Eventually(t, func() bool { return len(node.sentTxs()) == 1 }, 5*time.Second, 10*time.Millisecond, "transaction should be sent")
+	tx := node.sentTxs()[0]
+	require.Equal(t, contractCallTokenAddress, *tx.To(), "transaction should call the token")
+	node.mine(t, tx, types.ReceiptStatusSuccessful, &types.Log{
+		Address: contractCallTokenAddress,
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+			common.BytesToHash(c.Addresses[0].Bytes()),
+			common.BytesToHash(recipient.Bytes()),
+		},
+		Data: common.BigToHash(big.NewInt(10)).Bytes(),
+	})
+
+	r := <-sent
+	require.NoError(t, r.err, "failed to send transaction")
+	require.Equal(t, tx.Hash().Hex(), r.decoded.Hash, "incorrect transaction")
+	require.Equal(t, "transfer(address,uint256)", r.decoded.Method, "incorrect method")
+	require.Equal(t, map[string]interface{}{"to": recipient, "amount": big.NewInt(10)}, r.decoded.Input, "inputs should be decoded")
+	require.Len(t, r.decoded.Events, 1, "event should be decoded")
+	require.Equal(t, "Transfer(address,address,uint256)", r.decoded.Events[0].Signature, "incorrect event")
+	require.Equal(t, big.NewInt(10), r.decoded.Events[0].EventData["value"], "incorrect event value")
+}
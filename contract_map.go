@@ -13,6 +13,7 @@ import (
 type ContractMap struct {
 	mu         *sync.RWMutex
 	addressMap map[string]string
+	store      *ContractMapStore
 }
 
 func NewEmptyContractMap() ContractMap {
@@ -29,17 +30,78 @@ func NewContractMap(contracts map[string]string) ContractMap {
 	}
 }
 
+// NewSharedContractMap creates a contract map backed by a shared store. Contracts saved in the store by other
+// processes are merged into the map whenever we look up an address or name we don't know yet.
+func NewSharedContractMap(store *ContractMapStore) (ContractMap, error) {
+	contracts, err := store.Load()
+	if err != nil {
+		return ContractMap{}, err
+	}
+
+	return ContractMap{
+		mu:         &sync.RWMutex{},
+		addressMap: contracts,
+		store:      store,
+	}, nil
+}
+
+// IsShared returns true if the contract map is backed by a shared store
+func (c ContractMap) IsShared() bool {
+	return c.store != nil
+}
+
+// SaveContract adds contract to the map and, if the map is shared, persists it in the shared store
+func (c ContractMap) SaveContract(addr, name string) error {
+	c.AddContract(addr, name)
+	if c.store == nil {
+		return nil
+	}
+
+	return c.store.SaveContract(name, addr)
+}
+
+// refresh merges contracts saved in the shared store by other processes, if the store has changed since last read
+func (c ContractMap) refresh() {
+	if c.store == nil || !c.store.HasChanged() {
+		return
+	}
+
+	contracts, err := c.store.Load()
+	if err != nil {
+		L.Warn().
+			Err(err).
+			Str("File", c.store.FileName()).
+			Msg("Failed to refresh shared contract map")
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for addr, name := range contracts {
+		if _, ok := c.addressMap[addr]; !ok {
+			c.addressMap[addr] = name
+		}
+	}
+}
+
 func (c ContractMap) GetContractMap() map[string]string {
 	return c.addressMap
 }
 
 func (c ContractMap) IsKnownAddress(addr string) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	return c.addressMap[strings.ToLower(addr)] != ""
+	return c.GetContractName(addr) != ""
 }
 
 func (c ContractMap) GetContractName(addr string) string {
+	if name := c.getContractName(addr); name != "" || c.store == nil {
+		return name
+	}
+
+	c.refresh()
+	return c.getContractName(addr)
+}
+
+func (c ContractMap) getContractName(addr string) string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	return c.addressMap[strings.ToLower(addr)]
@@ -50,6 +112,15 @@ func (c ContractMap) GetContractAddress(addr string) string {
 		return UNKNOWN
 	}
 
+	if address := c.getContractAddress(addr); address != UNKNOWN || c.store == nil {
+		return address
+	}
+
+	c.refresh()
+	return c.getContractAddress(addr)
+}
+
+func (c ContractMap) getContractAddress(addr string) string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for k, v := range c.addressMap {
@@ -0,0 +1,261 @@
+package seth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrAcquireContractMapLock = "failed to acquire contract map lock"
+	ErrWriteContractMap       = "failed to write contract map"
+
+	SharedContractMapFilePattern = "deployed_contracts_%s.toml"
+
+	contractMapLockSuffix        = ".lock"
+	contractMapLockRetryInterval = 10 * time.Millisecond
+)
+
+var (
+	// ContractMapLockTimeout is the maximum time we wait for the lock of a shared contract map
+	ContractMapLockTimeout = 30 * time.Second
+	// ContractMapStaleLockAge is the age after which a lock file is considered to be left over by a crashed process and removed
+	ContractMapStaleLockAge = 2 * time.Minute
+)
+
+// ContractMapStore is a contract map persisted in a single TOML file, which can be safely shared by many processes
+// (e.g. test binaries started by `go test ./...`). Writes are serialised with a lock file and always merge with the
+// current content of the file, which is replaced atomically, so readers never need to take the lock.
+type ContractMapStore struct {
+	filename string
+	mu       *sync.Mutex
+	modTime  time.Time
+	size     int64
+}
+
+// NewContractMapStore creates a new store backed by given file. File doesn't need to exist.
+func NewContractMapStore(filename string) *ContractMapStore {
+	return &ContractMapStore{
+		filename: filename,
+		mu:       &sync.Mutex{},
+	}
+}
+
+// FileName returns the name of the file backing the store
+func (s *ContractMapStore) FileName() string {
+	return s.filename
+}
+
+// Load reads all contracts from the file. Addresses are returned in lower case.
+func (s *ContractMapStore) Load() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load()
+}
+
+// HasChanged returns true if the file was modified since we last read or wrote it
+func (s *ContractMapStore) HasChanged() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		return false
+	}
+
+	return !info.ModTime().Equal(s.modTime) || info.Size() != s.size
+}
+
+// SaveContract adds contract to the file, keeping all entries saved by other processes
+func (s *ContractMapStore) SaveContract(name, address string) error {
+	return s.Save(map[string]string{address: name})
+}
+
+// Save merges contracts with the ones saved in the file and writes the result back. It holds the file lock for
+// the whole read-modify-write cycle.
+func (s *ContractMapStore) Save(contracts map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for address, name := range contracts {
+		if !common.IsHexAddress(address) {
+			return fmt.Errorf("invalid contract address: %s", address)
+		}
+		current[strings.ToLower(address)] = strings.TrimSuffix(name, ".abi")
+	}
+
+	toSave := make(map[string]string, len(current))
+	for address, name := range current {
+		toSave[common.HexToAddress(address).Hex()] = name
+	}
+
+	marshalled, err := toml.Marshal(toSave)
+	if err != nil {
+		return errors.Wrap(err, ErrWriteContractMap)
+	}
+
+	if err := s.replaceFile(marshalled); err != nil {
+		return errors.Wrap(err, ErrWriteContractMap)
+	}
+
+	return nil
+}
+
+func (s *ContractMapStore) load() (map[string]string, error) {
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, errors.Wrap(err, ErrReadContractMap)
+	}
+
+	loaded, err := LoadDeployedContracts(s.filename)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrReadContractMap)
+	}
+
+	contracts := make(map[string]string, len(loaded))
+	for address, name := range loaded {
+		contracts[strings.ToLower(address)] = name
+	}
+
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	return contracts, nil
+}
+
+// replaceFile writes content to a temporary file in the same directory and renames it, so that readers never see
+// a partially written file
+func (s *ContractMapStore) replaceFile(content []byte) error {
+	dir := filepath.Dir(s.filename)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.filename); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(s.filename)
+	if err != nil {
+		return err
+	}
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	return nil
+}
+
+// lock creates the lock file exclusively, waiting for other processes to release it. Lock files older than
+// ContractMapStaleLockAge are considered abandoned and taken over.
+func (s *ContractMapStore) lock() (func(), error) {
+	lockFile := s.filename + contractMapLockSuffix
+	if err := os.MkdirAll(filepath.Dir(lockFile), os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, ErrAcquireContractMapLock)
+	}
+
+	token := fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	deadline := time.Now().Add(ContractMapLockTimeout)
+	for {
+		f, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, _ = f.WriteString(token)
+			_ = f.Close()
+			return func() { releaseContractMapLock(lockFile, token) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, errors.Wrap(err, ErrAcquireContractMapLock)
+		}
+
+		if staleToken, stale := readStaleContractMapLock(lockFile); stale {
+			removeStaleContractMapLock(lockFile, staleToken)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("%s %s: timed out after %s", ErrAcquireContractMapLock, lockFile, ContractMapLockTimeout)
+		}
+		time.Sleep(contractMapLockRetryInterval)
+	}
+}
+
+// readStaleContractMapLock returns the token of the lock file if it is older than ContractMapStaleLockAge
+func readStaleContractMapLock(lockFile string) (string, bool) {
+	content, err := os.ReadFile(lockFile)
+	if err != nil {
+		return "", false
+	}
+	info, err := os.Stat(lockFile)
+	if err != nil || time.Since(info.ModTime()) <= ContractMapStaleLockAge {
+		return "", false
+	}
+	return string(content), true
+}
+
+// removeStaleContractMapLock removes the stale lock file only if it still holds the token it had when it was found
+// to be stale and is still stale. If another process took it over in the meantime, the new lock has a different
+// token and fresh modification time and is left alone.
+func removeStaleContractMapLock(lockFile, staleToken string) {
+	token, stale := readStaleContractMapLock(lockFile)
+	if !stale || token != staleToken {
+		return
+	}
+	if err := os.Remove(lockFile); err != nil {
+		// somebody else removed it first
+		return
+	}
+
+	L.Warn().
+		Str("File", lockFile).
+		Msg("Removed stale contract map lock")
+}
+
+// releaseContractMapLock removes the lock file only if it is still owned by us, i.e. it wasn't taken over as stale
+func releaseContractMapLock(lockFile, token string) {
+	content, err := os.ReadFile(lockFile)
+	if err != nil || string(content) != token {
+		return
+	}
+	_ = os.Remove(lockFile)
+}
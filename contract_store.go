@@ -1,8 +1,12 @@
 package seth
 
 import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -22,10 +26,29 @@ type ContractStore struct {
 	ABIs ABIStore
 	BINs map[string][]byte
 	mu   *sync.RWMutex
+	// errorIndex maps custom error selectors to all distinct error definitions found in all ABIs
+	errorIndex map[[4]byte][]ABIErrorDefinition
+	// eventIndex maps event topics (topic0) to all distinct event definitions found in all ABIs
+	eventIndex map[common.Hash][]ABIEventDefinition
+	// indexedFingerprint is the fingerprint of ABIs, from which indexes were built. ABIs can be changed directly in the
+	// public map, so indexes are rebuilt whenever the fingerprint changes
+	indexedFingerprint uint64
 }
 
 type ABIStore map[string]abi.ABI
 
+// ABIErrorDefinition is a custom error definition together with names of all ABIs it was found in
+type ABIErrorDefinition struct {
+	Error    abi.Error
+	ABINames []string
+}
+
+// ABIEventDefinition is an event definition together with names of all ABIs it was found in
+type ABIEventDefinition struct {
+	Event    abi.Event
+	ABINames []string
+}
+
 func (c *ContractStore) GetABI(name string) (*abi.ABI, bool) {
 	if !strings.HasSuffix(name, ".abi") {
 		name = name + ".abi"
@@ -49,6 +72,199 @@ func (c *ContractStore) AddABI(name string, abi abi.ABI) {
 	c.ABIs[name] = abi
 }
 
+// FindErrorsBySelector returns all distinct custom error definitions, from all known ABIs, matching given selector.
+// Same error (with the same signature) defined in multiple ABIs (e.g. when it comes from a library) is returned only once.
+func (c *ContractStore) FindErrorsBySelector(selector []byte) []ABIErrorDefinition {
+	if len(selector) < 4 {
+		return nil
+	}
+
+	var key [4]byte
+	copy(key[:], selector[:4])
+
+	c.mu.RLock()
+	if c.indexesUpToDate() {
+		defer c.mu.RUnlock()
+		return c.errorIndex[key]
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshIndexes()
+
+	return c.errorIndex[key]
+}
+
+// FindEventsByTopic returns all distinct event definitions, from all known ABIs, matching given topic (topic0).
+// Events with the same signature, but different indexed arguments (e.g. ERC-20 and ERC-721 Transfer) are returned separately.
+func (c *ContractStore) FindEventsByTopic(topic common.Hash) []ABIEventDefinition {
+	c.mu.RLock()
+	if c.indexesUpToDate() {
+		defer c.mu.RUnlock()
+		return c.eventIndex[topic]
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshIndexes()
+
+	return c.eventIndex[topic]
+}
+
+// refreshIndexes rebuilds indexes if ABIs changed since they were last built. Must be called with the write lock held.
+func (c *ContractStore) refreshIndexes() {
+	if !c.indexesUpToDate() {
+		c.buildIndexes()
+	}
+}
+
+// indexesUpToDate returns true if indexes were built from current ABIs. Must be called with the lock held.
+func (c *ContractStore) indexesUpToDate() bool {
+	return c.errorIndex != nil && c.indexedFingerprint == c.abisFingerprint()
+}
+
+// abisFingerprint returns an order-independent fingerprint of ABI names and IDs of their errors and events, which is much cheaper
+// to calculate than the indexes. Must be called with the lock held.
+func (c *ContractStore) abisFingerprint() uint64 {
+	fingerprint := uint64(len(c.ABIs))
+	for name, contractABI := range c.ABIs {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(name))
+		nameHash := h.Sum64()
+		fingerprint += nameHash
+		for _, abiError := range contractABI.Errors {
+			fingerprint += nameHash ^ binary.BigEndian.Uint64(abiError.ID[:8])
+		}
+		for _, event := range contractABI.Events {
+			indexed := uint64(0)
+			for _, input := range event.Inputs {
+				indexed = indexed<<1 | boolToUint64(input.Indexed)
+			}
+			fingerprint += nameHash ^ (binary.BigEndian.Uint64(event.ID[:8]) + indexed)
+		}
+	}
+	return fingerprint
+}
+
+func boolToUint64(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// DecodeCustomError decodes ABI-encoded custom error using all known ABIs. If selector matches more than one
+// distinct error definition, all successfully decoded candidates are returned and the ambiguity is reported.
+// It returns an empty string if no matching error definition was found.
+func (c *ContractStore) DecodeCustomError(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", nil
+	}
+
+	definitions := c.FindErrorsBySelector(data[:4])
+	if len(definitions) == 0 {
+		return "", nil
+	}
+
+	var decoded []string
+	var unpackErr error
+	for _, definition := range definitions {
+		v, err := definition.Error.Unpack(data)
+		if err != nil {
+			unpackErr = err
+			continue
+		}
+		L.Trace().Interface("Error", definition.Error.Name).Interface("Args", v).Msg("Revert Reason")
+		decoded = append(decoded, fmt.Sprintf("error type: %s, error values: %v", definition.Error.Name, v))
+	}
+
+	switch len(decoded) {
+	case 0:
+		return "", unpackErr
+	case 1:
+		return decoded[0], nil
+	default:
+		candidates := make([]string, 0, len(definitions))
+		for _, definition := range definitions {
+			candidates = append(candidates, fmt.Sprintf("%s in %s", definition.Error.Sig, strings.Join(definition.ABINames, ", ")))
+		}
+		L.Warn().
+			Str("Selector", fmt.Sprintf("0x%x", data[:4])).
+			Strs("Candidates", candidates).
+			Msg("Ambiguous custom error selector. It matches multiple errors with different signatures")
+
+		return fmt.Sprintf("ambiguous error selector 0x%x, possible errors: %s", data[:4], strings.Join(decoded, "; ")), nil
+	}
+}
+
+// buildIndexes (re)builds custom error and event indexes from all ABIs. Must be called with the lock held.
+func (c *ContractStore) buildIndexes() {
+	names := make([]string, 0, len(c.ABIs))
+	for name := range c.ABIs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errorIndex := make(map[[4]byte][]ABIErrorDefinition)
+	eventIndex := make(map[common.Hash][]ABIEventDefinition)
+	for _, name := range names {
+		for _, abiError := range c.ABIs[name].Errors {
+			var key [4]byte
+			copy(key[:], abiError.ID.Bytes()[:4])
+
+			found := false
+			for i, existing := range errorIndex[key] {
+				if existing.Error.Sig == abiError.Sig {
+					errorIndex[key][i].ABINames = append(errorIndex[key][i].ABINames, name)
+					found = true
+					break
+				}
+			}
+			if !found {
+				errorIndex[key] = append(errorIndex[key], ABIErrorDefinition{Error: abiError, ABINames: []string{name}})
+			}
+		}
+
+		for _, event := range c.ABIs[name].Events {
+			// anonymous events have no topic0, so they can't be indexed
+			if event.Anonymous {
+				continue
+			}
+
+			found := false
+			for i, existing := range eventIndex[event.ID] {
+				if existing.Event.Sig == event.Sig && indexedArgumentsPattern(existing.Event) == indexedArgumentsPattern(event) {
+					eventIndex[event.ID][i].ABINames = append(eventIndex[event.ID][i].ABINames, name)
+					found = true
+					break
+				}
+			}
+			if !found {
+				eventIndex[event.ID] = append(eventIndex[event.ID], ABIEventDefinition{Event: event, ABINames: []string{name}})
+			}
+		}
+	}
+
+	c.errorIndex = errorIndex
+	c.eventIndex = eventIndex
+	c.indexedFingerprint = c.abisFingerprint()
+}
+
+// indexedArgumentsPattern returns a string describing which event arguments are indexed, e.g. "110"
+func indexedArgumentsPattern(event abi.Event) string {
+	var sb strings.Builder
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			sb.WriteString("1")
+		} else {
+			sb.WriteString("0")
+		}
+	}
+	return sb.String()
+}
+
 func (c *ContractStore) GetBIN(name string) ([]byte, bool) {
 	if !strings.HasSuffix(name, ".bin") {
 		name = name + ".bin"
@@ -103,6 +319,8 @@ func NewContractStore(abiPath, binPath string) (*ContractStore, error) {
 		}
 	}
 
+	cs.buildIndexes()
+
 	if binPath != "" {
 		files, err := os.ReadDir(binPath)
 		if err != nil {
@@ -1,8 +1,11 @@
 package seth_test
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/smartcontractkit/seth"
 	"github.com/stretchr/testify/require"
@@ -101,3 +104,77 @@ func TestSmokeContractBINStore(t *testing.T) {
 		})
 	}
 }
+
+func TestContractStoreIndexesCustomErrorsFromAllABIs(t *testing.T) {
+	cs, err := seth.NewContractStore("./contracts/abi", "")
+	require.NoError(t, err, "failed to create contract store")
+
+	networkDebugABI, ok := cs.GetABI("NetworkDebugContract")
+	require.True(t, ok, "missing NetworkDebugContract ABI")
+	customErr := networkDebugABI.Errors["CustomErr"]
+
+	definitions := cs.FindErrorsBySelector(customErr.ID.Bytes()[:4])
+	require.Len(t, definitions, 1, "same error defined in multiple ABIs should be indexed once")
+	require.Equal(t, []string{"NetworkDebugContract.abi", "NetworkDebugSubContract.abi"}, definitions[0].ABINames, "error should be indexed for all ABIs")
+
+	data, err := customErr.Inputs.Pack(big.NewInt(12), big.NewInt(21))
+	require.NoError(t, err, "failed to pack error values")
+	decoded, err := cs.DecodeCustomError(append(customErr.ID.Bytes()[:4], data...))
+	require.NoError(t, err, "failed to decode custom error")
+	require.Equal(t, "error type: CustomErr, error values: [12 21]", decoded, "incorrectly decoded custom error")
+
+	decoded, err = cs.DecodeCustomError(common.FromHex("0xdeadbeef"))
+	require.NoError(t, err, "unknown selector should not return an error")
+	require.Empty(t, decoded, "unknown selector should not be decoded")
+}
+
+func TestContractStoreReportsAmbiguousCustomErrors(t *testing.T) {
+	cs, err := seth.NewContractStore("", "")
+	require.NoError(t, err, "failed to create contract store")
+
+	uint256Type, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err, "failed to create uint256 type")
+	addressType, err := abi.NewType("address", "", nil)
+	require.NoError(t, err, "failed to create address type")
+
+	first := abi.NewError("First", abi.Arguments{{Name: "value", Type: uint256Type}})
+	// simulate a selector collision between two errors with different signatures
+	second := abi.NewError("Second", abi.Arguments{{Name: "who", Type: addressType}})
+	second.ID = first.ID
+
+	cs.AddABI("First", abi.ABI{Errors: map[string]abi.Error{"First": first}})
+	cs.AddABI("Second", abi.ABI{Errors: map[string]abi.Error{"Second": second}})
+
+	require.Len(t, cs.FindErrorsBySelector(first.ID.Bytes()[:4]), 2, "expected both colliding errors to be indexed")
+
+	data, err := first.Inputs.Pack(big.NewInt(1))
+	require.NoError(t, err, "failed to pack error values")
+	decoded, err := cs.DecodeCustomError(append(first.ID.Bytes()[:4], data...))
+	require.NoError(t, err, "failed to decode ambiguous custom error")
+	require.Contains(t, decoded, "ambiguous error selector", "ambiguity should be reported")
+	require.Contains(t, decoded, "error type: First, error values: [1]", "first candidate should be decoded")
+	require.Contains(t, decoded, "error type: Second, error values: [0x0000000000000000000000000000000000000001]", "second candidate should be decoded")
+}
+
+func TestContractStoreRebuildsIndexesWhenABIsMapChanges(t *testing.T) {
+	cs, err := seth.NewContractStore("", "")
+	require.NoError(t, err, "failed to create contract store")
+
+	uint256Type, err := abi.NewType("uint256", "", nil)
+	require.NoError(t, err, "failed to create uint256 type")
+
+	first := abi.NewError("First", abi.Arguments{{Name: "value", Type: uint256Type}})
+	second := abi.NewError("Second", abi.Arguments{{Name: "value", Type: uint256Type}})
+
+	cs.AddABI("Errors", abi.ABI{Errors: map[string]abi.Error{"First": first}})
+	require.Len(t, cs.FindErrorsBySelector(first.ID.Bytes()[:4]), 1, "expected error added with AddABI to be indexed")
+
+	// added directly to the map, bypassing AddABI()
+	cs.ABIs["Other.abi"] = abi.ABI{Errors: map[string]abi.Error{"Second": second}}
+	require.Len(t, cs.FindErrorsBySelector(second.ID.Bytes()[:4]), 1, "expected error added to the map to be indexed")
+
+	// same number of ABIs, but different content
+	cs.ABIs["Other.abi"] = abi.ABI{Errors: map[string]abi.Error{"First": first}}
+	require.Empty(t, cs.FindErrorsBySelector(second.ID.Bytes()[:4]), "replaced error should be removed from the index")
+	require.Equal(t, []string{"Errors.abi", "Other.abi"}, cs.FindErrorsBySelector(first.ID.Bytes()[:4])[0].ABINames, "expected new error to be indexed")
+}
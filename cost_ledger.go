@@ -0,0 +1,280 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	CostLedgerFilePattern = "cost_ledger_%s_%s"
+	// CostLedgerMethod_Deployment is the method name recorded for contract deployments
+	CostLedgerMethod_Deployment = "constructor"
+	// CostLedgerMethod_Transfer is the method name recorded for ETH transfers
+	CostLedgerMethod_Transfer = "transfer"
+	// CostLedgerUnknown is used in the summary for entries without known contract or method
+	CostLedgerUnknown = "unknown"
+)
+
+const (
+	ErrCostLedgerDisabled = "cost ledger is disabled"
+)
+
+// CostLedgerConfig controls the cost ledger, which records gas costs of all mined transactions seen by Seth
+type CostLedgerConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// CostLedgerEnabled returns true if cost ledger is enabled
+func (c *Config) CostLedgerEnabled() bool {
+	return c.CostLedger != nil && c.CostLedger.Enabled
+}
+
+// CostLedgerEntry is the cost of a single mined transaction
+type CostLedgerEntry struct {
+	TxHash string `json:"tx_hash"`
+	From   string `json:"from"`
+	// KeyNum is the index of the sender in client's keys or -1 if sender's key wasn't loaded
+	KeyNum            int      `json:"key_num"`
+	Contract          string   `json:"contract,omitempty"`
+	Method            string   `json:"method,omitempty"`
+	GasUsed           uint64   `json:"gas_used"`
+	EffectiveGasPrice *big.Int `json:"effective_gas_price"`
+	// L1Fee is the fee paid for posting transaction data to L1, it's only set on L2 networks
+	L1Fee *big.Int `json:"l1_fee,omitempty"`
+	// L1FeeInGasUsed is true if L1 fee was paid with L2 gas (Arbitrum), so it's already included in gas used
+	L1FeeInGasUsed bool `json:"l1_fee_in_gas_used,omitempty"`
+	// Cost is gas used * effective gas price + L1 fee (unless it's included in gas used)
+	Cost *big.Int `json:"cost"`
+}
+
+// CostLedgerSummaryItem is the aggregated cost of a group of transactions
+type CostLedgerSummaryItem struct {
+	Transactions int      `json:"transactions"`
+	GasUsed      uint64   `json:"gas_used"`
+	L1Fee        *big.Int `json:"l1_fee"`
+	Cost         *big.Int `json:"cost"`
+	CostETH      string   `json:"cost_eth"`
+}
+
+func (i *CostLedgerSummaryItem) add(entry CostLedgerEntry) {
+	i.Transactions++
+	i.GasUsed += entry.GasUsed
+	if entry.L1Fee != nil {
+		i.L1Fee.Add(i.L1Fee, entry.L1Fee)
+	}
+	i.Cost.Add(i.Cost, entry.Cost)
+	i.CostETH = WeiToEther(i.Cost).Text('f', -1)
+}
+
+// CostLedgerSummary is the cost of all recorded transactions grouped by sender key, contract and method ("Contract.method")
+type CostLedgerSummary struct {
+	ByKey      map[string]*CostLedgerSummaryItem `json:"by_key"`
+	ByContract map[string]*CostLedgerSummaryItem `json:"by_contract"`
+	ByMethod   map[string]*CostLedgerSummaryItem `json:"by_method"`
+	Total      *CostLedgerSummaryItem            `json:"total"`
+}
+
+// CostLedgerReport is what's saved to the artifacts dir
+type CostLedgerReport struct {
+	Network string             `json:"network"`
+	Summary *CostLedgerSummary `json:"summary"`
+	Entries []CostLedgerEntry  `json:"entries"`
+}
+
+// CostLedger records gas costs of mined transactions. Each transaction is recorded only once
+type CostLedger struct {
+	mu       sync.Mutex
+	entries  []CostLedgerEntry
+	recorded map[string]struct{}
+}
+
+// NewCostLedger creates a new empty cost ledger
+func NewCostLedger() *CostLedger {
+	return &CostLedger{
+		recorded: make(map[string]struct{}),
+	}
+}
+
+// Record adds entry to the ledger, if transaction with the same hash wasn't recorded yet. If entry's cost is not set, it's calculated
+func (l *CostLedger) Record(entry CostLedgerEntry) {
+	if entry.EffectiveGasPrice == nil {
+		entry.EffectiveGasPrice = big.NewInt(0)
+	}
+	if entry.Cost == nil {
+		entry.Cost = new(big.Int).Mul(new(big.Int).SetUint64(entry.GasUsed), entry.EffectiveGasPrice)
+		if entry.L1Fee != nil && !entry.L1FeeInGasUsed {
+			entry.Cost.Add(entry.Cost, entry.L1Fee)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.recorded[entry.TxHash]; ok {
+		return
+	}
+	l.recorded[entry.TxHash] = struct{}{}
+	l.entries = append(l.entries, entry)
+}
+
+// Entries returns a copy of all recorded entries
+func (l *CostLedger) Entries() []CostLedgerEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]CostLedgerEntry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
+
+// Summary returns costs grouped by sender key, contract and method together with the total cost
+func (l *CostLedger) Summary() *CostLedgerSummary {
+	summary := &CostLedgerSummary{
+		ByKey:      make(map[string]*CostLedgerSummaryItem),
+		ByContract: make(map[string]*CostLedgerSummaryItem),
+		ByMethod:   make(map[string]*CostLedgerSummaryItem),
+		Total:      newCostLedgerSummaryItem(),
+	}
+
+	addTo := func(group map[string]*CostLedgerSummaryItem, key string, entry CostLedgerEntry) {
+		if _, ok := group[key]; !ok {
+			group[key] = newCostLedgerSummaryItem()
+		}
+		group[key].add(entry)
+	}
+
+	for _, entry := range l.Entries() {
+		contract, method := entry.Contract, entry.Method
+		if contract == "" {
+			contract = CostLedgerUnknown
+		}
+		if method == "" {
+			method = CostLedgerUnknown
+		}
+
+		addTo(summary.ByKey, fmt.Sprintf("%d (%s)", entry.KeyNum, entry.From), entry)
+		addTo(summary.ByContract, contract, entry)
+		addTo(summary.ByMethod, fmt.Sprintf("%s.%s", contract, method), entry)
+		summary.Total.add(entry)
+	}
+
+	return summary
+}
+
+func newCostLedgerSummaryItem() *CostLedgerSummaryItem {
+	return &CostLedgerSummaryItem{L1Fee: big.NewInt(0), Cost: big.NewInt(0), CostETH: "0"}
+}
+
+// SaveCostLedger writes cost ledger summary and all its entries as JSON to the artifacts dir and returns the path of the file.
+// It should be called once all transactions were sent, e.g. at the end of the test
+func (m *Client) SaveCostLedger() (string, error) {
+	if m.CostLedger == nil {
+		return "", errors.New(ErrCostLedgerDisabled)
+	}
+
+	summary := m.CostLedger.Summary()
+	L.Info().
+		Int("Transactions", summary.Total.Transactions).
+		Uint64("Gas used", summary.Total.GasUsed).
+		Str("Total cost (ETH)", summary.Total.CostETH).
+		Msg("Cost ledger summary")
+
+	keys := make([]string, 0, len(summary.ByMethod))
+	for key := range summary.ByMethod {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		L.Debug().
+			Str("Method", key).
+			Int("Transactions", summary.ByMethod[key].Transactions).
+			Str("Cost (ETH)", summary.ByMethod[key].CostETH).
+			Msg("Cost by method")
+	}
+
+	path, err := saveAsJson(CostLedgerReport{
+		Network: m.Cfg.Network.Name,
+		Summary: summary,
+		Entries: m.CostLedger.Entries(),
+	}, m.Cfg.ArtifactsDir, fmt.Sprintf(CostLedgerFilePattern, m.Cfg.Network.Name, time.Now().Format("2006-01-02-15-04-05")))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to save cost ledger")
+	}
+
+	L.Info().Str("Path", path).Msg("Saved cost ledger")
+	return path, nil
+}
+
+// recordTransactionCost adds mined transaction to the cost ledger, if it's enabled. If receipt is nil, it's fetched from the node.
+// On L2 networks receipt is fetched anyway, because L1 fee is not part of the standard receipt. If contract name is empty, it's
+// looked up in the contract map.
+func (m *Client) recordTransactionCost(tx *types.Transaction, receipt *types.Receipt, contract, method string) {
+	if m.CostLedger == nil || tx == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+
+	var rawReceipt map[string]interface{}
+	if receipt == nil || m.chargesL1Fee() {
+		var err error
+		receipt, rawReceipt, err = m.transactionReceiptWithL1FeeFields(ctx, tx.Hash())
+		if err != nil {
+			L.Warn().Err(err).Str("TxHash", tx.Hash().Hex()).Msg("Failed to get transaction receipt. Transaction won't be recorded in cost ledger")
+			return
+		}
+	}
+
+	entry := CostLedgerEntry{
+		TxHash:            tx.Hash().Hex(),
+		KeyNum:            -1,
+		Contract:          contract,
+		Method:            method,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+	}
+	entry.L1Fee, entry.L1FeeInGasUsed = m.l1FeeFromReceipt(ctx, tx, receipt, rawReceipt)
+
+	if entry.EffectiveGasPrice == nil {
+		entry.EffectiveGasPrice = tx.GasPrice()
+	}
+
+	if entry.Method == "" {
+		switch {
+		case tx.To() == nil:
+			entry.Method = CostLedgerMethod_Deployment
+		case len(tx.Data()) == 0:
+			entry.Method = CostLedgerMethod_Transfer
+		}
+	}
+
+	if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+		entry.From = from.Hex()
+		for i, addr := range m.Addresses {
+			if addr == from {
+				entry.KeyNum = i
+				break
+			}
+		}
+	}
+
+	if entry.Contract == "" && m.ContractAddressToNameMap.mu != nil {
+		var addr common.Address
+		switch {
+		case tx.To() != nil:
+			addr = *tx.To()
+		case receipt.ContractAddress != (common.Address{}):
+			addr = receipt.ContractAddress
+		}
+		entry.Contract = m.ContractAddressToNameMap.GetContractName(addr.Hex())
+	}
+
+	m.CostLedger.Record(entry)
+}
@@ -0,0 +1,114 @@
+package seth_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func TestCostLedgerSummaryGroupsByKeyContractAndMethod(t *testing.T) {
+	ledger := seth.NewCostLedger()
+	ledger.Record(seth.CostLedgerEntry{TxHash: "0x01", From: "0xA", KeyNum: 0, Contract: "NetworkDebugContract", Method: seth.CostLedgerMethod_Deployment, GasUsed: 1_000_000, EffectiveGasPrice: big.NewInt(1_000_000_000)})
+	ledger.Record(seth.CostLedgerEntry{TxHash: "0x02", From: "0xA", KeyNum: 0, Contract: "NetworkDebugContract", Method: "set", GasUsed: 50_000, EffectiveGasPrice: big.NewInt(1_000_000_000), L1Fee: big.NewInt(1_000)})
+	ledger.Record(seth.CostLedgerEntry{TxHash: "0x03", From: "0xB", KeyNum: 1, Method: seth.CostLedgerMethod_Transfer, GasUsed: 21_000, EffectiveGasPrice: big.NewInt(2_000_000_000)})
+	// the same transaction might be decoded more than once
+	ledger.Record(seth.CostLedgerEntry{TxHash: "0x03", From: "0xB", KeyNum: 1, Method: seth.CostLedgerMethod_Transfer, GasUsed: 21_000, EffectiveGasPrice: big.NewInt(2_000_000_000)})
+
+	entries := ledger.Entries()
+	require.Len(t, entries, 3, "each transaction should be recorded once")
+	require.Equal(t, big.NewInt(50_000_000_001_000), entries[1].Cost, "cost should include L1 fee")
+
+	summary := ledger.Summary()
+	require.Equal(t, 3, summary.Total.Transactions, "incorrect number of transactions")
+	require.Equal(t, uint64(1_071_000), summary.Total.GasUsed, "incorrect total gas used")
+	require.Equal(t, big.NewInt(1_000), summary.Total.L1Fee, "incorrect total L1 fee")
+	require.Equal(t, "0.001092000000001", summary.Total.CostETH, "incorrect total cost in ETH")
+
+	require.Len(t, summary.ByKey, 2, "incorrect number of keys")
+	require.Equal(t, 2, summary.ByKey["0 (0xA)"].Transactions, "incorrect number of transactions of key 0")
+	require.Equal(t, big.NewInt(42_000_000_000_000), summary.ByKey["1 (0xB)"].Cost, "incorrect cost of key 1")
+
+	require.Len(t, summary.ByContract, 2, "incorrect number of contracts")
+	require.Equal(t, 2, summary.ByContract["NetworkDebugContract"].Transactions, "incorrect number of contract transactions")
+	require.Equal(t, 1, summary.ByContract[seth.CostLedgerUnknown].Transactions, "transfers should have unknown contract")
+
+	require.Len(t, summary.ByMethod, 3, "incorrect number of methods")
+	require.Equal(t, uint64(50_000), summary.ByMethod["NetworkDebugContract.set"].GasUsed, "incorrect gas used by method")
+}
+
+func TestCostLedgerIsSavedToArtifactsDir(t *testing.T) {
+	_, err := newFakeNodeClient(t, newFakeNode(t)).SaveCostLedger()
+	require.EqualError(t, err, seth.ErrCostLedgerDisabled, "saving should fail when ledger is disabled")
+
+	c := newFakeNodeClient(t, newFakeNode(t), withCostLedgerNode(), withCostLedger())
+	c.CostLedger.Record(seth.CostLedgerEntry{TxHash: "0x01", KeyNum: 0, Method: seth.CostLedgerMethod_Transfer, GasUsed: 21_000, EffectiveGasPrice: big.NewInt(1)})
+
+	path, err := c.SaveCostLedger()
+	require.NoError(t, err, "failed to save cost ledger")
+	require.Equal(t, c.Cfg.ArtifactsDir, filepath.Dir(path), "ledger should be saved to artifacts dir")
+	require.Contains(t, path, "cost_ledger_fake_node_", "file name should contain network name")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "failed to read cost ledger")
+
+	var report seth.CostLedgerReport
+	require.NoError(t, json.Unmarshal(content, &report), "failed to unmarshal cost ledger")
+	require.Equal(t, "fake_node", report.Network, "incorrect network")
+	require.Len(t, report.Entries, 1, "incorrect number of entries")
+	require.Equal(t, big.NewInt(21_000), report.Summary.Total.Cost, "incorrect total cost")
+}
+
+// withCostLedgerNode makes the fake node mine every sent transaction using 100_000 gas
+func withCostLedgerNode() fakeNodeClientOption {
+	return withNode(func(n *fakeNode) {
+		n.autoMine = true
+		n.gasUsed = 100_000
+	})
+}
+
+func TestCostLedgerRecordsDeploymentWithSingleReceiptFetch(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withCostLedgerNode(), withCostLedger())
+	// deployed contract has code, so that waiting for deployment succeeds
+	node.code[crypto.CreateAddress(c.Addresses[0], 0)] = []byte{0x01}
+	contractABI, err := abi.JSON(strings.NewReader(`[]`))
+	require.NoError(t, err, "failed to parse ABI")
+
+	_, err = c.DeployContract(c.NewTXOpts(), "Empty", contractABI, []byte{0x60, 0x00})
+	require.NoError(t, err, "failed to deploy contract")
+
+	entries := c.CostLedger.Entries()
+	require.Len(t, entries, 1, "deployment should be recorded")
+	require.Equal(t, seth.CostLedgerMethod_Deployment, entries[0].Method, "incorrect method")
+	require.Equal(t, uint64(100_000), entries[0].GasUsed, "incorrect gas used")
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	// one receipt is fetched while waiting for deployment and one is shared by cost ledger, metrics and gas limit log
+	require.Equal(t, 2, node.receiptCalls, "receipt should be fetched only once after deployment")
+}
+
+func TestCostLedgerRecordsRevertedDeployment(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withCostLedgerNode(), withCostLedger())
+	node.revert = true
+	contractABI, err := abi.JSON(strings.NewReader(`[]`))
+	require.NoError(t, err, "failed to parse ABI")
+
+	_, err = c.DeployContract(c.NewTXOpts(), "Empty", contractABI, []byte{0x60, 0x00})
+	require.ErrorContains(t, err, "deployment transaction was reverted", "reverted deployment should fail")
+
+	entries := c.CostLedger.Entries()
+	require.Len(t, entries, 1, "reverted deployment should be recorded")
+	require.Equal(t, seth.CostLedgerMethod_Deployment, entries[0].Method, "incorrect method")
+	require.Equal(t, uint64(100_000), entries[0].GasUsed, "gas used by reverted deployment should be recorded")
+}
@@ -1,9 +1,7 @@
 package seth
 
 import (
-	"bytes"
 	"context"
-	"encoding/hex"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -11,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
@@ -38,6 +37,10 @@ type DecodedTransaction struct {
 	Transaction *types.Transaction      `json:"transaction,omitempty"`
 	Receipt     *types.Receipt          `json:"receipt,omitempty"`
 	Events      []DecodedTransactionLog `json:"events,omitempty"`
+	// AccessList is the EIP-2930 access list sent with the transaction
+	AccessList types.AccessList `json:"access_list,omitempty"`
+	// AccessListGasSaved is the gas saved thanks to the access list
+	AccessListGasSaved int64 `json:"access_list_gas_saved,omitempty"`
 }
 
 type CommonData struct {
@@ -54,15 +57,16 @@ type CommonData struct {
 // DecodedCall decoded call
 type DecodedCall struct {
 	CommonData
-	FromAddress string             `json:"from_address,omitempty"`
-	ToAddress   string             `json:"to_address,omitempty"`
-	From        string             `json:"from,omitempty"`
-	To          string             `json:"to,omitempty"`
-	Events      []DecodedCommonLog `json:"events,omitempty"`
-	Comment     string             `json:"comment,omitempty"`
-	Value       int64              `json:"value,omitempty"`
-	GasLimit    uint64             `json:"gas_limit,omitempty"`
-	GasUsed     uint64             `json:"gas_used,omitempty"`
+	FromAddress  string             `json:"from_address,omitempty"`
+	ToAddress    string             `json:"to_address,omitempty"`
+	From         string             `json:"from,omitempty"`
+	To           string             `json:"to,omitempty"`
+	Events       []DecodedCommonLog `json:"events,omitempty"`
+	Comment      string             `json:"comment,omitempty"`
+	Value        int64              `json:"value,omitempty"`
+	GasLimit     uint64             `json:"gas_limit,omitempty"`
+	GasUsed      uint64             `json:"gas_used,omitempty"`
+	RevertReason string             `json:"revert_reason,omitempty"`
 }
 
 type DecodedCommonLog struct {
@@ -129,6 +133,10 @@ func (m *Client) decodeTransaction(l zerolog.Logger, tx *types.Transaction, rece
 		Transaction: tx,
 		Protected:   tx.Protected(),
 		Hash:        tx.Hash().String(),
+		AccessList:  tx.AccessList(),
+	}
+	if len(defaultTxn.AccessList) > 0 {
+		defaultTxn.AccessListGasSaved = accessListGasSaving(tx)
 	}
 	// if there is no tx data we have no inputs/outputs/logs
 	if len(txData) == 0 || len(txData) < 4 {
@@ -171,10 +179,7 @@ func (m *Client) decodeTransaction(l zerolog.Logger, tx *types.Transaction, rece
 		for _, l := range receipt.Logs {
 			logsValues = append(logsValues, *l)
 		}
-		txEvents, err = m.decodeContractLogs(l, logsValues, abiResult.ABI)
-		if err != nil {
-			return defaultTxn, err
-		}
+		txEvents = m.decodeContractLogs(l, logsValues)
 		txIndex = receipt.TransactionIndex
 	}
 	ptx := &DecodedTransaction{
@@ -183,12 +188,14 @@ func (m *Client) decodeTransaction(l zerolog.Logger, tx *types.Transaction, rece
 			Method:    abiResult.Method.Sig,
 			Input:     txInput,
 		},
-		Index:       txIndex,
-		Receipt:     receipt,
-		Transaction: tx,
-		Protected:   tx.Protected(),
-		Hash:        tx.Hash().String(),
-		Events:      txEvents,
+		Index:              txIndex,
+		Receipt:            receipt,
+		Transaction:        tx,
+		Protected:          tx.Protected(),
+		Hash:               tx.Hash().String(),
+		Events:             txEvents,
+		AccessList:         defaultTxn.AccessList,
+		AccessListGasSaved: defaultTxn.AccessListGasSaved,
 	}
 
 	return ptx, nil
@@ -196,6 +203,11 @@ func (m *Client) decodeTransaction(l zerolog.Logger, tx *types.Transaction, rece
 
 // printDecodedTXData prints decoded txn data
 func (m *Client) printDecodedTXData(l zerolog.Logger, ptx *DecodedTransaction) {
+	// formatting might call the node to read token decimals, don't do it if nothing will be printed
+	if !l.Debug().Enabled() {
+		return
+	}
+	ptx = m.ValueFormatter.FormatTransaction(ptx)
 	l.Debug().Str("Method signature", ptx.Signature).Send()
 	l.Debug().Str("Method name", ptx.Method).Send()
 	if ptx.Input != nil {
@@ -209,6 +221,12 @@ func (m *Client) printDecodedTXData(l zerolog.Logger, ptx *DecodedTransaction) {
 			Str("Signature", e.Signature).
 			Interface("Log", e.EventData).Send()
 	}
+	if len(ptx.AccessList) > 0 {
+		l.Debug().
+			Interface("Access list", ptx.AccessList).
+			Int64("Gas saved", ptx.AccessListGasSaved).
+			Send()
+	}
 }
 
 // DecodeCustomABIErr decodes typed Solidity errors
@@ -223,26 +241,15 @@ func (m *Client) DecodeCustomABIErr(txErr error) (string, error) {
 	}
 	if cerr.ErrorData() != nil {
 		L.Trace().Msg("Decoding custom ABI error from tx")
-		for _, a := range m.ContractStore.ABIs {
-			for k, abiError := range a.Errors {
-				data, err := hex.DecodeString(cerr.ErrorData().(string)[2:])
-				if err != nil {
-					return "", err
-				}
-				if len(data) < 4 {
-					return "", err
-				}
-				if bytes.Equal(data[:4], abiError.ID.Bytes()[:4]) {
-					// Found a matching error
-					v, err := abiError.Unpack(data)
-					if err != nil {
-						return "", err
-					}
-					L.Trace().Interface("Error", k).Interface("Args", v).Msg("Revert Reason")
-					return fmt.Sprintf("error type: %s, error values: %v", k, v), nil
-				}
-			}
+		errData, ok := cerr.ErrorData().(string)
+		if !ok {
+			return "", nil
+		}
+		data, err := hexutil.Decode(errData)
+		if err != nil {
+			return "", err
 		}
+		return m.ContractStore.DecodeCustomError(data)
 	} else {
 		L.Warn().Msg("No error data in tx")
 	}
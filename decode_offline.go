@@ -0,0 +1,122 @@
+package seth
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrNoRevertReason = "revert data doesn't match any custom error from known ABIs nor Error(string) or Panic(uint256)"
+)
+
+// OfflineDecoder decodes calldata, logs and revert data using only ABIs from the Contract Store and the contract map,
+// without any RPC connection. It's useful when all you have is a hex blob copied from node logs.
+type OfflineDecoder struct {
+	ContractStore *ContractStore
+	ABIFinder     *ABIFinder
+}
+
+// DecodedInput is calldata decoded together with the name of the contract whose ABI was used to decode it
+type DecodedInput struct {
+	CommonData
+	Contract string `json:"contract"`
+	// DuplicateCount is the number of other ABIs with the same method selector, if it's not 0 decoding might be ambiguous
+	DuplicateCount int `json:"duplicate_count,omitempty"`
+}
+
+// DecodedLog is a log decoded together with the name of the contract whose ABI was used to decode it
+type DecodedLog struct {
+	DecodedCommonLog
+	Contract string `json:"contract"`
+}
+
+// NewOfflineDecoder creates a new OfflineDecoder. Contract map is used only to pick the right ABI for known addresses.
+func NewOfflineDecoder(contractStore *ContractStore, contractMap ContractMap) *OfflineDecoder {
+	abiFinder := NewABIFinder(contractMap, contractStore)
+	return &OfflineDecoder{
+		ContractStore: contractStore,
+		ABIFinder:     &abiFinder,
+	}
+}
+
+// DecodeInput decodes transaction or call input. If "to" address is empty or unknown, all known ABIs are searched for the method selector.
+func (d *OfflineDecoder) DecodeInput(to string, data []byte) (*DecodedInput, error) {
+	if len(data) < 4 {
+		return nil, errors.New(ErrNoTxData)
+	}
+	if to == "" {
+		to = UNKNOWN
+	}
+
+	abiResult, err := d.ABIFinder.FindABIByMethod(to, data[:4])
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := decodeTxInputs(L, data, abiResult.Method)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrDecodeInput)
+	}
+
+	return &DecodedInput{
+		CommonData: CommonData{
+			Signature: common.Bytes2Hex(abiResult.Method.ID),
+			Method:    abiResult.Method.Sig,
+			Input:     input,
+		},
+		Contract:       abiResult.ContractName(),
+		DuplicateCount: abiResult.DuplicateCount,
+	}, nil
+}
+
+// DecodeLog decodes log data and topics. Address of the emitting contract is optional, but it helps to pick the right ABI.
+func (d *OfflineDecoder) DecodeLog(address string, topics []common.Hash, data []byte) (*DecodedLog, error) {
+	if address == "" {
+		address = UNKNOWN
+	}
+
+	abiResult, err := d.ABIFinder.FindABIByEvent(address, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	eventsMap, topicsMap, err := decodeEventFromLog(L, abiResult.ABI, *abiResult.Event, TransactionLog{topics, data})
+	if err != nil {
+		return nil, errors.Wrap(err, ErrDecodeLog)
+	}
+
+	decoded := &DecodedLog{Contract: abiResult.ContractName()}
+	decodedLogFromMaps(&decoded.DecodedCommonLog, eventsMap, topicsMap)
+	decoded.Signature = abiResult.Event.Sig
+	if common.IsHexAddress(address) {
+		decoded.Address = common.HexToAddress(address)
+	}
+	for _, topic := range topics {
+		decoded.Topics = append(decoded.Topics, topic.Hex())
+	}
+
+	return decoded, nil
+}
+
+// DecodeError decodes revert data using custom errors from all known ABIs, falling back to standard Error(string) and Panic(uint256) errors
+func (d *OfflineDecoder) DecodeError(data []byte) (string, error) {
+	if len(data) < 4 {
+		return "", errors.New(ErrNoRevertReason)
+	}
+
+	reason, err := d.ContractStore.DecodeCustomError(data)
+	if err != nil {
+		return "", err
+	}
+	if reason != "" {
+		return reason, nil
+	}
+
+	reason, err = abi.UnpackRevert(data)
+	if err != nil {
+		return "", errors.Wrap(err, ErrNoRevertReason)
+	}
+
+	return reason, nil
+}
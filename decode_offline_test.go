@@ -0,0 +1,110 @@
+package seth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+var (
+	offlineDecoderToken     = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	offlineDecoderRecipient = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+)
+
+func newOfflineDecoder(t *testing.T) (*seth.OfflineDecoder, *seth.ContractStore) {
+	cs, err := seth.NewContractStore("./contracts/abi", "")
+	require.NoError(t, err, "failed to create contract store")
+	contractMap := seth.NewEmptyContractMap()
+	contractMap.AddContract(offlineDecoderToken, "LinkTokenModern")
+
+	return seth.NewOfflineDecoder(cs, contractMap), cs
+}
+
+func TestOfflineDecoderDecodesInput(t *testing.T) {
+	decoder, cs := newOfflineDecoder(t)
+	linkABI, ok := cs.GetABI("LinkTokenModern")
+	require.True(t, ok, "ABI not found")
+
+	data, err := linkABI.Pack("transfer", offlineDecoderRecipient, big.NewInt(1_000))
+	require.NoError(t, err, "failed to pack input")
+
+	decoded, err := decoder.DecodeInput(offlineDecoderToken, data)
+	require.NoError(t, err, "failed to decode input")
+	require.Equal(t, "LinkTokenModern", decoded.Contract, "ABI of the called contract should be used")
+	require.Equal(t, "transfer(address,uint256)", decoded.Method, "incorrect method")
+	require.Equal(t, "a9059cbb", decoded.Signature, "incorrect signature")
+	require.Equal(t, 0, decoded.DuplicateCount, "there should be no duplicates for known address")
+	require.Equal(t, offlineDecoderRecipient, decoded.Input["to"], "incorrect recipient")
+	require.Equal(t, big.NewInt(1_000), decoded.Input["amount"], "incorrect amount")
+
+	// both LINK token ABIs have transfer method
+	decoded, err = decoder.DecodeInput("", data)
+	require.NoError(t, err, "failed to decode input without address")
+	require.Equal(t, "transfer(address,uint256)", decoded.Method, "incorrect method")
+	require.Positive(t, decoded.DuplicateCount, "ambiguous selector should be reported")
+
+	_, err = decoder.DecodeInput("", []byte{0x1, 0x2})
+	require.EqualError(t, err, seth.ErrNoTxData, "expected error for too short input")
+
+	_, err = decoder.DecodeInput("", common.FromHex("0xdeadbeef"))
+	require.EqualError(t, err, seth.ErrNoABIMethod, "expected error for unknown selector")
+}
+
+func TestOfflineDecoderDecodesLog(t *testing.T) {
+	decoder, _ := newOfflineDecoder(t)
+
+	from := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	topics := []common.Hash{
+		crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+		common.BytesToHash(from.Bytes()),
+		common.BytesToHash(offlineDecoderRecipient.Bytes()),
+	}
+	data := common.BigToHash(big.NewInt(1_000)).Bytes()
+
+	decoded, err := decoder.DecodeLog(offlineDecoderToken, topics, data)
+	require.NoError(t, err, "failed to decode log")
+	require.Equal(t, "LinkTokenModern", decoded.Contract, "ABI of the emitting contract should be used")
+	require.Equal(t, "Transfer(address,address,uint256)", decoded.Signature, "incorrect event")
+	require.Equal(t, common.HexToAddress(offlineDecoderToken), decoded.Address, "incorrect address")
+	require.Len(t, decoded.Topics, 3, "topics should be kept")
+	require.Equal(t, from, decoded.EventData["from"], "incorrect indexed field")
+	require.Equal(t, offlineDecoderRecipient, decoded.EventData["to"], "incorrect indexed field")
+	require.Equal(t, big.NewInt(1_000), decoded.EventData["value"], "incorrect non-indexed field")
+
+	decoded, err = decoder.DecodeLog("", []common.Hash{crypto.Keccak256Hash([]byte("OneIndexEvent(uint256)")), common.BigToHash(big.NewInt(5))}, nil)
+	require.NoError(t, err, "failed to decode log without address and data")
+	require.Equal(t, big.NewInt(5), decoded.EventData["a"], "incorrect indexed field")
+
+	_, err = decoder.DecodeLog("", []common.Hash{crypto.Keccak256Hash([]byte("Unknown()"))}, nil)
+	require.EqualError(t, err, seth.ErrNoABIEvent, "expected error for unknown event")
+}
+
+func TestOfflineDecoderDecodesError(t *testing.T) {
+	decoder, cs := newOfflineDecoder(t)
+	debugABI, ok := cs.GetABI("NetworkDebugContract")
+	require.True(t, ok, "ABI not found")
+
+	customErr := debugABI.Errors["CustomErr"]
+	args, err := customErr.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	require.NoError(t, err, "failed to pack custom error")
+	reason, err := decoder.DecodeError(append(customErr.ID.Bytes()[:4], args...))
+	require.NoError(t, err, "failed to decode custom error")
+	require.Equal(t, "error type: CustomErr, error values: [1 2]", reason, "incorrect revert reason")
+
+	stringType, err := abi.NewType("string", "", nil)
+	require.NoError(t, err, "failed to create string type")
+	message, err := abi.Arguments{{Type: stringType}}.Pack("not enough funds")
+	require.NoError(t, err, "failed to pack Error(string)")
+	reason, err = decoder.DecodeError(append(crypto.Keccak256([]byte("Error(string)"))[:4], message...))
+	require.NoError(t, err, "failed to decode Error(string)")
+	require.Equal(t, "not enough funds", reason, "incorrect revert reason")
+
+	_, err = decoder.DecodeError(common.FromHex("0xdeadbeef"))
+	require.ErrorContains(t, err, seth.ErrNoRevertReason, "expected error for unknown revert data")
+}
@@ -0,0 +1,751 @@
+package seth_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const (
+	fakeNodeChainID       = 1337
+	fakeNodeGasPrice      = 1_000
+	fakeNodeGasFeeCap     = 2_000
+	fakeNodeGasTipCap     = 1_000
+	fakeNodeBlockGasLimit = 30_000_000
+)
+
+// fakeNode is an in-memory node serving "eth", "net" and "txpool" RPC namespaces over HTTP. Sent transactions stay in the
+// transaction pool until they are mined with mine(), unless autoMine is set. Replacements have to outbid pending transactions
+// by at least 10%, like in Geth. Fields holding responses can be changed by tests before calling the client.
+type fakeNode struct {
+	URL string
+
+	mu sync.Mutex
+	// head is the number of the latest block, mined transactions are included in it
+	head          uint64
+	blockGasLimit uint64
+	baseFee       *big.Int
+	gasEstimate   uint64
+	// gasUsed is the gas used reported in receipts of mined transactions
+	gasUsed uint64
+	// autoMine mines sent transactions right away, as long as there are no gaps in nonces
+	autoMine bool
+	// revert makes auto-mined transactions fail
+	revert bool
+	// underpriced is the number of sent transactions, which will be rejected as underpriced
+	underpriced int
+	// txPoolDisabled makes "txpool" namespace return errors, like nodes that don't expose it
+	txPoolDisabled bool
+	// callResults are canned ABI-encoded results of eth_call by contract address
+	callResults map[common.Address]hexutil.Bytes
+	// callErr is returned by eth_call to addresses without canned results, if set
+	callErr error
+	// receiptFields are extra fields added to every receipt, e.g. "l1Fee" of L2 networks
+	receiptFields   map[string]interface{}
+	accessList      types.AccessList
+	accessListCalls int
+	receiptCalls    int
+	blockCalls      int
+	code            map[common.Address]hexutil.Bytes
+	balances        map[common.Address]*big.Int
+	latestNonces    map[common.Address]uint64
+	pool            map[common.Address]map[uint64]*types.Transaction
+	txs             map[common.Hash]*types.Transaction
+	receipts        map[common.Hash]*types.Receipt
+	blocks          map[uint64][]*types.Transaction
+	sent            []*types.Transaction
+	// calls are inputs of all eth_call requests by contract address
+	calls map[common.Address][]hexutil.Bytes
+}
+
+// newFakeNode starts a new fakeNode, which is stopped when the test finishes
+func newFakeNode(t *testing.T) *fakeNode {
+	node := &fakeNode{
+		head:          1,
+		blockGasLimit: fakeNodeBlockGasLimit,
+		baseFee:       big.NewInt(1),
+		gasEstimate:   21_000,
+		gasUsed:       21_000,
+		callResults:   make(map[common.Address]hexutil.Bytes),
+		receiptFields: make(map[string]interface{}),
+		code:          make(map[common.Address]hexutil.Bytes),
+		balances:      make(map[common.Address]*big.Int),
+		latestNonces:  make(map[common.Address]uint64),
+		pool:          make(map[common.Address]map[uint64]*types.Transaction),
+		txs:           make(map[common.Hash]*types.Transaction),
+		receipts:      make(map[common.Hash]*types.Receipt),
+		blocks:        make(map[uint64][]*types.Transaction),
+		calls:         make(map[common.Address][]hexutil.Bytes),
+	}
+
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", &fakeEthNamespace{node}), "failed to register fake eth namespace")
+	require.NoError(t, server.RegisterName("net", &fakeNetNamespace{node}), "failed to register fake net namespace")
+	require.NoError(t, server.RegisterName("txpool", &fakeTxPoolNamespace{node}), "failed to register fake txpool namespace")
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	node.URL = httpServer.URL
+
+	return node
+}
+
+// newFakeNodeKeys generates hex encoded private keys
+func newFakeNodeKeys(t *testing.T, count int) []string {
+	var keys []string
+	for i := 0; i < count; i++ {
+		pk, err := crypto.GenerateKey()
+		require.NoError(t, err, "failed to generate key")
+		keys = append(keys, hexutil.Encode(crypto.FromECDSA(pk))[2:])
+	}
+	return keys
+}
+
+// fakeNodeClientOption sets up the fake node, changes default config of the client created by newFakeNodeClient
+// or sets the client up after it's created
+type fakeNodeClientOption struct {
+	node   func(n *fakeNode)
+	config func(cfg *seth.Config)
+	client func(t *testing.T, c *seth.Client)
+}
+
+// newFakeNodeClient creates a client connected to the fake node with NewClientWithConfig. Default config uses a single key,
+// fixed gas prices and no tracing; options are applied in order, node and config ones before the client is created.
+func newFakeNodeClient(t *testing.T, node *fakeNode, options ...fakeNodeClientOption) *seth.Client {
+	cfg := &seth.Config{
+		ArtifactsDir: t.TempDir(),
+		TracingLevel: seth.TracingLevel_None,
+		Network: &seth.Network{
+			Name:           "fake_node",
+			URLs:           []string{node.URL},
+			PrivateKeys:    newFakeNodeKeys(t, 1),
+			TxnTimeout:     seth.MustMakeDuration(5 * time.Second),
+			DialTimeout:    seth.MustMakeDuration(time.Second),
+			TransferGasFee: 21_000,
+			GasPrice:       fakeNodeGasPrice,
+			GasFeeCap:      fakeNodeGasFeeCap,
+			GasTipCap:      fakeNodeGasTipCap,
+			GasOracle:      seth.GasOracle_Fixed,
+		},
+		NonceManager: &seth.NonceManagerCfg{
+			KeySyncRateLimitSec: 10,
+			KeySyncRetries:      1,
+			KeySyncTimeout:      seth.MustMakeDuration(time.Second),
+			KeySyncRetryDelay:   seth.MustMakeDuration(10 * time.Millisecond),
+		},
+	}
+	for _, o := range options {
+		if o.node != nil {
+			o.node(node)
+		}
+		if o.config != nil {
+			o.config(cfg)
+		}
+	}
+
+	c, err := seth.NewClientWithConfig(cfg)
+	require.NoError(t, err, "failed to create client connected to fake node")
+	t.Cleanup(c.CancelFunc)
+
+	for _, o := range options {
+		if o.client != nil {
+			o.client(t, c)
+		}
+	}
+
+	return c
+}
+
+// withNode changes the fake node before the client is created
+func withNode(fn func(n *fakeNode)) fakeNodeClientOption {
+	return fakeNodeClientOption{node: fn}
+}
+
+// withConfig changes any part of the default config
+func withConfig(fn func(cfg *seth.Config)) fakeNodeClientOption {
+	return fakeNodeClientOption{config: fn}
+}
+
+// withAutoMine makes the fake node mine every sent transaction
+func withAutoMine() fakeNodeClientOption {
+	return withNode(func(n *fakeNode) {
+		n.autoMine = true
+	})
+}
+
+// withKeys replaces the default key with given number of new keys
+func withKeys(t *testing.T, count int) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.Network.PrivateKeys = newFakeNodeKeys(t, count)
+	})
+}
+
+func withEIP1559(enabled bool) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.Network.EIP1559DynamicFees = enabled
+	})
+}
+
+func withTxnTimeout(timeout time.Duration) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.Network.TxnTimeout = seth.MustMakeDuration(timeout)
+	})
+}
+
+func withGasBump(gasBump *seth.GasBumpConfig) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.GasBump = gasBump
+	})
+}
+
+func withGasLimitEstimation(estimation *seth.GasLimitEstimationConfig) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.GasLimitEstimation = estimation
+	})
+}
+
+func withCostLedger() fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.CostLedger = &seth.CostLedgerConfig{Enabled: true}
+	})
+}
+
+func withMetrics(listenAddress string) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.Metrics = &seth.MetricsConfig{Enabled: true, ListenAddress: listenAddress}
+	})
+}
+
+func withTracingLevel(level string) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.TracingLevel = level
+	})
+}
+
+func withABIDir(dir string) fakeNodeClientOption {
+	return withConfig(func(cfg *seth.Config) {
+		cfg.ABIDir = dir
+	})
+}
+
+// withStuckTxWatchdog enables the watchdog in config. Unless checkInterval is short, checks have to be run manually.
+func withStuckTxWatchdog(checkInterval time.Duration) fakeNodeClientOption {
+	return fakeNodeClientOption{
+		config: func(cfg *seth.Config) {
+			cfg.StuckTxWatchdog = &seth.StuckTxWatchdogConfig{
+				Enabled:         true,
+				CheckInterval:   seth.MustMakeDuration(checkInterval),
+				MaxReplacements: 2,
+			}
+		},
+		client: func(t *testing.T, c *seth.Client) {
+			// wait for the background checks to finish, before the next client replaces the global logger
+			t.Cleanup(c.StuckTxWatchdog.Stop)
+		},
+	}
+}
+
+// withABI parses the ABI and adds it to the contract store under given name
+func withABI(name, abiJSON string) fakeNodeClientOption {
+	return fakeNodeClientOption{
+		client: func(t *testing.T, c *seth.Client) {
+			contractABI, err := abi.JSON(strings.NewReader(abiJSON))
+			require.NoError(t, err, "failed to parse ABI")
+			c.ContractStore.AddABI(name, contractABI)
+		},
+	}
+}
+
+// withContract deploys fake code at the address and adds the contract to the contract map under given name
+func withContract(address common.Address, name string) fakeNodeClientOption {
+	return fakeNodeClientOption{
+		node: func(n *fakeNode) {
+			n.code[address] = hexutil.Bytes{0x60}
+		},
+		client: func(_ *testing.T, c *seth.Client) {
+			c.ContractAddressToNameMap.AddContract(address.Hex(), name)
+		},
+	}
+}
+
+// addPending adds already signed transaction to the transaction pool, as if it was sent by someone else
+func (n *fakeNode) addPending(t *testing.T, tx *types.Transaction) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	require.NoError(t, n.addToPool(tx), "failed to add transaction to the pool")
+}
+
+// mine includes the transaction in the head block with given status and logs, removing it from the transaction pool
+func (n *fakeNode) mine(t *testing.T, tx *types.Transaction, status uint64, logs ...*types.Log) *types.Receipt {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	from, err := n.sender(tx)
+	require.NoError(t, err, "failed to recover sender")
+	return n.mineTx(from, tx, status, logs)
+}
+
+// newBlock advances the head, so that following transactions are mined in a new block
+func (n *fakeNode) newBlock() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.head++
+	return n.head
+}
+
+// callInputs returns inputs of all eth_call requests to the contract
+func (n *fakeNode) callInputs(to common.Address) []hexutil.Bytes {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]hexutil.Bytes{}, n.calls[to]...)
+}
+
+// sentTxs returns all transactions accepted by eth_sendRawTransaction
+func (n *fakeNode) sentTxs() []*types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*types.Transaction{}, n.sent...)
+}
+
+// pending returns pooled transaction of the sender with given nonce
+func (n *fakeNode) pending(from common.Address, nonce uint64) *types.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.pool[from][nonce]
+}
+
+func (n *fakeNode) setLatestNonce(address common.Address, nonce uint64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.latestNonces[address] = nonce
+}
+
+func (n *fakeNode) setBalance(address common.Address, balance *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.balances[address] = balance
+}
+
+func (n *fakeNode) sender(tx *types.Transaction) (common.Address, error) {
+	return types.Sender(types.LatestSignerForChainID(big.NewInt(fakeNodeChainID)), tx)
+}
+
+// pendingNonce returns the next nonce after consecutive pooled transactions. Must be called with the lock held.
+func (n *fakeNode) pendingNonce(from common.Address) uint64 {
+	nonce := n.latestNonces[from]
+	for n.pool[from][nonce] != nil {
+		nonce++
+	}
+	return nonce
+}
+
+// addToPool adds transaction to the pool, if it has a nonce that wasn't mined yet and outbids the pooled transaction with the
+// same nonce. Must be called with the lock held.
+func (n *fakeNode) addToPool(tx *types.Transaction) error {
+	from, err := n.sender(tx)
+	if err != nil {
+		return err
+	}
+	if tx.Nonce() < n.latestNonces[from] {
+		return errors.New("nonce too low")
+	}
+	if previous := n.pool[from][tx.Nonce()]; previous != nil {
+		if !outbids(tx.GasFeeCap(), previous.GasFeeCap()) || !outbids(tx.GasTipCap(), previous.GasTipCap()) {
+			return errors.New("replacement transaction underpriced")
+		}
+	}
+	if n.pool[from] == nil {
+		n.pool[from] = make(map[uint64]*types.Transaction)
+	}
+	n.pool[from][tx.Nonce()] = tx
+	n.txs[tx.Hash()] = tx
+	return nil
+}
+
+// outbids returns true if fee is at least 10% higher than previous one
+func outbids(fee, previous *big.Int) bool {
+	minimum := new(big.Int).Div(new(big.Int).Mul(previous, big.NewInt(110)), big.NewInt(100))
+	return fee.Cmp(minimum) >= 0
+}
+
+// mineTx includes the transaction in the head block. Must be called with the lock held.
+func (n *fakeNode) mineTx(from common.Address, tx *types.Transaction, status uint64, logs []*types.Log) *types.Receipt {
+	delete(n.pool[from], tx.Nonce())
+	if tx.Nonce()+1 > n.latestNonces[from] {
+		n.latestNonces[from] = tx.Nonce() + 1
+	}
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	for i, lo := range logs {
+		lo.TxHash = tx.Hash()
+		lo.BlockNumber = n.head
+		lo.Index = uint(i)
+	}
+
+	effectiveGasPrice := tx.GasPrice()
+	if tx.Type() != types.LegacyTxType && tx.Type() != types.AccessListTxType {
+		effectiveGasPrice = new(big.Int).Add(n.baseFee, tx.GasTipCap())
+		if effectiveGasPrice.Cmp(tx.GasFeeCap()) > 0 {
+			effectiveGasPrice = tx.GasFeeCap()
+		}
+	}
+
+	n.txs[tx.Hash()] = tx
+	n.blocks[n.head] = append(n.blocks[n.head], tx)
+	receipt := &types.Receipt{
+		Type:              tx.Type(),
+		Status:            status,
+		CumulativeGasUsed: n.gasUsed,
+		GasUsed:           n.gasUsed,
+		EffectiveGasPrice: effectiveGasPrice,
+		Logs:              logs,
+		TxHash:            tx.Hash(),
+		BlockNumber:       new(big.Int).SetUint64(n.head),
+		TransactionIndex:  uint(len(n.blocks[n.head]) - 1),
+	}
+	if tx.To() == nil {
+		receipt.ContractAddress = crypto.CreateAddress(from, tx.Nonce())
+	}
+	n.receipts[tx.Hash()] = receipt
+
+	return receipt
+}
+
+// mineConsecutive mines pooled transactions of the sender starting from the latest nonce. Must be called with the lock held.
+func (n *fakeNode) mineConsecutive(from common.Address) {
+	for {
+		tx := n.pool[from][n.latestNonces[from]]
+		if tx == nil {
+			return
+		}
+		status := types.ReceiptStatusSuccessful
+		if n.revert {
+			status = types.ReceiptStatusFailed
+		}
+		n.mineTx(from, tx, status, nil)
+	}
+}
+
+// toFields marshals value to JSON object fields, so that extra fields can be added to it
+func toFields(value json.Marshaler) (map[string]interface{}, error) {
+	encoded, err := value.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(encoded, &fields)
+	return fields, err
+}
+
+// fakeEthNamespace is the "eth" RPC namespace of fakeNode
+type fakeEthNamespace struct {
+	n *fakeNode
+}
+
+type fakeCallArgs struct {
+	From       *common.Address   `json:"from"`
+	To         *common.Address   `json:"to"`
+	Input      hexutil.Bytes     `json:"input"`
+	Data       hexutil.Bytes     `json:"data"`
+	AccessList *types.AccessList `json:"accessList"`
+}
+
+func (e *fakeEthNamespace) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(fakeNodeChainID))
+}
+
+func (e *fakeEthNamespace) BlockNumber() hexutil.Uint64 {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	return hexutil.Uint64(e.n.head)
+}
+
+func (e *fakeEthNamespace) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(fakeNodeGasPrice))
+}
+
+func (e *fakeEthNamespace) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(fakeNodeGasTipCap))
+}
+
+func (e *fakeEthNamespace) GetBalance(address common.Address, _ string) *hexutil.Big {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	if balance, ok := e.n.balances[address]; ok {
+		return (*hexutil.Big)(balance)
+	}
+	return (*hexutil.Big)(big.NewInt(0))
+}
+
+func (e *fakeEthNamespace) GetCode(address common.Address, _ string) hexutil.Bytes {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	return e.n.code[address]
+}
+
+func (e *fakeEthNamespace) GetTransactionCount(address common.Address, block string) hexutil.Uint64 {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	if block == "pending" {
+		return hexutil.Uint64(e.n.pendingNonce(address))
+	}
+	return hexutil.Uint64(e.n.latestNonces[address])
+}
+
+// EstimateGas returns gasEstimate adjusted by the access list like in EVM (EIP-2929 and EIP-2930): each entry costs 2400 gas
+// and each storage key 1900 gas, but first access to the address saves 2500 gas (unless it's the sender or recipient, which are
+// always warm) and first access to each storage key saves 2000 gas. It assumes that everything in the access list is accessed.
+func (e *fakeEthNamespace) EstimateGas(args fakeCallArgs) hexutil.Uint64 {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	gas := int64(e.n.gasEstimate)
+	if args.AccessList != nil {
+		for _, tuple := range *args.AccessList {
+			keys := int64(len(tuple.StorageKeys))
+			gas += 2400 + 1900*keys - 2000*keys
+			alwaysWarm := (args.From != nil && *args.From == tuple.Address) || (args.To != nil && *args.To == tuple.Address)
+			if !alwaysWarm {
+				gas -= 2500
+			}
+		}
+	}
+	return hexutil.Uint64(gas)
+}
+
+func (e *fakeEthNamespace) Call(args fakeCallArgs, _ string) (hexutil.Bytes, error) {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	if args.To != nil {
+		input := args.Input
+		if input == nil {
+			input = args.Data
+		}
+		e.n.calls[*args.To] = append(e.n.calls[*args.To], input)
+		if result, ok := e.n.callResults[*args.To]; ok {
+			return result, nil
+		}
+	}
+	if e.n.callErr != nil {
+		return nil, e.n.callErr
+	}
+	return hexutil.Bytes{}, nil
+}
+
+type fakeAccessListResult struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64   `json:"gasUsed"`
+}
+
+func (e *fakeEthNamespace) CreateAccessList(_ fakeCallArgs) fakeAccessListResult {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	e.n.accessListCalls++
+	return fakeAccessListResult{AccessList: e.n.accessList, GasUsed: hexutil.Uint64(e.n.gasEstimate)}
+}
+
+func (e *fakeEthNamespace) SendRawTransaction(encoded hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encoded); err != nil {
+		return common.Hash{}, err
+	}
+
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	if e.n.underpriced > 0 {
+		e.n.underpriced--
+		return common.Hash{}, errors.New("replacement transaction underpriced")
+	}
+	if err := e.n.addToPool(tx); err != nil {
+		return common.Hash{}, err
+	}
+	e.n.sent = append(e.n.sent, tx)
+	if e.n.autoMine {
+		from, _ := e.n.sender(tx)
+		e.n.mineConsecutive(from)
+	}
+
+	return tx.Hash(), nil
+}
+
+func (e *fakeEthNamespace) GetTransactionByHash(hash common.Hash) (map[string]interface{}, error) {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	tx, ok := e.n.txs[hash]
+	if !ok {
+		return nil, nil
+	}
+	fields, err := toFields(tx)
+	if err != nil {
+		return nil, err
+	}
+	if receipt, ok := e.n.receipts[hash]; ok {
+		fields["blockNumber"] = hexutil.EncodeBig(receipt.BlockNumber)
+		fields["blockHash"] = common.BigToHash(receipt.BlockNumber)
+	}
+	return fields, nil
+}
+
+func (e *fakeEthNamespace) GetTransactionReceipt(hash common.Hash) (map[string]interface{}, error) {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	e.n.receiptCalls++
+	receipt, ok := e.n.receipts[hash]
+	if !ok {
+		return nil, nil
+	}
+	fields, err := toFields(receipt)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range e.n.receiptFields {
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+func (e *fakeEthNamespace) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+	e.n.blockCalls++
+	blockNumber := e.n.head
+	if number >= 0 {
+		blockNumber = uint64(number)
+	}
+	if blockNumber > e.n.head {
+		return nil, nil
+	}
+
+	txs := e.n.blocks[blockNumber]
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(blockNumber),
+		Difficulty: big.NewInt(0),
+		GasLimit:   e.n.blockGasLimit,
+		BaseFee:    e.n.baseFee,
+		UncleHash:  types.EmptyUncleHash,
+		TxHash:     types.EmptyTxsHash,
+		Time:       uint64(time.Now().Unix()),
+	}
+	if len(txs) > 0 {
+		header.TxHash = common.BigToHash(big.NewInt(int64(len(txs))))
+	}
+	fields, err := toFields(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if fullTx {
+		fields["transactions"] = txs
+	} else {
+		hashes := make([]common.Hash, 0, len(txs))
+		for _, tx := range txs {
+			hashes = append(hashes, tx.Hash())
+		}
+		fields["transactions"] = hashes
+	}
+	fields["uncles"] = []common.Hash{}
+
+	return fields, nil
+}
+
+func (e *fakeEthNamespace) GetLogs(filter map[string]interface{}) []*types.Log {
+	e.n.mu.Lock()
+	defer e.n.mu.Unlock()
+
+	addresses := map[common.Address]bool{}
+	switch address := filter["address"].(type) {
+	case string:
+		addresses[common.HexToAddress(address)] = true
+	case []interface{}:
+		for _, a := range address {
+			addresses[common.HexToAddress(a.(string))] = true
+		}
+	}
+
+	logs := []*types.Log{}
+	for number := uint64(0); number <= e.n.head; number++ {
+		for _, tx := range e.n.blocks[number] {
+			for _, lo := range e.n.receipts[tx.Hash()].Logs {
+				if len(addresses) == 0 || addresses[lo.Address] {
+					logs = append(logs, lo)
+				}
+			}
+		}
+	}
+	return logs
+}
+
+// fakeNetNamespace is the "net" RPC namespace of fakeNode
+type fakeNetNamespace struct {
+	n *fakeNode
+}
+
+func (*fakeNetNamespace) Version() string {
+	return strconv.Itoa(fakeNodeChainID)
+}
+
+// fakeTxPoolNamespace is the "txpool" RPC namespace of fakeNode
+type fakeTxPoolNamespace struct {
+	n *fakeNode
+}
+
+func (p *fakeTxPoolNamespace) ContentFrom(address common.Address) (map[string]map[string]*types.Transaction, error) {
+	p.n.mu.Lock()
+	defer p.n.mu.Unlock()
+	if p.n.txPoolDisabled {
+		return nil, errors.New("the method txpool_contentFrom does not exist/is not available")
+	}
+
+	content := map[string]map[string]*types.Transaction{"pending": {}, "queued": {}}
+	pending := p.n.pendingNonce(address)
+	for nonce, tx := range p.n.pool[address] {
+		if nonce < pending {
+			content["pending"][strconv.FormatUint(nonce, 10)] = tx
+		} else {
+			content["queued"][strconv.FormatUint(nonce, 10)] = tx
+		}
+	}
+	return content, nil
+}
+
+func (p *fakeTxPoolNamespace) Content() (map[string]map[string]map[string]*types.Transaction, error) {
+	p.n.mu.Lock()
+	disabled := p.n.txPoolDisabled
+	senders := make([]common.Address, 0, len(p.n.pool))
+	for from := range p.n.pool {
+		senders = append(senders, from)
+	}
+	p.n.mu.Unlock()
+	if disabled {
+		return nil, errors.New("the method txpool_content does not exist/is not available")
+	}
+
+	content := map[string]map[string]map[string]*types.Transaction{"pending": {}, "queued": {}}
+	for _, from := range senders {
+		fromContent, err := p.ContentFrom(from)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(from.Hex())
+		content["pending"][key] = fromContent["pending"]
+		content["queued"][key] = fromContent["queued"]
+	}
+	return content, nil
+}
@@ -0,0 +1,385 @@
+package seth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	FeeHistoryFixtureFilePattern = "fee_history_%s_%d"
+)
+
+const (
+	ErrFeeHistoryFixture           = "failed to record fee history fixture"
+	ErrInvalidFeeHistoryFixture    = "invalid fee history fixture"
+	ErrFeeHistoryFixtureRange      = "block is outside of the range recorded in fee history fixture"
+	ErrFeeHistoryFixturePercentile = "reward percentile wasn't recorded in fee history fixture"
+	ErrFeeHistoryFixtureBlockHash  = "block with given hash wasn't recorded in fee history fixture"
+)
+
+// FeeHistoryFixturePercentiles are reward percentiles recorded in fee history fixtures. They cover percentiles used by
+// GasEstimator (99) and all priorities of FeeHistoryGasOracle
+var FeeHistoryFixturePercentiles = []float64{25, 50, 75, 99}
+
+// feeHistoryFixtureConcurrency is the maximum number of blocks fetched concurrently while recording a fixture
+const feeHistoryFixtureConcurrency = 10
+
+// FeeHistoryFixture contains everything gas estimation and block stats read from the node: blocks, eth_feeHistory, eth_gasPrice and
+// eth_maxPriorityFeePerGas responses of consecutive blocks. It's recorded from a live network and can be replayed
+// with NewFeeHistoryReplayClient to test gas estimation offline.
+type FeeHistoryFixture struct {
+	Network    string       `json:"network"`
+	ChainID    *hexutil.Big `json:"chain_id"`
+	RecordedAt time.Time    `json:"recorded_at"`
+	// Headers of recorded blocks, oldest first
+	Headers []*types.Header `json:"headers"`
+	// Transactions of each recorded block, so that whole blocks can be replayed
+	Transactions      [][]*types.Transaction `json:"transactions"`
+	RewardPercentiles []float64              `json:"reward_percentiles"`
+	// Reward contains tips at RewardPercentiles for each recorded block
+	Reward       [][]*hexutil.Big `json:"reward"`
+	GasUsedRatio []float64        `json:"gas_used_ratio"`
+	// BaseFee contains base fee of each recorded block and of the block following the latest one
+	BaseFee            []*hexutil.Big `json:"base_fee"`
+	SuggestedGasPrice  *hexutil.Big   `json:"suggested_gas_price"`
+	SuggestedGasTipCap *hexutil.Big   `json:"suggested_gas_tip_cap"`
+}
+
+// OldestBlock returns number of the oldest recorded block
+func (f *FeeHistoryFixture) OldestBlock() uint64 {
+	return f.Headers[0].Number.Uint64()
+}
+
+// LatestBlock returns number of the latest recorded block
+func (f *FeeHistoryFixture) LatestBlock() uint64 {
+	return f.Headers[len(f.Headers)-1].Number.Uint64()
+}
+
+// Validate checks that fixture contains fee history of each recorded header
+func (f *FeeHistoryFixture) Validate() error {
+	if f.ChainID == nil || f.SuggestedGasPrice == nil || f.SuggestedGasTipCap == nil {
+		return errors.Errorf("%s: chain id and suggested fees are required", ErrInvalidFeeHistoryFixture)
+	}
+	if len(f.Headers) == 0 || len(f.Transactions) != len(f.Headers) || len(f.Reward) != len(f.Headers) || len(f.GasUsedRatio) != len(f.Headers) || len(f.BaseFee) != len(f.Headers)+1 {
+		return errors.Errorf("%s: each header needs transactions, reward, gas used ratio and base fee, plus base fee of the next block", ErrInvalidFeeHistoryFixture)
+	}
+	for i, header := range f.Headers {
+		if header == nil || header.Number == nil || header.Number.Uint64() != f.OldestBlock()+uint64(i) {
+			return errors.Errorf("%s: headers must be consecutive, oldest first", ErrInvalidFeeHistoryFixture)
+		}
+		if (header.TxHash == types.EmptyTxsHash) != (len(f.Transactions[i]) == 0) {
+			return errors.Errorf("%s: block %d should have transactions matching its header", ErrInvalidFeeHistoryFixture, header.Number.Uint64())
+		}
+		if len(f.Reward[i]) != len(f.RewardPercentiles) {
+			return errors.Errorf("%s: block %d should have reward for each percentile", ErrInvalidFeeHistoryFixture, header.Number.Uint64())
+		}
+	}
+	return nil
+}
+
+// Save saves fixture as JSON file in given directory and returns its path
+func (f *FeeHistoryFixture) Save(dir string) (string, error) {
+	return saveAsJson(f, dir, fmt.Sprintf(FeeHistoryFixtureFilePattern, f.Network, f.LatestBlock()))
+}
+
+// LoadFeeHistoryFixture reads and validates fee history fixture saved with FeeHistoryFixture.Save
+func LoadFeeHistoryFixture(path string) (*FeeHistoryFixture, error) {
+	fixture := &FeeHistoryFixture{}
+	if err := OpenJsonFileAsStruct(path, fixture); err != nil {
+		return nil, err
+	}
+	if err := fixture.Validate(); err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+// RecordFeeHistoryFixture records blocks and fee history of given number of latest blocks (network's gas_price_estimation_blocks
+// if 0) together with current suggested gas price and tip
+func (m *Client) RecordFeeHistoryFixture(ctx context.Context, blocks uint64) (*FeeHistoryFixture, error) {
+	if blocks == 0 {
+		blocks = m.Cfg.Network.GasPriceEstimationBlocks
+	}
+
+	latest, err := m.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrFeeHistoryFixture)
+	}
+	if blocks == 0 || blocks > latest {
+		blocks = latest
+	}
+	oldest := latest - blocks + 1
+
+	L.Info().
+		Uint64("From", oldest).
+		Uint64("To", latest).
+		Msg("Recording fee history fixture")
+
+	fixture := &FeeHistoryFixture{
+		Network:           m.Cfg.Network.Name,
+		ChainID:           (*hexutil.Big)(big.NewInt(m.ChainID)),
+		RecordedAt:        time.Now(),
+		Headers:           make([]*types.Header, blocks),
+		Transactions:      make([][]*types.Transaction, blocks),
+		RewardPercentiles: FeeHistoryFixturePercentiles,
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(feeHistoryFixtureConcurrency)
+	for i := uint64(0); i < blocks; i++ {
+		i := i
+		eg.Go(func() error {
+			block, err := m.Client.BlockByNumber(egCtx, new(big.Int).SetUint64(oldest+i))
+			if err != nil {
+				return errors.Wrapf(err, "failed to get block %d", oldest+i)
+			}
+			fixture.Headers[i] = block.Header()
+			fixture.Transactions[i] = block.Transactions()
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, errors.Wrap(err, ErrFeeHistoryFixture)
+	}
+
+	for first := oldest; first <= latest; first += MaxFeeHistoryBlocks {
+		count := min(uint64(MaxFeeHistoryBlocks), latest-first+1)
+		history, err := m.Client.FeeHistory(ctx, count, new(big.Int).SetUint64(first+count-1), FeeHistoryFixturePercentiles)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrFeeHistoryFixture)
+		}
+		if history.OldestBlock == nil || history.OldestBlock.Uint64() != first || uint64(len(history.GasUsedRatio)) != count || uint64(len(history.BaseFee)) != count+1 {
+			return nil, errors.Wrap(errors.New("node returned fee history of fewer blocks than requested"), ErrFeeHistoryFixture)
+		}
+		for i := uint64(0); i < count; i++ {
+			fixture.Reward = append(fixture.Reward, toHexBigs(history.Reward[i]))
+			fixture.GasUsedRatio = append(fixture.GasUsedRatio, history.GasUsedRatio[i])
+			fixture.BaseFee = append(fixture.BaseFee, (*hexutil.Big)(history.BaseFee[i]))
+		}
+		if first+count-1 == latest {
+			fixture.BaseFee = append(fixture.BaseFee, (*hexutil.Big)(history.BaseFee[count]))
+		}
+	}
+
+	gasPrice, err := m.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrFeeHistoryFixture)
+	}
+	fixture.SuggestedGasPrice = (*hexutil.Big)(gasPrice)
+
+	gasTipCap, err := m.Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrFeeHistoryFixture)
+	}
+	fixture.SuggestedGasTipCap = (*hexutil.Big)(gasTipCap)
+
+	if err := fixture.Validate(); err != nil {
+		return nil, errors.Wrap(err, ErrFeeHistoryFixture)
+	}
+
+	return fixture, nil
+}
+
+func toHexBigs(values []*big.Int) []*hexutil.Big {
+	result := make([]*hexutil.Big, len(values))
+	for i, v := range values {
+		result[i] = (*hexutil.Big)(v)
+	}
+	return result
+}
+
+// NewFeeHistoryReplayClient returns a client, which serves blocks, fee history and suggested fees recorded in the fixture,
+// as if the latest recorded block was the chain head. Fee history can be requested only for recorded blocks and percentiles,
+// requests for older blocks are truncated to the oldest recorded block, just like a node with pruned history would do.
+func NewFeeHistoryReplayClient(fixture *FeeHistoryFixture) (*ethclient.Client, error) {
+	server, err := NewFeeHistoryReplayServer(fixture)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpc.DialInProc(server)), nil
+}
+
+// NewFeeHistoryReplayServer returns RPC server replaying the fixture like NewFeeHistoryReplayClient. It's an http.Handler, so it can be
+// served over HTTP and used as RPC URL of a Seth client
+func NewFeeHistoryReplayServer(fixture *FeeHistoryFixture) (*rpc.Server, error) {
+	if err := fixture.Validate(); err != nil {
+		return nil, err
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &feeHistoryReplay{fixture: fixture}); err != nil {
+		return nil, err
+	}
+	if err := server.RegisterName("net", &feeHistoryReplayNet{fixture: fixture}); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// feeHistoryReplay is the "eth" RPC namespace backed by fee history fixture
+type feeHistoryReplay struct {
+	fixture *FeeHistoryFixture
+}
+
+type feeHistoryReplayResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+func (r *feeHistoryReplay) ChainId() *hexutil.Big {
+	return r.fixture.ChainID
+}
+
+func (r *feeHistoryReplay) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(r.fixture.LatestBlock())
+}
+
+func (r *feeHistoryReplay) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	blockNumber, err := r.resolve(number)
+	if err != nil {
+		return nil, err
+	}
+	if blockNumber < r.fixture.OldestBlock() {
+		return nil, errors.Errorf("%s: %d", ErrFeeHistoryFixtureRange, blockNumber)
+	}
+	return r.block(blockNumber-r.fixture.OldestBlock(), fullTx)
+}
+
+func (r *feeHistoryReplay) GetBlockByHash(hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	for i, header := range r.fixture.Headers {
+		if header.Hash() == hash {
+			return r.block(uint64(i), fullTx)
+		}
+	}
+	return nil, errors.Errorf("%s: %s", ErrFeeHistoryFixtureBlockHash, hash.Hex())
+}
+
+// block returns recorded block in the same format as eth_getBlockByNumber, with either whole transactions or their hashes
+func (r *feeHistoryReplay) block(index uint64, fullTx bool) (map[string]interface{}, error) {
+	header := r.fixture.Headers[index]
+	block, err := jsonFields(header)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]interface{}, 0, len(r.fixture.Transactions[index]))
+	for i, tx := range r.fixture.Transactions[index] {
+		if !fullTx {
+			transactions = append(transactions, tx.Hash())
+			continue
+		}
+		fields, err := jsonFields(tx)
+		if err != nil {
+			return nil, err
+		}
+		fields["blockHash"] = header.Hash()
+		fields["blockNumber"] = (*hexutil.Big)(header.Number)
+		fields["transactionIndex"] = hexutil.Uint64(i)
+		transactions = append(transactions, fields)
+	}
+	block["transactions"] = transactions
+	block["uncles"] = []common.Hash{}
+	if header.WithdrawalsHash != nil {
+		block["withdrawals"] = []*types.Withdrawal{}
+	}
+
+	return block, nil
+}
+
+// jsonFields returns JSON fields of the value, so that more fields can be added to them
+func jsonFields(v interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	err = json.Unmarshal(encoded, &fields)
+	return fields, err
+}
+
+func (r *feeHistoryReplay) FeeHistory(blockCount hexutil.Uint, lastBlock rpc.BlockNumber, percentiles []float64) (*feeHistoryReplayResult, error) {
+	last, err := r.resolve(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]int, len(percentiles))
+	for i, p := range percentiles {
+		indexes[i] = slices.Index(r.fixture.RewardPercentiles, p)
+		if indexes[i] == -1 {
+			return nil, errors.Errorf("%s: %.2f", ErrFeeHistoryFixturePercentile, p)
+		}
+	}
+
+	oldest := r.fixture.OldestBlock()
+	if last < oldest {
+		return nil, errors.Errorf("%s: %d", ErrFeeHistoryFixtureRange, last)
+	}
+	first := oldest
+	if uint64(blockCount) <= last-oldest {
+		first = last - uint64(blockCount) + 1
+	}
+	from, to := first-oldest, last-oldest+1
+
+	result := &feeHistoryReplayResult{
+		OldestBlock:  (*hexutil.Big)(new(big.Int).SetUint64(first)),
+		GasUsedRatio: r.fixture.GasUsedRatio[from:to],
+		BaseFee:      r.fixture.BaseFee[from : to+1],
+	}
+	if len(percentiles) > 0 {
+		for _, rewards := range r.fixture.Reward[from:to] {
+			selected := make([]*hexutil.Big, len(indexes))
+			for i, idx := range indexes {
+				selected[i] = rewards[idx]
+			}
+			result.Reward = append(result.Reward, selected)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *feeHistoryReplay) GasPrice() *hexutil.Big {
+	return r.fixture.SuggestedGasPrice
+}
+
+func (r *feeHistoryReplay) MaxPriorityFeePerGas() *hexutil.Big {
+	return r.fixture.SuggestedGasTipCap
+}
+
+// resolve returns number of the requested block, treating latest recorded block as the chain head
+func (r *feeHistoryReplay) resolve(number rpc.BlockNumber) (uint64, error) {
+	switch {
+	case number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber:
+		return r.fixture.LatestBlock(), nil
+	case number < 0:
+		return 0, errors.Errorf("%s: %s", ErrFeeHistoryFixtureRange, number.String())
+	case uint64(number) > r.fixture.LatestBlock():
+		return 0, errors.Errorf("%s: %d", ErrFeeHistoryFixtureRange, number)
+	default:
+		return uint64(number), nil
+	}
+}
+
+// feeHistoryReplayNet is the "net" RPC namespace backed by fee history fixture
+type feeHistoryReplayNet struct {
+	fixture *FeeHistoryFixture
+}
+
+func (n *feeHistoryReplayNet) Version() string {
+	return n.fixture.ChainID.ToInt().String()
+}
@@ -0,0 +1,194 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const (
+	feeHistoryTestLatestBlock = 100
+	feeHistoryTestBlocks      = 20
+	feeHistoryTestGasLimit    = 30_000_000
+	feeHistoryTestBaseFee     = 10_000_000_000
+	feeHistoryTestTip         = 2_000_000_000
+	// feeHistoryRecordedFixture was recorded with RecordFeeHistoryFixture from a congested geth dev chain: 20 senders competed for
+	// blocks fitting 50 transfers with tips growing up to 13 gwei, so base fee grew from 2.5 to 23 gwei within the window
+	feeHistoryRecordedFixture = "testdata/fee_history_geth_dev_36.json"
+)
+
+// newFeeHistoryTestFixture returns synthetic fixture of blocks, which are all full, have the same base fee and rewards growing with percentile
+func newFeeHistoryTestFixture() *seth.FeeHistoryFixture {
+	fixture := &seth.FeeHistoryFixture{
+		Network:            "fee_history_test",
+		ChainID:            (*hexutil.Big)(big.NewInt(1337)),
+		RewardPercentiles:  seth.FeeHistoryFixturePercentiles,
+		SuggestedGasPrice:  (*hexutil.Big)(big.NewInt(feeHistoryTestBaseFee + feeHistoryTestTip)),
+		SuggestedGasTipCap: (*hexutil.Big)(big.NewInt(feeHistoryTestTip / 2)),
+	}
+	for bn := int64(feeHistoryTestLatestBlock - feeHistoryTestBlocks + 1); bn <= feeHistoryTestLatestBlock; bn++ {
+		fixture.Headers = append(fixture.Headers, &types.Header{
+			Number:     big.NewInt(bn),
+			Difficulty: big.NewInt(0),
+			GasLimit:   feeHistoryTestGasLimit,
+			GasUsed:    feeHistoryTestGasLimit,
+			BaseFee:    big.NewInt(feeHistoryTestBaseFee),
+			Time:       uint64(bn) * 12,
+			UncleHash:  types.EmptyUncleHash,
+			TxHash:     types.EmptyTxsHash,
+		})
+		var rewards []*hexutil.Big
+		for _, p := range fixture.RewardPercentiles {
+			rewards = append(rewards, (*hexutil.Big)(big.NewInt(int64(p)*feeHistoryTestTip/99)))
+		}
+		fixture.Transactions = append(fixture.Transactions, []*types.Transaction{})
+		fixture.Reward = append(fixture.Reward, rewards)
+		fixture.GasUsedRatio = append(fixture.GasUsedRatio, 1)
+		fixture.BaseFee = append(fixture.BaseFee, (*hexutil.Big)(big.NewInt(feeHistoryTestBaseFee)))
+	}
+	fixture.BaseFee = append(fixture.BaseFee, (*hexutil.Big)(big.NewInt(feeHistoryTestBaseFee)))
+	return fixture
+}
+
+func loadRecordedFeeHistoryFixture(t *testing.T) *seth.FeeHistoryFixture {
+	fixture, err := seth.LoadFeeHistoryFixture(feeHistoryRecordedFixture)
+	require.NoError(t, err, "failed to load recorded fixture")
+	return fixture
+}
+
+// newFeeHistoryReplaySethClient creates a client connected over HTTP to the server replaying the fixture
+func newFeeHistoryReplaySethClient(t *testing.T, fixture *seth.FeeHistoryFixture) *seth.Client {
+	server, err := seth.NewFeeHistoryReplayServer(fixture)
+	require.NoError(t, err, "failed to create replay server")
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+
+	c, err := seth.NewClientRaw(&seth.Config{
+		ArtifactsDir: t.TempDir(),
+		Network: &seth.Network{
+			Name:                      fixture.Network,
+			URLs:                      []string{httpServer.URL},
+			DialTimeout:               seth.MustMakeDuration(time.Second),
+			TxnTimeout:                seth.MustMakeDuration(5 * time.Second),
+			GasPriceEstimationEnabled: true,
+			GasPriceEstimationBlocks:  10,
+		},
+	}, nil, nil)
+	require.NoError(t, err, "failed to create client connected to replay server")
+	t.Cleanup(c.CancelFunc)
+
+	return c
+}
+
+func TestFeeHistoryFixtureIsRecordedSavedAndLoaded(t *testing.T) {
+	source := newFeeHistoryReplaySethClient(t, newFeeHistoryTestFixture())
+
+	recorded, err := source.RecordFeeHistoryFixture(context.Background(), 5)
+	require.NoError(t, err, "failed to record fixture")
+	require.Len(t, recorded.Headers, 5, "incorrect number of recorded headers")
+	require.Equal(t, uint64(96), recorded.OldestBlock(), "incorrect oldest block")
+	require.Equal(t, uint64(feeHistoryTestLatestBlock), recorded.LatestBlock(), "incorrect latest block")
+	require.Len(t, recorded.BaseFee, 6, "base fee of the next block should be recorded")
+	require.Equal(t, big.NewInt(feeHistoryTestTip), recorded.Reward[0][3].ToInt(), "incorrect 99th percentile reward")
+	require.Equal(t, big.NewInt(feeHistoryTestTip/2), recorded.SuggestedGasTipCap.ToInt(), "incorrect suggested tip")
+
+	path, err := recorded.Save(t.TempDir())
+	require.NoError(t, err, "failed to save fixture")
+	require.Contains(t, path, "fee_history_fee_history_test_100", "file name should contain network and latest block")
+
+	loaded, err := seth.LoadFeeHistoryFixture(path)
+	require.NoError(t, err, "failed to load fixture")
+	require.Equal(t, recorded.Headers[4].Hash(), loaded.Headers[4].Hash(), "headers should survive saving")
+	require.Equal(t, recorded.BaseFee, loaded.BaseFee, "base fees should survive saving")
+	require.Equal(t, recorded.Reward, loaded.Reward, "rewards should survive saving")
+
+	loaded.BaseFee = loaded.BaseFee[:5]
+	require.ErrorContains(t, loaded.Validate(), seth.ErrInvalidFeeHistoryFixture, "fixture without next base fee should be invalid")
+}
+
+func TestFeeHistoryReplayServesOnlyRecordedData(t *testing.T) {
+	c := newFeeHistoryReplaySethClient(t, newFeeHistoryTestFixture())
+	ctx := context.Background()
+
+	history, err := c.Client.FeeHistory(ctx, 50, nil, []float64{99, 25})
+	require.NoError(t, err, "failed to get fee history")
+	require.Equal(t, int64(feeHistoryTestLatestBlock-feeHistoryTestBlocks+1), history.OldestBlock.Int64(), "history should be truncated to recorded blocks")
+	require.Len(t, history.GasUsedRatio, feeHistoryTestBlocks, "incorrect number of blocks")
+	require.Equal(t, []*big.Int{big.NewInt(feeHistoryTestTip), big.NewInt(25 * feeHistoryTestTip / 99)}, history.Reward[0], "rewards should be in requested order")
+
+	_, err = c.Client.FeeHistory(ctx, 1, nil, []float64{10})
+	require.ErrorContains(t, err, seth.ErrFeeHistoryFixturePercentile, "percentile that wasn't recorded should be rejected")
+
+	_, err = c.Client.HeaderByNumber(ctx, big.NewInt(feeHistoryTestLatestBlock+1))
+	require.ErrorContains(t, err, seth.ErrFeeHistoryFixtureRange, "future block should be rejected")
+
+	chainID, err := c.Client.NetworkID(ctx)
+	require.NoError(t, err, "failed to get network id")
+	require.Equal(t, big.NewInt(1337), chainID, "incorrect network id")
+}
+
+func TestFeeHistoryReplayMakesGasEstimationDeterministic(t *testing.T) {
+	fixture := newFeeHistoryTestFixture()
+	for i := 0; i < 2; i++ {
+		c := newFeeHistoryReplaySethClient(t, fixture)
+
+		congestion, err := c.CalculateNetworkCongestionMetric(10, seth.CongestionStrategy_NewestFirst)
+		require.NoError(t, err, "failed to calculate congestion")
+		require.Equal(t, 1.0, congestion, "full blocks should have maximum congestion")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		feeCap, tipCap, err := c.GetSuggestedEIP1559Fees(ctx, seth.Priority_Standard)
+		cancel()
+		require.NoError(t, err, "failed to get suggested fees")
+		// historical tip (99th percentile) is higher than suggested one, both base fee and tip get extreme congestion buffer (1.4),
+		// which is applied with float multiplication, so results are truncated 1 wei below 2.8 and 14 gwei
+		require.Equal(t, big.NewInt(2_799_999_999), tipCap, "incorrect tip cap")
+		require.Equal(t, big.NewInt(16_799_999_998), feeCap, "incorrect fee cap")
+	}
+
+	oracle := seth.NewFeeHistoryGasOracle(newFeeHistoryReplaySethClient(t, fixture).Client, 10)
+	feeCap, tipCap, err := oracle.SuggestEIP1559Fees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to get fee history oracle fees")
+	require.Equal(t, big.NewInt(75*feeHistoryTestTip/99), tipCap, "tip should be 75th percentile")
+	require.Equal(t, big.NewInt(2*feeHistoryTestBaseFee+75*feeHistoryTestTip/99), feeCap, "fee cap should be twice next base fee plus tip")
+}
+
+func TestFeeHistoryReplayServesRecordedBlocks(t *testing.T) {
+	fixture := loadRecordedFeeHistoryFixture(t)
+	c := newFeeHistoryReplaySethClient(t, fixture)
+	ctx := context.Background()
+
+	var withTransactions int
+	for i, header := range fixture.Headers {
+		block, err := c.Client.BlockByNumber(ctx, header.Number)
+		require.NoError(t, err, "failed to get recorded block")
+		require.Equal(t, header.Hash(), block.Hash(), "block should have recorded header")
+		require.Len(t, block.Transactions(), len(fixture.Transactions[i]), "block should have recorded transactions")
+		for j, tx := range block.Transactions() {
+			require.Equal(t, fixture.Transactions[i][j].Hash(), tx.Hash(), "block should have recorded transactions in the same order")
+		}
+		if len(block.Transactions()) > 0 {
+			withTransactions++
+		}
+	}
+	require.Greater(t, withTransactions, 0, "recorded fixture should have blocks with transactions")
+
+	block, err := c.Client.BlockByHash(ctx, fixture.Headers[0].Hash())
+	require.NoError(t, err, "failed to get recorded block by hash")
+	require.Equal(t, fixture.OldestBlock(), block.NumberU64(), "incorrect block")
+
+	_, err = c.Client.BlockByHash(ctx, common.Hash{1})
+	require.ErrorContains(t, err, seth.ErrFeeHistoryFixtureBlockHash, "block that wasn't recorded should be rejected")
+}
@@ -79,18 +79,19 @@ func (m *GasEstimator) Stats(fromNumber uint64, priorityPerc float64) (GasSugges
 
 // GasPercentiles contains gas percentiles
 type GasPercentiles struct {
-	Max    float64
-	Perc99 float64
-	Perc75 float64
-	Perc50 float64
-	Perc25 float64
+	Max    float64 `toml:"max" json:"max"`
+	Perc99 float64 `toml:"perc_99" json:"perc_99"`
+	Perc75 float64 `toml:"perc_75" json:"perc_75"`
+	Perc50 float64 `toml:"perc_50" json:"perc_50"`
+	Perc25 float64 `toml:"perc_25" json:"perc_25"`
 }
 
+// GasSuggestions contains base fee (GasPrice) and priority fee (TipCap) percentiles from fee history together with gas price and tip cap suggested by the node
 type GasSuggestions struct {
-	GasPrice           *GasPercentiles
-	TipCap             *GasPercentiles
-	SuggestedGasPrice  *big.Int
-	SuggestedGasTipCap *big.Int
+	GasPrice           *GasPercentiles `toml:"gas_price" json:"gas_price"`
+	TipCap             *GasPercentiles `toml:"tip_cap" json:"tip_cap"`
+	SuggestedGasPrice  *big.Int        `toml:"suggested_gas_price" json:"suggested_gas_price"`
+	SuggestedGasTipCap *big.Int        `toml:"suggested_gas_tip_cap" json:"suggested_gas_tip_cap"`
 }
 
 // quantilesFromFloatArray calculates quantiles from a float array
@@ -88,6 +88,21 @@ func (m *Client) CalculateNetworkCongestionMetric(blocksNumber uint64, strategy
 	var headers []*types.Header
 	headers = append(headers, lastBlock)
 
+	// headers already in the cache (e.g. added by background gas oracle with each new head) are used as they are,
+	// so that only headers that entered the window since the last calculation are fetched
+	var missing []int64
+	for i := lastBlockNumber; i > lastBlockNumber-blocksNumber; i-- {
+		// better safe than sorry (might happen for brand-new chains)
+		if i <= 1 {
+			break
+		}
+		if header, ok := m.HeaderCache.Get(int64(i)); ok {
+			headers = append(headers, header)
+			continue
+		}
+		missing = append(missing, int64(i))
+	}
+
 	var wg sync.WaitGroup
 	dataCh := make(chan *types.Header)
 
@@ -100,28 +115,24 @@ func (m *Client) CalculateNetworkCongestionMetric(blocksNumber uint64, strategy
 	}()
 
 	startTime := time.Now()
-	for i := lastBlockNumber; i > lastBlockNumber-blocksNumber; i-- {
-		// better safe than sorry (might happen for brand-new chains)
-		if i <= 1 {
-			break
-		}
-
+	for _, i := range missing {
 		wg.Add(1)
 		go func(bn *big.Int) {
 			header, err := getHeaderData(bn)
 			if err != nil {
 				L.Error().Err(err).Msgf("Failed to get block %d header", bn.Int64())
+				wg.Done()
 				return
 			}
 			dataCh <- header
-		}(big.NewInt(int64(i)))
+		}(big.NewInt(i))
 	}
 
 	wg.Wait()
 	close(dataCh)
 
 	endTime := time.Now()
-	L.Debug().Msgf("Time to fetch %d block headers: %v", blocksNumber, endTime.Sub(startTime))
+	L.Debug().Msgf("Time to fetch %d missing out of %d block headers: %v", len(missing), blocksNumber, endTime.Sub(startTime))
 
 	minBlockCount := int(float64(blocksNumber) * 0.8)
 	if len(headers) < minBlockCount {
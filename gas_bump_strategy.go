@@ -0,0 +1,237 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GasBumpStrategy_Priority bumps fees by percentage based on gas_price_estimation_tx_priority (see PriorityBasedGasBumpingStrategyFn)
+	GasBumpStrategy_Priority = "priority"
+	// GasBumpStrategy_Linear adds linear_step_percent of the original fee with every bump
+	GasBumpStrategy_Linear = "linear"
+	// GasBumpStrategy_Exponential multiplies previous fee by exponential_factor
+	GasBumpStrategy_Exponential = "exponential"
+	// GasBumpStrategy_Fixed adds fixed_increment wei to previous fee
+	GasBumpStrategy_Fixed = "fixed"
+	// GasBumpStrategy_Network uses current network estimate from the gas oracle
+	GasBumpStrategy_Network = "network"
+)
+
+const (
+	GasBumpField_GasPrice   = "gas price"
+	GasBumpField_GasFeeCap  = "gas fee cap"
+	GasBumpField_GasTipCap  = "gas tip cap"
+	GasBumpField_BlobFeeCap = "blob fee cap"
+)
+
+const (
+	DefaultGasBumpLinearStepPercent = 20
+	DefaultGasBumpExponentialFactor = 1.5
+	DefaultGasBumpFixedIncrement    = 1_000_000_000 // 1 gwei
+	// MaxUnderpricedReplacementBumps is how many more times we bump fees, when node rejects replacement transaction as underpriced
+	MaxUnderpricedReplacementBumps = 3
+)
+
+const (
+	ErrUnknownGasBumpStrategy = "gas bump strategy must be one of: priority, linear, exponential, fixed, network"
+	ErrGasBumpCapReached      = "bumped fee would exceed its cap"
+)
+
+// validateGasBumpConfig normalises declarative gas bump strategy settings and sets defaults
+func validateGasBumpConfig(cfg *GasBumpConfig) error {
+	if cfg == nil {
+		return nil
+	}
+
+	cfg.Strategy = strings.ToLower(cfg.Strategy)
+	switch cfg.Strategy {
+	case "", GasBumpStrategy_Priority, GasBumpStrategy_Network:
+	case GasBumpStrategy_Linear:
+		if cfg.LinearStepPercent < 0 {
+			return errors.New("gas bump linear_step_percent must be greater than 0")
+		}
+		if cfg.LinearStepPercent == 0 {
+			cfg.LinearStepPercent = DefaultGasBumpLinearStepPercent
+		}
+	case GasBumpStrategy_Exponential:
+		if cfg.ExponentialFactor != 0 && cfg.ExponentialFactor <= 1 {
+			return errors.New("gas bump exponential_factor must be greater than 1")
+		}
+		if cfg.ExponentialFactor == 0 {
+			cfg.ExponentialFactor = DefaultGasBumpExponentialFactor
+		}
+	case GasBumpStrategy_Fixed:
+		if cfg.FixedIncrement < 0 {
+			return errors.New("gas bump fixed_increment must be greater than 0")
+		}
+		if cfg.FixedIncrement == 0 {
+			cfg.FixedIncrement = DefaultGasBumpFixedIncrement
+		}
+	default:
+		return errors.New(ErrUnknownGasBumpStrategy)
+	}
+
+	if cfg.MaxGasTipCap < 0 || cfg.MaxGasFeeCap < 0 || cfg.MaxBlobFeeCap < 0 {
+		return errors.New("gas bump caps must not be negative")
+	}
+
+	return nil
+}
+
+// HasDeclarativeStrategy returns true if gas bumping uses one of the strategies configured in TOML instead of a strategy function
+func (c *GasBumpConfig) HasDeclarativeStrategy() bool {
+	return c != nil && c.StrategyFn == nil && c.Strategy != "" && c.Strategy != GasBumpStrategy_Priority
+}
+
+// cap returns configured cap for given fee or nil, if it's not set
+func (c *GasBumpConfig) cap(field string) *big.Int {
+	if c == nil {
+		return nil
+	}
+	var value int64
+	switch field {
+	case GasBumpField_GasTipCap:
+		value = c.MaxGasTipCap
+	case GasBumpField_GasFeeCap:
+		value = c.MaxGasFeeCap
+	case GasBumpField_BlobFeeCap:
+		value = c.MaxBlobFeeCap
+	}
+	if value == 0 {
+		return nil
+	}
+	return big.NewInt(value)
+}
+
+// minimumReplacementFee returns fee increased by MinReplacementGasBumpPercent (rounded up), which is the minimum nodes accept for replacements
+func minimumReplacementFee(previous *big.Int) *big.Int {
+	minimum := new(big.Int).Mul(previous, big.NewInt(100+MinReplacementGasBumpPercent))
+	minimum.Add(minimum, big.NewInt(99))
+	return minimum.Div(minimum, big.NewInt(100))
+}
+
+// maxBig returns the bigger of two values, nil values are ignored
+func maxBig(a, b *big.Int) *big.Int {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+type replacementFeeKey struct {
+	trackedTxKey
+	field string
+}
+
+// originalFee returns the first fee seen for given transaction and field (when it was bumped for the first time), so that linear strategy can use it
+func (m *Client) originalFee(key trackedTxKey, field string, current *big.Int) *big.Int {
+	original, _ := m.replacementFeeOrigins.LoadOrStore(replacementFeeKey{trackedTxKey: key, field: field}, new(big.Int).Set(current))
+	return original.(*big.Int)
+}
+
+// forgetOriginalFees removes original fees remembered for the nonce, once it was mined or we stopped replacing it
+func (m *Client) forgetOriginalFees(from common.Address, nonce uint64) {
+	key := trackedTxKey{from: from, nonce: nonce}
+	for _, field := range []string{GasBumpField_GasPrice, GasBumpField_GasFeeCap, GasBumpField_GasTipCap, GasBumpField_BlobFeeCap} {
+		m.replacementFeeOrigins.Delete(replacementFeeKey{trackedTxKey: key, field: field})
+	}
+}
+
+// bumpFee returns bumped value of a single fee of a replacement transaction. If strategy function is given, it's used, otherwise
+// declarative strategy from gas bump config is used and the result is never lower than what nodes accept as a replacement.
+// Minimum, if not nil, is the lowest acceptable value (e.g. doubled fee for blob transactions). Bumped fee is capped
+// by the cap configured for that fee; if the cap is lower than the minimum, an error is returned.
+func (m *Client) bumpFee(key trackedTxKey, field string, previous, minimum *big.Int, strategy GasBumpStrategyFn) (*big.Int, error) {
+	var bumped *big.Int
+	if strategy != nil {
+		bumped = strategy(new(big.Int).Set(previous))
+	} else {
+		minimum = maxBig(minimum, minimumReplacementFee(previous))
+		bumped = m.declarativeBump(key, field, previous)
+	}
+	bumped = maxBig(bumped, minimum)
+
+	if maxFee := m.Cfg.GasBump.cap(field); maxFee != nil && bumped.Cmp(maxFee) > 0 {
+		if minimum != nil && minimum.Cmp(maxFee) > 0 {
+			return nil, fmt.Errorf("%s: %s %s > %s", ErrGasBumpCapReached, field, minimum.String(), maxFee.String())
+		}
+		L.Debug().Str("Fee", field).Str("Bumped", bumped.String()).Str("Cap", maxFee.String()).Msg("Bumped fee exceeds its cap, using the cap")
+		bumped = maxFee
+	}
+
+	return bumped, nil
+}
+
+// declarativeBump bumps the fee using strategy configured in TOML
+func (m *Client) declarativeBump(key trackedTxKey, field string, previous *big.Int) *big.Int {
+	cfg := m.Cfg.GasBump
+	if cfg == nil {
+		return new(big.Int).Set(previous)
+	}
+
+	switch cfg.Strategy {
+	case GasBumpStrategy_Linear:
+		original := m.originalFee(key, field, previous)
+		step := new(big.Int).Mul(original, big.NewInt(cfg.LinearStepPercent))
+		return step.Div(step, big.NewInt(100)).Add(step, previous)
+	case GasBumpStrategy_Exponential:
+		bumped, _ := new(big.Float).Mul(new(big.Float).SetInt(previous), big.NewFloat(cfg.ExponentialFactor)).Int(nil)
+		return bumped
+	case GasBumpStrategy_Fixed:
+		return new(big.Int).Add(previous, big.NewInt(cfg.FixedIncrement))
+	case GasBumpStrategy_Network:
+		estimate, err := m.networkFeeEstimate(field)
+		if err != nil {
+			L.Warn().Err(err).Str("Fee", field).Msg("Failed to get current network estimate for gas bumping. Using minimum replacement fee")
+			return new(big.Int).Set(previous)
+		}
+		return estimate
+	default:
+		return PriorityBasedGasBumpingStrategyFn(m.Cfg.Network.GasPriceEstimationTxPriority)(new(big.Int).Set(previous))
+	}
+}
+
+// networkFeeEstimate returns current estimate of given fee
+func (m *Client) networkFeeEstimate(field string) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+
+	if field == GasBumpField_BlobFeeCap {
+		return m.SuggestBlobFeeCap(ctx)
+	}
+	if m.GasOracle == nil {
+		return nil, errors.New("gas oracle is not set")
+	}
+
+	priority := m.Cfg.Network.GasPriceEstimationTxPriority
+	if priority == "" {
+		priority = Priority_Standard
+	}
+
+	if field == GasBumpField_GasPrice {
+		return m.gasOracle().SuggestLegacyFees(ctx, priority)
+	}
+
+	gasFeeCap, gasTipCap, err := m.gasOracle().SuggestEIP1559Fees(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	if field == GasBumpField_GasTipCap {
+		return gasTipCap, nil
+	}
+	return gasFeeCap, nil
+}
+
+// isReplacementUnderpriced returns true if node rejected replacement transaction, because its fees were not bumped enough
+func isReplacementUnderpriced(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "replacement transaction underpriced")
+}
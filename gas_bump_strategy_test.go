@@ -0,0 +1,121 @@
+package seth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func pendingLegacyTx(t *testing.T, c *seth.Client, node *fakeNode, gasPrice int64) *types.Transaction {
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	return signPending(t, c, node, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(gasPrice), Gas: 21_000, To: &to, Value: big.NewInt(0)})
+}
+
+func pendingDynamicFeeTx(t *testing.T, c *seth.Client, node *fakeNode, gasFeeCap, gasTipCap int64) *types.Transaction {
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	return signPending(t, c, node, &types.DynamicFeeTx{ChainID: big.NewInt(c.ChainID), Nonce: 1, GasFeeCap: big.NewInt(gasFeeCap), GasTipCap: big.NewInt(gasTipCap), Gas: 21_000, To: &to, Value: big.NewInt(0)})
+}
+
+func TestGasBumpStrategyValidation(t *testing.T) {
+	// config is validated when the client is created
+	c := newFakeNodeClient(t, newFakeNode(t), withGasBump(&seth.GasBumpConfig{Strategy: "Exponential"}))
+	require.Equal(t, seth.GasBumpStrategy_Exponential, c.Cfg.GasBump.Strategy, "strategy should be lowercased")
+	require.Equal(t, seth.DefaultGasBumpExponentialFactor, c.Cfg.GasBump.ExponentialFactor, "default factor should be set")
+
+	c.Cfg.GasBump = &seth.GasBumpConfig{Strategy: "random"}
+	require.EqualError(t, seth.ValidateConfig(c.Cfg), seth.ErrUnknownGasBumpStrategy, "unknown strategy should be rejected")
+
+	c.Cfg.GasBump = &seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Exponential, ExponentialFactor: 0.5}
+	require.Error(t, seth.ValidateConfig(c.Cfg), "factor lower than 1 should be rejected")
+}
+
+func TestGasBumpStrategyLinearAddsStepOfOriginalFee(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Linear, LinearStepPercent: 20}))
+	tx := pendingLegacyTx(t, c, node, 1_000)
+
+	replacement, err := c.SpeedUp(tx, nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(1_200), replacement.GasPrice(), "first bump should add 20% of the original fee")
+
+	replacement, err = c.SpeedUp(replacement, nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(1_400), replacement.GasPrice(), "second bump should add 20% of the original fee")
+}
+
+func TestGasBumpStrategyExponentialAndFixedRespectReplacementMinimum(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Exponential, ExponentialFactor: 2}))
+	replacement, err := c.SpeedUp(pendingDynamicFeeTx(t, c, node, 1_000, 100), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(2_000), replacement.GasFeeCap(), "fee cap should be doubled")
+	require.Equal(t, big.NewInt(200), replacement.GasTipCap(), "tip cap should be doubled")
+
+	node = newFakeNode(t)
+	c = newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Fixed, FixedIncrement: 5}))
+	replacement, err = c.SpeedUp(pendingDynamicFeeTx(t, c, node, 1_000, 100), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(1_100), replacement.GasFeeCap(), "fee cap should be bumped by at least 10%")
+	require.Equal(t, big.NewInt(110), replacement.GasTipCap(), "tip cap should be bumped by at least 10%")
+}
+
+func TestGasBumpStrategyNetworkFollowsGasOracle(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Network}))
+	c.GasOracle = seth.NewFixedGasOracle(1, 5_000, 105)
+
+	replacement, err := c.SpeedUp(pendingDynamicFeeTx(t, c, node, 1_000, 100), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(5_000), replacement.GasFeeCap(), "fee cap should follow network estimate")
+	require.Equal(t, big.NewInt(110), replacement.GasTipCap(), "tip cap lower than replacement minimum should be raised")
+}
+
+func TestGasBumpStrategyCapsFees(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Exponential, ExponentialFactor: 3, MaxGasFeeCap: 2_500, MaxGasTipCap: 150}))
+	replacement, err := c.SpeedUp(pendingDynamicFeeTx(t, c, node, 1_000, 100), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(2_500), replacement.GasFeeCap(), "fee cap should be capped")
+	require.Equal(t, big.NewInt(150), replacement.GasTipCap(), "tip cap should be capped")
+
+	_, err = c.SpeedUp(replacement, nil)
+	require.ErrorContains(t, err, seth.ErrGasBumpCapReached, "cap lower than replacement minimum should stop bumping")
+}
+
+func TestGasBumpStrategyBumpsAgainWhenReplacementIsUnderpriced(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Fixed, FixedIncrement: 500}))
+	node.underpriced = 2
+
+	replacement, err := c.SpeedUp(pendingLegacyTx(t, c, node, 1_000), nil)
+	require.NoError(t, err, "underpriced replacement should be bumped again")
+	require.Len(t, node.sentTxs(), 1, "only accepted replacement should be recorded")
+	require.Equal(t, big.NewInt(2_500), replacement.GasPrice(), "fee should be bumped once per rejection")
+
+	node.underpriced = seth.MaxUnderpricedReplacementBumps + 1
+	_, err = c.SpeedUp(replacement, nil)
+	require.ErrorContains(t, err, "replacement transaction underpriced", "we should give up after max extra bumps")
+}
+
+func TestGasBumpStrategyForgetsOriginalFeeOnceNonceIsMined(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Linear, LinearStepPercent: 20}))
+	replacement, err := c.SpeedUp(pendingLegacyTx(t, c, node, 1_000), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(1_200), replacement.GasPrice(), "first bump should add 20% of the original fee")
+
+	node.mine(t, replacement, types.ReceiptStatusSuccessful)
+	_, err = c.SpeedUp(replacement, nil)
+	require.EqualError(t, err, seth.ErrTxNotPending, "mined transaction should not be replaced")
+
+	// after a reorg the same nonce is used by a different transaction, which shouldn't be bumped using the fee of the mined one
+	node.setLatestNonce(c.Addresses[0], 1)
+	replacement, err = c.SpeedUp(pendingLegacyTx(t, c, node, 5_000), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(6_000), replacement.GasPrice(), "step should be based on the fee of the new transaction")
+}
@@ -622,3 +622,15 @@ func TestGasBumping_Contract_Interaction_Legacy_FailedBumping(t *testing.T) {
 	require.Error(t, err, "did not fail to transfer tokens, even though gas bumping is disabled")
 	require.Equal(t, 3, gasBumps, "expected 2 gas bumps")
 }
+
+func TestGasBumping_NetworkStrategyFollowsRecordedCongestion(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Strategy: seth.GasBumpStrategy_Network}))
+	// estimations come from the recorded congested window, values are the same as in TestGasEstimationOfRecordedCongestion
+	c.GasOracle = newFeeHistoryReplaySethClient(t, loadRecordedFeeHistoryFixture(t)).GasOracle
+
+	replacement, err := c.SpeedUp(pendingDynamicFeeTx(t, c, node, 2_000_000_000, 1_000_000_000), nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(33_455_314_245), replacement.GasFeeCap(), "fee cap should follow standard priority estimate")
+	require.Equal(t, big.NewInt(10_400_000_000), replacement.GasTipCap(), "tip cap should follow standard priority estimate")
+}
@@ -0,0 +1,281 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultGasLimitMultiplier = 1.2
+	// GasLimitOverride_Deployment is the key of gas limit override used for all contract deployments
+	GasLimitOverride_Deployment = "constructor"
+	// blockGasLimitRefreshInterval is how long block gas limit used to clamp gas limits is cached for
+	blockGasLimitRefreshInterval = time.Minute
+)
+
+const (
+	ErrGasLimitMultiplier = "gas limit multiplier must be greater than or equal to 1"
+)
+
+// GasLimitEstimationConfig controls how gas limit estimated by eth_estimateGas is adjusted before transaction is signed.
+// It's used only for transactions without gas limit set (neither in network config nor in transaction options).
+type GasLimitEstimationConfig struct {
+	Enabled bool `toml:"enabled"`
+	// Multiplier is applied to estimated gas limit
+	Multiplier float64 `toml:"multiplier"`
+	// Overrides sets fixed gas limit for methods. Keys can be "Contract.method", method signature ("set(uint256)"), method name ("set") or "constructor" for deployments
+	Overrides map[string]uint64 `toml:"overrides"`
+}
+
+// GasLimitEstimationEnabled returns true if gas limit estimation is enabled
+func (c *Config) GasLimitEstimationEnabled() bool {
+	return c.GasLimitEstimation != nil && c.GasLimitEstimation.Enabled
+}
+
+// validateGasLimitEstimationConfig sets default multiplier and checks that it won't decrease the estimate
+func validateGasLimitEstimationConfig(cfg *GasLimitEstimationConfig) error {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	if cfg.Multiplier == 0 {
+		cfg.Multiplier = DefaultGasLimitMultiplier
+	}
+	if cfg.Multiplier < 1 {
+		return errors.New(ErrGasLimitMultiplier)
+	}
+	return nil
+}
+
+// gasLimitEstimate is the gas limit Seth set for a transaction, kept until the transaction is mined, so that it can be compared with gas used
+type gasLimitEstimate struct {
+	method    string
+	estimated uint64
+	gasLimit  uint64
+	signedAt  time.Time
+}
+
+// blockGasLimitCache keeps gas limit of the latest block, so that it isn't fetched for every signed transaction
+type blockGasLimitCache struct {
+	mu        sync.Mutex
+	gasLimit  uint64
+	fetchedAt time.Time
+}
+
+// gasLimitSigner wraps signer function, so that gas limit estimated by eth_estimateGas is multiplied (or replaced with an override for the method)
+// and clamped at the block gas limit before signing. Transactions with gas limit set in options are signed as they are.
+func (m *Client) gasLimitSigner(opts *bind.TransactOpts, signerFn bind.SignerFn) bind.SignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if opts.GasLimit != 0 {
+			return signerFn(from, tx)
+		}
+
+		methodKeys := m.methodKeys(tx)
+		gasLimit := m.adjustGasLimit(tx.Gas(), methodKeys)
+
+		var method string
+		if len(methodKeys) > 0 {
+			method = methodKeys[0]
+		}
+		m.forgetExpiredGasLimitEstimates()
+		m.gasLimitEstimates.Store(trackedTxKey{from: from, nonce: tx.Nonce()}, gasLimitEstimate{
+			method:    method,
+			estimated: tx.Gas(),
+			gasLimit:  gasLimit,
+			signedAt:  time.Now(),
+		})
+
+		if gasLimit == tx.Gas() {
+			return signerFn(from, tx)
+		}
+
+		adjusted, err := withGasLimit(tx, gasLimit)
+		if err != nil {
+			L.Warn().Err(err).Msg("Failed to adjust gas limit. Sending transaction with estimated gas limit")
+			return signerFn(from, tx)
+		}
+
+		return signerFn(from, adjusted)
+	}
+}
+
+// adjustGasLimit returns override for the first matching method key or estimated gas limit multiplied by the multiplier.
+// Result is never higher than the gas limit of the latest block (cached for blockGasLimitRefreshInterval).
+func (m *Client) adjustGasLimit(estimated uint64, methodKeys []string) uint64 {
+	cfg := m.Cfg.GasLimitEstimation
+	gasLimit := estimated
+	if cfg.Multiplier > 1 {
+		gasLimit = uint64(math.Ceil(float64(estimated) * cfg.Multiplier))
+	}
+
+	for _, key := range methodKeys {
+		if override, ok := cfg.Overrides[key]; ok {
+			L.Debug().Str("Method", key).Uint64("Gas limit", override).Msg("Using gas limit override")
+			gasLimit = override
+			break
+		}
+	}
+
+	blockGasLimit, err := m.latestBlockGasLimit()
+	if err != nil {
+		L.Warn().Err(err).Msg("Failed to get latest block. Gas limit won't be clamped at block gas limit")
+		return gasLimit
+	}
+
+	if gasLimit > blockGasLimit {
+		L.Warn().
+			Uint64("Gas limit", gasLimit).
+			Uint64("Block gas limit", blockGasLimit).
+			Msg("Gas limit is higher than block gas limit. Using block gas limit")
+		gasLimit = blockGasLimit
+	}
+
+	L.Debug().
+		Uint64("Estimated", estimated).
+		Uint64("Gas limit", gasLimit).
+		Msg("Adjusted estimated gas limit")
+
+	return gasLimit
+}
+
+// latestBlockGasLimit returns gas limit of the latest block, fetching it only if the cached one is older than blockGasLimitRefreshInterval
+func (m *Client) latestBlockGasLimit() (uint64, error) {
+	m.blockGasLimit.mu.Lock()
+	defer m.blockGasLimit.mu.Unlock()
+	if m.blockGasLimit.gasLimit != 0 && time.Since(m.blockGasLimit.fetchedAt) < blockGasLimitRefreshInterval {
+		return m.blockGasLimit.gasLimit, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+	header, err := m.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	m.blockGasLimit.gasLimit = header.GasLimit
+	m.blockGasLimit.fetchedAt = time.Now()
+
+	return header.GasLimit, nil
+}
+
+// forgetExpiredGasLimitEstimates removes gas limits of transactions, which were signed earlier than Seth would wait for them to be mined
+// (including all gas bump retries). They were either never decoded or won't ever be, so there's nothing to compare them with.
+func (m *Client) forgetExpiredGasLimitEstimates() {
+	ttl := m.Cfg.Network.TxnTimeout.Duration() * time.Duration(m.Cfg.GasBumpRetries()+1)
+	m.gasLimitEstimates.Range(func(key, value interface{}) bool {
+		if time.Since(value.(gasLimitEstimate).signedAt) > ttl {
+			m.gasLimitEstimates.Delete(key)
+		}
+		return true
+	})
+}
+
+// forgetGasLimitEstimate removes gas limit set for the nonce, once we stopped waiting for it to be mined
+func (m *Client) forgetGasLimitEstimate(from common.Address, nonce uint64) {
+	m.gasLimitEstimates.Delete(trackedTxKey{from: from, nonce: nonce})
+}
+
+// methodKeys returns keys, which can be used to override gas limit for the method called by the transaction, from the most to the least specific one
+func (m *Client) methodKeys(tx *types.Transaction) []string {
+	if tx.To() == nil {
+		return []string{GasLimitOverride_Deployment}
+	}
+	if len(tx.Data()) < 4 || m.ABIFinder == nil || m.ABIFinder.ContractStore == nil {
+		return nil
+	}
+
+	result, err := m.ABIFinder.FindABIByMethod(tx.To().Hex(), tx.Data()[:4])
+	if err != nil {
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("%s.%s", result.ContractName(), result.Method.Name),
+		result.Method.Sig,
+		result.Method.Name,
+	}
+}
+
+// logGasLimitEstimate logs gas limit set by Seth next to gas used by the mined transaction, so that the multiplier can be tuned.
+// If receipt is nil, it's fetched only when Seth estimated gas limit of the transaction.
+func (m *Client) logGasLimitEstimate(tx *types.Transaction, receipt *types.Receipt) {
+	if !m.Cfg.GasLimitEstimationEnabled() || tx == nil {
+		return
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return
+	}
+	stored, ok := m.gasLimitEstimates.LoadAndDelete(trackedTxKey{from: from, nonce: tx.Nonce()})
+	if !ok {
+		return
+	}
+	estimate := stored.(gasLimitEstimate)
+
+	if receipt == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+		defer cancel()
+		receipt, err = m.Client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			L.Debug().Err(err).Str("TxHash", tx.Hash().Hex()).Msg("Failed to get receipt. Can't compare gas limit estimate with gas used")
+			return
+		}
+	}
+
+	L.Info().
+		Str("TxHash", tx.Hash().Hex()).
+		Str("Method", estimate.method).
+		Uint64("Estimated", estimate.estimated).
+		Uint64("Gas limit", estimate.gasLimit).
+		Uint64("Gas used", receipt.GasUsed).
+		Str("Gas used/estimated", fmt.Sprintf("%.2f", float64(receipt.GasUsed)/float64(estimate.estimated))).
+		Msg("Gas limit estimate")
+}
+
+// withGasLimit returns copy of unsigned transaction with different gas limit
+func withGasLimit(tx *types.Transaction, gasLimit uint64) (*types.Transaction, error) {
+	switch tx.Type() {
+	case types.LegacyTxType:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: tx.GasPrice(),
+			Gas:      gasLimit,
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), nil
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasPrice:   tx.GasPrice(),
+			Gas:        gasLimit,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	case types.DynamicFeeTxType:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tx.GasTipCap(),
+			GasFeeCap:  tx.GasFeeCap(),
+			Gas:        gasLimit,
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	default:
+		return nil, errors.Errorf("unsupported tx type %d", tx.Type())
+	}
+}
@@ -0,0 +1,124 @@
+package seth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const gasLimitStoreABI = `[{"inputs":[{"internalType":"uint256","name":"x","type":"uint256"}],"name":"set","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"reset","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+const (
+	gasLimitTestEstimate      = 50_000
+	gasLimitTestBlockGasLimit = 100_000
+)
+
+var gasLimitStoreAddress = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+// withGasLimitStore deploys the store and makes the fake node estimate gasLimitTestEstimate gas for any call
+func withGasLimitStore() fakeNodeClientOption {
+	return fakeNodeClientOption{
+		node: func(n *fakeNode) {
+			n.gasEstimate = gasLimitTestEstimate
+			n.blockGasLimit = gasLimitTestBlockGasLimit
+			n.code[gasLimitStoreAddress] = hexutil.Bytes{0x60}
+		},
+		client: func(t *testing.T, c *seth.Client) {
+			withABI("Store", gasLimitStoreABI).client(t, c)
+			withContract(gasLimitStoreAddress, "Store").client(t, c)
+		},
+	}
+}
+
+// boundGasLimitStore binds the store added with withGasLimitStore
+func boundGasLimitStore(t *testing.T, c *seth.Client) *bind.BoundContract {
+	storeABI, ok := c.ContractStore.GetABI("Store")
+	require.True(t, ok, "store ABI not found")
+	return bind.NewBoundContract(gasLimitStoreAddress, *storeABI, c.Client, c.Client, c.Client)
+}
+
+func TestGasLimitEstimationMultipliesEstimate(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasLimitStore(), withGasLimitEstimation(&seth.GasLimitEstimationConfig{Enabled: true}))
+	store := boundGasLimitStore(t, c)
+	require.Equal(t, seth.DefaultGasLimitMultiplier, c.Cfg.GasLimitEstimation.Multiplier, "default multiplier should be set")
+
+	_, err := store.Transact(c.NewTXOpts(), "set", big.NewInt(1))
+	require.NoError(t, err, "failed to send transaction")
+	require.Len(t, node.sentTxs(), 1, "one transaction should be sent")
+	require.Equal(t, uint64(60_000), node.sentTxs()[0].Gas(), "estimate should be multiplied")
+
+	_, err = store.Transact(c.NewTXOpts(seth.WithGasLimit(30_000)), "set", big.NewInt(1))
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, uint64(30_000), node.sentTxs()[1].Gas(), "gas limit set in options should not be changed")
+}
+
+func TestGasLimitEstimationUsesMostSpecificOverride(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasLimitStore(), withGasLimitEstimation(&seth.GasLimitEstimationConfig{
+		Enabled:    true,
+		Multiplier: 1.5,
+		Overrides: map[string]uint64{
+			"set":          70_000,
+			"Store.set":    80_000,
+			"reset()":      90_000,
+			"constructor":  1_000_000,
+			"Other.reset":  10_000,
+			"unknown(int)": 10_000,
+		},
+	}))
+	store := boundGasLimitStore(t, c)
+
+	_, err := store.Transact(c.NewTXOpts(), "set", big.NewInt(1))
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, uint64(80_000), node.sentTxs()[0].Gas(), "contract method override should be used")
+
+	_, err = store.Transact(c.NewTXOpts(), "reset")
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, uint64(90_000), node.sentTxs()[1].Gas(), "method signature override should be used")
+
+	c.Cfg.GasLimitEstimation.Overrides = nil
+	_, err = store.Transact(c.NewTXOpts(), "reset")
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, uint64(75_000), node.sentTxs()[2].Gas(), "estimate should be multiplied without override")
+}
+
+func TestGasLimitEstimationIsClampedAtBlockGasLimit(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasLimitStore(), withGasLimitEstimation(&seth.GasLimitEstimationConfig{Enabled: true, Multiplier: 3}))
+	store := boundGasLimitStore(t, c)
+
+	_, err := store.Transact(c.NewTXOpts(), "set", big.NewInt(1))
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, uint64(gasLimitTestBlockGasLimit), node.sentTxs()[0].Gas(), "gas limit should not exceed block gas limit")
+
+	c.Cfg.GasLimitEstimation.Multiplier = 0.5
+	require.EqualError(t, seth.ValidateConfig(c.Cfg), seth.ErrGasLimitMultiplier, "multiplier lower than 1 should be rejected")
+}
+
+func TestGasLimitEstimationCachesBlockGasLimit(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasLimitStore(), withGasLimitEstimation(&seth.GasLimitEstimationConfig{Enabled: true, Multiplier: 3}))
+	store := boundGasLimitStore(t, c)
+
+	_, err := store.Transact(c.NewTXOpts(), "set", big.NewInt(1))
+	require.NoError(t, err, "failed to send transaction")
+	node.mu.Lock()
+	blockCalls := node.blockCalls
+	node.blockGasLimit = gasLimitTestBlockGasLimit * 2
+	node.mu.Unlock()
+
+	_, err = store.Transact(c.NewTXOpts(), "set", big.NewInt(2))
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, uint64(gasLimitTestBlockGasLimit), node.sentTxs()[1].Gas(), "cached block gas limit should be used")
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	require.Equal(t, blockCalls, node.blockCalls, "latest block should not be fetched for every signed transaction")
+}
@@ -0,0 +1,245 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/pkg/errors"
+)
+
+const (
+	// GasOracle_Congestion uses node's suggestions adjusted by historical fee data, priority and network congestion (default)
+	GasOracle_Congestion = "congestion"
+	// GasOracle_Fixed always uses gas prices from the network config
+	GasOracle_Fixed = "fixed"
+	// GasOracle_FeeHistory uses percentiles of tips from eth_feeHistory and base fee of the next block
+	GasOracle_FeeHistory = "fee_history"
+)
+
+const (
+	ErrUnknownGasOracle = "unknown gas oracle"
+	ErrFeeHistory       = "failed to get fee history"
+)
+
+// MaxFeeHistoryBlocks is the maximum number of blocks most nodes return for a single eth_feeHistory call
+const MaxFeeHistoryBlocks = 1024
+
+// GasOracle suggests gas prices for transactions with given priority. It's used by CalculateGasEstimations(), which
+// falls back to values from the network config, if the oracle returns an error.
+type GasOracle interface {
+	// Name returns the name of the oracle, used only for logging
+	Name() string
+	// SuggestEIP1559Fees returns fee cap and tip cap for EIP-1559 transactions
+	SuggestEIP1559Fees(ctx context.Context, priority string) (gasFeeCap *big.Int, gasTipCap *big.Int, err error)
+	// SuggestLegacyFees returns gas price for legacy transactions
+	SuggestLegacyFees(ctx context.Context, priority string) (gasPrice *big.Int, err error)
+}
+
+// NewGasOracle returns custom gas oracle from the config, if it's set, or creates built-in oracle selected by network's `gas_oracle` setting
+func NewGasOracle(c *Client) (GasOracle, error) {
+	if c.Cfg.CustomGasOracle != nil {
+		return c.Cfg.CustomGasOracle, nil
+	}
+
+	switch strings.ToLower(c.Cfg.Network.GasOracle) {
+	case "", GasOracle_Congestion:
+		return NewCongestionGasOracle(c), nil
+	case GasOracle_Fixed:
+		return NewFixedGasOracle(c.Cfg.Network.GasPrice, c.Cfg.Network.GasFeeCap, c.Cfg.Network.GasTipCap), nil
+	case GasOracle_FeeHistory:
+		return NewFeeHistoryGasOracle(c.Client, c.Cfg.Network.GasPriceEstimationBlocks), nil
+	default:
+		return nil, fmt.Errorf("%s: %s", ErrUnknownGasOracle, c.Cfg.Network.GasOracle)
+	}
+}
+
+// gasOracle returns client's GasOracle. Clients that weren't created with NewClientRaw (e.g. struct literals) have none
+// set, in which case oracle selected by the config is used and if that fails, fixed prices from the network config.
+func (m *Client) gasOracle() GasOracle {
+	if m.GasOracle != nil {
+		return m.GasOracle
+	}
+	oracle, err := NewGasOracle(m)
+	if err != nil {
+		L.Warn().Err(err).Msg("Failed to create gas oracle. Using fixed gas prices from network config")
+		return NewFixedGasOracle(m.Cfg.Network.GasPrice, m.Cfg.Network.GasFeeCap, m.Cfg.Network.GasTipCap)
+	}
+	return oracle
+}
+
+// CongestionGasOracle uses node's suggested fees adjusted by historical fee data, transaction priority and network congestion
+type CongestionGasOracle struct {
+	client *Client
+}
+
+// NewCongestionGasOracle creates a new CongestionGasOracle
+func NewCongestionGasOracle(client *Client) *CongestionGasOracle {
+	return &CongestionGasOracle{client: client}
+}
+
+func (o *CongestionGasOracle) Name() string {
+	return GasOracle_Congestion
+}
+
+func (o *CongestionGasOracle) SuggestEIP1559Fees(ctx context.Context, priority string) (*big.Int, *big.Int, error) {
+	return o.client.GetSuggestedEIP1559Fees(ctx, priority)
+}
+
+func (o *CongestionGasOracle) SuggestLegacyFees(ctx context.Context, priority string) (*big.Int, error) {
+	return o.client.GetSuggestedLegacyFees(ctx, priority)
+}
+
+// FixedGasOracle always returns the same gas prices, regardless of priority
+type FixedGasOracle struct {
+	gasPrice  *big.Int
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+}
+
+// NewFixedGasOracle creates a new FixedGasOracle
+func NewFixedGasOracle(gasPrice, gasFeeCap, gasTipCap int64) *FixedGasOracle {
+	return &FixedGasOracle{
+		gasPrice:  big.NewInt(gasPrice),
+		gasFeeCap: big.NewInt(gasFeeCap),
+		gasTipCap: big.NewInt(gasTipCap),
+	}
+}
+
+func (o *FixedGasOracle) Name() string {
+	return GasOracle_Fixed
+}
+
+func (o *FixedGasOracle) SuggestEIP1559Fees(_ context.Context, _ string) (*big.Int, *big.Int, error) {
+	return new(big.Int).Set(o.gasFeeCap), new(big.Int).Set(o.gasTipCap), nil
+}
+
+func (o *FixedGasOracle) SuggestLegacyFees(_ context.Context, _ string) (*big.Int, error) {
+	return new(big.Int).Set(o.gasPrice), nil
+}
+
+// FeeHistoryReader is implemented by clients that support eth_feeHistory, e.g. ethclient.Client
+type FeeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// FeeHistoryGasOracle uses only eth_feeHistory: tip is the median (across non-empty blocks) of the reward percentile
+// selected by priority and fee cap is twice the base fee of the next block plus the tip, which keeps the transaction
+// includable even if the base fee keeps rising for a few blocks. Legacy gas price is next block's base fee plus the tip.
+type FeeHistoryGasOracle struct {
+	reader FeeHistoryReader
+	blocks uint64
+}
+
+// NewFeeHistoryGasOracle creates a new FeeHistoryGasOracle that uses given number of latest blocks (at most MaxFeeHistoryBlocks)
+func NewFeeHistoryGasOracle(reader FeeHistoryReader, blocks uint64) *FeeHistoryGasOracle {
+	if blocks == 0 {
+		blocks = 1
+	}
+	if blocks > MaxFeeHistoryBlocks {
+		blocks = MaxFeeHistoryBlocks
+	}
+	return &FeeHistoryGasOracle{reader: reader, blocks: blocks}
+}
+
+func (o *FeeHistoryGasOracle) Name() string {
+	return GasOracle_FeeHistory
+}
+
+func (o *FeeHistoryGasOracle) SuggestEIP1559Fees(ctx context.Context, priority string) (*big.Int, *big.Int, error) {
+	baseFee, tip, err := o.feeHistory(ctx, priority)
+	if err != nil {
+		return nil, nil, err
+	}
+	if baseFee.Sign() == 0 {
+		return nil, nil, errors.New(ZeroGasSuggestedErr)
+	}
+
+	feeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+
+	L.Debug().
+		Str("NextBaseFee", fmt.Sprintf("%s wei / %s ether", baseFee.String(), WeiToEther(baseFee).Text('f', -1))).
+		Str("GasTipCap", fmt.Sprintf("%s wei / %s ether", tip.String(), WeiToEther(tip).Text('f', -1))).
+		Str("GasFeeCap", fmt.Sprintf("%s wei / %s ether", feeCap.String(), WeiToEther(feeCap).Text('f', -1))).
+		Str("Priority", priority).
+		Msg("Calculated EIP-1559 fees from fee history")
+
+	return feeCap, tip, nil
+}
+
+func (o *FeeHistoryGasOracle) SuggestLegacyFees(ctx context.Context, priority string) (*big.Int, error) {
+	baseFee, tip, err := o.feeHistory(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := new(big.Int).Add(baseFee, tip)
+	if gasPrice.Sign() == 0 {
+		return nil, errors.New(ZeroGasSuggestedErr)
+	}
+
+	L.Debug().
+		Str("GasPrice", fmt.Sprintf("%s wei / %s ether", gasPrice.String(), WeiToEther(gasPrice).Text('f', -1))).
+		Str("Priority", priority).
+		Msg("Calculated Legacy fees from fee history")
+
+	return gasPrice, nil
+}
+
+// feeHistory returns base fee of the next block and median tip at percentile matching the priority
+func (o *FeeHistoryGasOracle) feeHistory(ctx context.Context, priority string) (*big.Int, *big.Int, error) {
+	percentile, err := feeHistoryPercentile(priority)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	history, err := o.reader.FeeHistory(ctx, o.blocks, nil, []float64{percentile})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrFeeHistory)
+	}
+
+	baseFee := big.NewInt(0)
+	// base fee array contains one more element than the number of blocks: base fee of the next block
+	if len(history.BaseFee) > 0 && history.BaseFee[len(history.BaseFee)-1] != nil {
+		baseFee = history.BaseFee[len(history.BaseFee)-1]
+	}
+
+	var tips []*big.Int
+	for i, reward := range history.Reward {
+		// empty blocks have all rewards equal to 0, which would skew the result
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if len(reward) > 0 && reward[0] != nil {
+			tips = append(tips, reward[0])
+		}
+	}
+
+	tip := big.NewInt(0)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool {
+			return tips[i].Cmp(tips[j]) < 0
+		})
+		tip = new(big.Int).Set(tips[len(tips)/2])
+	}
+
+	return new(big.Int).Set(baseFee), tip, nil
+}
+
+func feeHistoryPercentile(priority string) (float64, error) {
+	switch priority {
+	case Priority_Degen:
+		return 99, nil
+	case Priority_Fast:
+		return 75, nil
+	case Priority_Standard:
+		return 50, nil
+	case Priority_Slow:
+		return 25, nil
+	default:
+		return 0, fmt.Errorf("unknown priority: %s", priority)
+	}
+}
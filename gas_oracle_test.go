@@ -0,0 +1,141 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+type feeHistoryReader struct {
+	history     *ethereum.FeeHistory
+	percentiles []float64
+}
+
+func (f *feeHistoryReader) FeeHistory(_ context.Context, _ uint64, _ *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	f.percentiles = rewardPercentiles
+	return f.history, nil
+}
+
+type staticGasOracle struct {
+	err error
+}
+
+func (s *staticGasOracle) Name() string {
+	return "static"
+}
+
+func (s *staticGasOracle) SuggestEIP1559Fees(_ context.Context, _ string) (*big.Int, *big.Int, error) {
+	return big.NewInt(300), big.NewInt(30), s.err
+}
+
+func (s *staticGasOracle) SuggestLegacyFees(_ context.Context, _ string) (*big.Int, error) {
+	return big.NewInt(200), s.err
+}
+
+func TestGasOracleFeeHistory(t *testing.T) {
+	reader := &feeHistoryReader{history: &ethereum.FeeHistory{
+		Reward:       [][]*big.Int{{big.NewInt(5)}, {big.NewInt(0)}, {big.NewInt(1)}, {big.NewInt(3)}},
+		BaseFee:      []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(105), big.NewInt(110)},
+		GasUsedRatio: []float64{0.5, 0, 0.4, 0.7},
+	}}
+	oracle := seth.NewFeeHistoryGasOracle(reader, 4)
+
+	feeCap, tipCap, err := oracle.SuggestEIP1559Fees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to suggest EIP-1559 fees")
+	require.Equal(t, []float64{75}, reader.percentiles, "fast priority should use 75th percentile")
+	require.Equal(t, big.NewInt(3), tipCap, "tip should be the median of rewards from non-empty blocks")
+	require.Equal(t, big.NewInt(2*110+3), feeCap, "fee cap should be twice the next base fee plus tip")
+
+	gasPrice, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Slow)
+	require.NoError(t, err, "failed to suggest legacy fees")
+	require.Equal(t, []float64{25}, reader.percentiles, "slow priority should use 25th percentile")
+	require.Equal(t, big.NewInt(110+3), gasPrice, "gas price should be next base fee plus tip")
+
+	reader.history = &ethereum.FeeHistory{Reward: [][]*big.Int{{big.NewInt(7)}}, BaseFee: []*big.Int{big.NewInt(0), big.NewInt(0)}, GasUsedRatio: []float64{0.5}}
+	_, _, err = oracle.SuggestEIP1559Fees(context.Background(), seth.Priority_Standard)
+	require.EqualError(t, err, seth.ZeroGasSuggestedErr, "zero base fee should be rejected for EIP-1559 fees")
+
+	gasPrice, err = oracle.SuggestLegacyFees(context.Background(), seth.Priority_Standard)
+	require.NoError(t, err, "failed to suggest legacy fees for network without base fee")
+	require.Equal(t, big.NewInt(7), gasPrice, "gas price should be equal to the reward, when there's no base fee")
+}
+
+func TestGasOracleFixed(t *testing.T) {
+	oracle := seth.NewFixedGasOracle(1, 2, 3)
+
+	feeCap, tipCap, err := oracle.SuggestEIP1559Fees(context.Background(), seth.Priority_Fast)
+	require.NoError(t, err, "failed to suggest EIP-1559 fees")
+	require.Equal(t, big.NewInt(2), feeCap, "incorrect fee cap")
+	require.Equal(t, big.NewInt(3), tipCap, "incorrect tip cap")
+
+	gasPrice, err := oracle.SuggestLegacyFees(context.Background(), seth.Priority_Slow)
+	require.NoError(t, err, "failed to suggest legacy fees")
+	require.Equal(t, big.NewInt(1), gasPrice, "incorrect gas price")
+}
+
+func TestGasOracleCalculateGasEstimationsUsesClientOracle(t *testing.T) {
+	node := newFakeNode(t)
+	newClient := func(oracle seth.GasOracle, eip1559 bool) *seth.Client {
+		return newFakeNodeClient(t, node, withEIP1559(eip1559), withConfig(func(cfg *seth.Config) {
+			cfg.CustomGasOracle = oracle
+		}))
+	}
+	request := seth.GasEstimationRequest{
+		GasEstimationEnabled: true,
+		FallbackGasPrice:     1,
+		FallbackGasFeeCap:    2,
+		FallbackGasTipCap:    3,
+		Priority:             seth.Priority_Standard,
+	}
+
+	estimations := newClient(&staticGasOracle{}, true).CalculateGasEstimations(request)
+	require.Equal(t, big.NewInt(300), estimations.GasFeeCap, "fee cap should come from the oracle")
+	require.Equal(t, big.NewInt(30), estimations.GasTipCap, "tip cap should come from the oracle")
+
+	estimations = newClient(&staticGasOracle{}, false).CalculateGasEstimations(request)
+	require.Equal(t, big.NewInt(200), estimations.GasPrice, "gas price should come from the oracle")
+
+	estimations = newClient(&staticGasOracle{err: errors.New("oracle failed")}, true).CalculateGasEstimations(request)
+	require.Equal(t, big.NewInt(2), estimations.GasFeeCap, "fallback fee cap should be used, when oracle fails")
+	require.Equal(t, big.NewInt(3), estimations.GasTipCap, "fallback tip cap should be used, when oracle fails")
+}
+
+func TestGasOracleUnknownOracleIsRejected(t *testing.T) {
+	cfg := &seth.Config{Network: &seth.Network{GasOracle: "magic"}}
+	require.EqualError(t, seth.ValidateConfig(cfg), "gas oracle must be one of: congestion, fixed, fee_history", "unknown gas oracle should be rejected")
+}
+
+func TestGasOracleClientWithoutOracleUsesOracleFromConfig(t *testing.T) {
+	newClient := func(eip1559 bool) *seth.Client {
+		return &seth.Client{Cfg: &seth.Config{Network: &seth.Network{
+			Name:               "Fixed",
+			GasOracle:          seth.GasOracle_Fixed,
+			GasPrice:           5,
+			GasFeeCap:          6,
+			GasTipCap:          7,
+			EIP1559DynamicFees: eip1559,
+			TxnTimeout:         seth.MustMakeDuration(time.Minute),
+		}}}
+	}
+	request := seth.GasEstimationRequest{
+		GasEstimationEnabled: true,
+		FallbackGasPrice:     1,
+		FallbackGasFeeCap:    2,
+		FallbackGasTipCap:    3,
+		Priority:             seth.Priority_Standard,
+	}
+
+	estimations := newClient(true).CalculateGasEstimations(request)
+	require.Equal(t, big.NewInt(6), estimations.GasFeeCap, "fee cap should come from the oracle selected by config")
+	require.Equal(t, big.NewInt(7), estimations.GasTipCap, "tip cap should come from the oracle selected by config")
+
+	estimations = newClient(false).CalculateGasEstimations(request)
+	require.Equal(t, big.NewInt(5), estimations.GasPrice, "gas price should come from the oracle selected by config")
+}
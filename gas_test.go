@@ -2,6 +2,7 @@ package seth_test
 
 import (
 	"context"
+	"math/big"
 	"testing"
 
 	"github.com/smartcontractkit/seth"
@@ -35,3 +36,47 @@ func TestGasEstimator(t *testing.T) {
 	require.GreaterOrEqual(t, suggestions.TipCap.Perc99, suggestions.TipCap.Perc75, "Suggested 99th percentile tip cap should be greater than or equal to 75th percentile")
 	require.GreaterOrEqual(t, suggestions.TipCap.Max, suggestions.TipCap.Perc99, "Suggested max tip cap should be greater than or equal to 99th percentile")
 }
+
+// golden values below were calculated from the recorded congested window, they have to be updated only if estimation logic changes
+
+func TestGasEstimatorStatsOfRecordedCongestion(t *testing.T) {
+	fixture := loadRecordedFeeHistoryFixture(t)
+	c := newFeeHistoryReplaySethClient(t, fixture)
+
+	suggestions, err := seth.NewGasEstimator(c).Stats(fixture.LatestBlock(), 25)
+	require.NoError(t, err, "Gas esimator should not err")
+	require.Equal(t, seth.GasPercentiles{Max: 24_594_753_555, Perc99: 23_953_658_083.5, Perc75: 16_764_415_749.5, Perc50: 10_218_134_095, Perc25: 4_760_284_174}, *suggestions.GasPrice, "incorrect gas price percentiles")
+	require.Equal(t, seth.GasPercentiles{Max: 4_000_000_000, Perc99: 4_000_000_000, Perc75: 3_000_000_000, Perc50: 2_000_000_000, Perc25: 2_000_000_000}, *suggestions.TipCap, "incorrect tip cap percentiles")
+}
+
+func TestGasEstimationOfRecordedCongestion(t *testing.T) {
+	fixture := loadRecordedFeeHistoryFixture(t)
+
+	congestion, err := newFeeHistoryReplaySethClient(t, fixture).CalculateNetworkCongestionMetric(10, seth.CongestionStrategy_NewestFirst)
+	require.NoError(t, err, "failed to calculate congestion")
+	require.Equal(t, 0.7300639813860561, congestion, "incorrect congestion of the last 10 blocks")
+
+	expected := []struct {
+		priority  string
+		gasFeeCap int64
+		gasTipCap int64
+		gasPrice  int64
+	}{
+		{priority: seth.Priority_Slow, gasFeeCap: 21_502_152_799, gasTipCap: 5_720_000_000, gasPrice: 25_285_065_115},
+		{priority: seth.Priority_Standard, gasFeeCap: 33_455_314_245, gasTipCap: 10_400_000_000, gasPrice: 31_606_331_395},
+		{priority: seth.Priority_Fast, gasFeeCap: 56_867_706_610, gasTipCap: 19_500_000_000, gasPrice: 37_927_597_674},
+		{priority: seth.Priority_Degen, gasFeeCap: 73_309_769_431, gasTipCap: 25_350_000_000, gasPrice: 47_409_497_093},
+	}
+	for _, e := range expected {
+		// each estimation uses a new client, so that nothing is cached between them
+		c := newFeeHistoryReplaySethClient(t, fixture)
+		gasFeeCap, gasTipCap, err := c.GetSuggestedEIP1559Fees(context.Background(), e.priority)
+		require.NoError(t, err, "failed to get suggested EIP-1559 fees")
+		require.Equal(t, big.NewInt(e.gasFeeCap), gasFeeCap, "incorrect fee cap for %s priority", e.priority)
+		require.Equal(t, big.NewInt(e.gasTipCap), gasTipCap, "incorrect tip cap for %s priority", e.priority)
+
+		gasPrice, err := c.GetSuggestedLegacyFees(context.Background(), e.priority)
+		require.NoError(t, err, "failed to get suggested legacy fees")
+		require.Equal(t, big.NewInt(e.gasPrice), gasPrice, "incorrect gas price for %s priority", e.priority)
+	}
+}
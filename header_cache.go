@@ -83,3 +83,22 @@ func (c *LFUHeaderCache) evict() {
 	L.Trace().Msgf("Evicted header %d from cache", evictKey)
 	delete(c.cache, evictKey)
 }
+
+// Capacity returns the maximum number of headers the cache can hold.
+func (c *LFUHeaderCache) Capacity() uint64 {
+	return c.capacity
+}
+
+// EvictOlderThan removes all headers with block number lower than the given one. It's used to drop headers
+// that fell out of the estimation window, so that new heads don't evict headers that are still needed.
+func (c *LFUHeaderCache) EvictOlderThan(blockNumber int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.cache {
+		if key < blockNumber {
+			L.Trace().Msgf("Evicted header %d from cache", key)
+			delete(c.cache, key)
+		}
+	}
+}
@@ -3,14 +3,80 @@ package seth
 import (
 	"context"
 	"crypto/ecdsa"
+	"math/big"
+	"os"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pelletier/go-toml/v2"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
-	"math/big"
 )
 
+const (
+	ErrEmptyKeyFile = "key file doesn't contain any keys"
+	ErrNoKeysToFund = "no keys to fund, only the root key is loaded"
+)
+
+// KeyFile is a TOML file with private keys, which can be loaded in addition to the root key
+type KeyFile struct {
+	Keys []*KeyData `toml:"keys"`
+}
+
+// KeyData is a single key stored in the key file
+type KeyData struct {
+	PrivateKey string `toml:"private_key"`
+	Address    string `toml:"address"`
+}
+
+// NewKeyFile creates a key file with given number of new keys
+func NewKeyFile(keys int) (*KeyFile, error) {
+	kf := &KeyFile{}
+	for i := 0; i < keys; i++ {
+		addr, pk, err := NewAddress()
+		if err != nil {
+			return nil, err
+		}
+		kf.Keys = append(kf.Keys, &KeyData{PrivateKey: pk, Address: addr})
+	}
+	return kf, nil
+}
+
+// LoadKeyFile reads key file from given path
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	kf := &KeyFile{}
+	if err := toml.Unmarshal(data, kf); err != nil {
+		return nil, err
+	}
+	if len(kf.Keys) == 0 {
+		return nil, errors.New(ErrEmptyKeyFile)
+	}
+	return kf, nil
+}
+
+// Save writes key file to given path, file is readable only by the owner
+func (kf *KeyFile) Save(path string) error {
+	marshalled, err := toml.Marshal(kf)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, marshalled, 0600)
+}
+
+// PrivateKeys returns private keys from the key file in the format used in network config
+func (kf *KeyFile) PrivateKeys() []string {
+	pks := make([]string, 0, len(kf.Keys))
+	for _, k := range kf.Keys {
+		pks = append(pks, k.PrivateKey)
+	}
+	return pks
+}
+
 // NewAddress creates a new address
 func NewAddress() (string, string, error) {
 	privateKey, err := crypto.GenerateKey()
@@ -36,7 +102,7 @@ func ReturnFunds(c *Client, toAddr string) error {
 		toAddr = c.Addresses[0].Hex()
 	}
 
-	gasPrice, err := c.GetSuggestedLegacyFees(context.Background(), Priority_Standard)
+	gasPrice, err := c.gasOracle().SuggestLegacyFees(context.Background(), Priority_Standard)
 	if err != nil {
 		gasPrice = big.NewInt(c.Cfg.Network.GasPrice)
 	}
@@ -58,16 +124,14 @@ func ReturnFunds(c *Client, toAddr string) error {
 				return err
 			}
 
-			var gasLimit int64
-			gasLimitRaw, err := c.EstimateGasLimitForFundTransfer(c.Addresses[idx], common.HexToAddress(toAddr), balance)
+			transferFee, err := c.EstimateTransferFee(egCtx, c.Addresses[idx], common.HexToAddress(toAddr), balance, gasPrice)
 			if err != nil {
-				gasLimit = c.Cfg.Network.TransferGasFee
-			} else {
-				gasLimit = int64(gasLimitRaw)
+				L.Error().Err(err).Msg("Error estimating transfer fee")
+				return err
 			}
 
-			networkTransferFee := gasPrice.Int64() * gasLimit
-			fundsToReturn := new(big.Int).Sub(balance, big.NewInt(networkTransferFee))
+			networkTransferFee := transferFee.Total
+			fundsToReturn := new(big.Int).Sub(balance, networkTransferFee)
 
 			if fundsToReturn.Cmp(big.NewInt(0)) == -1 {
 				L.Warn().
@@ -82,18 +146,21 @@ func ReturnFunds(c *Client, toAddr string) error {
 			L.Info().
 				Str("Key", c.Addresses[idx].Hex()).
 				Interface("Balance", balance).
-				Interface("NetworkFee", c.Cfg.Network.GasPrice*gasLimit).
-				Interface("GasLimit", gasLimit).
+				Interface("NetworkFee", networkTransferFee).
+				Interface("L1Fee", transferFee.L1Fee).
+				Interface("GasLimit", transferFee.GasLimit).
 				Interface("GasPrice", gasPrice).
 				Interface("FundsToReturn", fundsToReturn).
 				Msg("Returning funds from address")
 
-			return c.TransferETHFromKey(
+			// the same gas limit has to be used, otherwise reserved fee might not cover the actual one
+			return c.transferETHFromKeyWithGasLimit(
 				egCtx,
 				idx,
 				toAddr,
 				fundsToReturn,
 				gasPrice,
+				transferFee.GasLimit,
 			)
 		})
 	}
@@ -103,3 +170,96 @@ func ReturnFunds(c *Client, toAddr string) error {
 
 	return nil
 }
+
+// KeyBalance is the balance of a loaded key
+type KeyBalance struct {
+	KeyNum  int
+	Address common.Address
+	Balance *big.Int
+}
+
+// KeyBalances returns balances of all loaded keys, root key first
+func (m *Client) KeyBalances(ctx context.Context) ([]KeyBalance, error) {
+	balances := make([]KeyBalance, len(m.Addresses))
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i, addr := range m.Addresses {
+		i, addr := i, addr
+		eg.Go(func() error {
+			balance, err := m.Client.BalanceAt(egCtx, addr, nil)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get balance of key %d", i)
+			}
+			balances[i] = KeyBalance{KeyNum: i, Address: addr, Balance: balance}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// SplitFunds splits root key's balance (minus transfer fees and root key buffer in ETH) equally between all other keys
+func SplitFunds(c *Client, rootKeyBuffer int64) error {
+	if len(c.Addresses) < 2 {
+		return errors.New(ErrNoKeysToFund)
+	}
+
+	gasPrice, err := c.gasOracle().SuggestLegacyFees(context.Background(), Priority_Standard)
+	if err != nil {
+		gasPrice = big.NewInt(c.Cfg.Network.GasPrice)
+	}
+
+	bd, err := c.CalculateSubKeyFunding(int64(len(c.Addresses)-1), gasPrice.Int64(), rootKeyBuffer)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eg, egCtx := errgroup.WithContext(ctx)
+	// root key is always the first one
+	for _, addr := range c.Addresses[1:] {
+		addr := addr
+		eg.Go(func() error {
+			return c.TransferETHFromKey(egCtx, 0, addr.Hex(), bd.AddrFunding, gasPrice)
+		})
+	}
+	return eg.Wait()
+}
+
+// TopUpFunds sends funds from the root key to all other keys, whose balance is lower than target, so that they have exactly target wei
+func TopUpFunds(c *Client, target *big.Int) error {
+	if len(c.Addresses) < 2 {
+		return errors.New(ErrNoKeysToFund)
+	}
+
+	gasPrice, err := c.gasOracle().SuggestLegacyFees(context.Background(), Priority_Standard)
+	if err != nil {
+		gasPrice = big.NewInt(c.Cfg.Network.GasPrice)
+	}
+
+	balances, err := c.KeyBalances(context.Background())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eg, egCtx := errgroup.WithContext(ctx)
+	for _, kb := range balances[1:] {
+		kb := kb
+		if kb.Balance.Cmp(target) >= 0 {
+			L.Info().
+				Int("KeyNum", kb.KeyNum).
+				Str("Key", kb.Address.Hex()).
+				Str("Balance", kb.Balance.String()).
+				Msg("Key has enough funds. Skipping.")
+			continue
+		}
+		eg.Go(func() error {
+			return c.TransferETHFromKey(egCtx, 0, kb.Address.Hex(), new(big.Int).Sub(target, kb.Balance), gasPrice)
+		})
+	}
+	return eg.Wait()
+}
@@ -0,0 +1,103 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func TestKeyFileIsSavedAndLoaded(t *testing.T) {
+	kf, err := seth.NewKeyFile(3)
+	require.NoError(t, err, "failed to create key file")
+	require.Len(t, kf.Keys, 3, "incorrect number of keys")
+
+	path := filepath.Join(t.TempDir(), "keys.toml")
+	require.NoError(t, kf.Save(path), "failed to save key file")
+
+	loaded, err := seth.LoadKeyFile(path)
+	require.NoError(t, err, "failed to load key file")
+	require.Equal(t, kf.PrivateKeys(), loaded.PrivateKeys(), "private keys should survive saving")
+
+	pk, err := crypto.HexToECDSA(loaded.Keys[0].PrivateKey)
+	require.NoError(t, err, "private key should be hex encoded")
+	require.Equal(t, crypto.PubkeyToAddress(pk.PublicKey).Hex(), loaded.Keys[0].Address, "address should match private key")
+
+	require.NoError(t, (&seth.KeyFile{}).Save(path), "failed to save empty key file")
+	_, err = seth.LoadKeyFile(path)
+	require.EqualError(t, err, seth.ErrEmptyKeyFile, "empty key file should be rejected")
+}
+
+func TestKeysBalancesAndNonces(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withAutoMine(), withKeys(t, 3))
+	node.setBalance(c.Addresses[0], big.NewInt(100))
+	node.setBalance(c.Addresses[2], big.NewInt(5))
+
+	balances, err := c.KeyBalances(context.Background())
+	require.NoError(t, err, "failed to get balances")
+	require.Len(t, balances, 3, "balance of each key should be returned")
+	require.Equal(t, c.Addresses[2], balances[2].Address, "balances should be ordered by key number")
+	require.Equal(t, big.NewInt(100), balances[0].Balance, "incorrect root key balance")
+	require.Zero(t, balances[1].Balance.Sign(), "incorrect key 1 balance")
+
+	node.setLatestNonce(c.Addresses[2], 5)
+	status, err := c.GetNonceFixStatus(2)
+	require.NoError(t, err, "failed to get nonce status")
+	require.Equal(t, c.Addresses[2], status.Address, "incorrect key address")
+	require.Equal(t, uint64(5), status.LastNonce, "incorrect latest nonce")
+	require.Zero(t, status.Pending(), "key without pending transactions should have none")
+}
+
+func TestKeysTransactionOptionsOfKeyThatIsNotLoaded(t *testing.T) {
+	c := newFakeNodeClient(t, newFakeNode(t), withAutoMine(), withKeys(t, 2))
+
+	opts := c.NewTXKeyOpts(1)
+	require.Nil(t, opts.Context.Value(seth.ContextErrorKey{}), "loaded key should have no error")
+	require.Equal(t, c.Addresses[1], opts.From, "incorrect sender")
+
+	for _, keyNum := range []int{-1, 2} {
+		opts = c.NewTXKeyOpts(keyNum)
+		require.NotNil(t, opts.Context.Value(seth.ContextErrorKey{}), "key %d should have an error", keyNum)
+	}
+}
+
+func TestKeysTopUpFundsOnlyKeysBelowTarget(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withAutoMine(), withKeys(t, 3))
+	target := big.NewInt(1_000_000)
+	node.setBalance(c.Addresses[0], big.NewInt(1_000_000_000))
+	node.setBalance(c.Addresses[1], big.NewInt(400_000))
+	node.setBalance(c.Addresses[2], big.NewInt(2_000_000))
+
+	require.NoError(t, seth.TopUpFunds(c, target), "failed to top up funds")
+	sent := node.sentTxs()
+	require.Len(t, sent, 1, "only key below target should be funded")
+	require.Equal(t, c.Addresses[1], *sent[0].To(), "incorrect funded key")
+	require.Equal(t, big.NewInt(600_000), sent[0].Value(), "key should be topped up to target")
+}
+
+func TestKeysSplitFundsBetweenAllKeys(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withAutoMine(), withKeys(t, 3))
+	node.setBalance(c.Addresses[0], big.NewInt(1_000_000_000))
+
+	require.NoError(t, seth.SplitFunds(c, 0), "failed to split funds")
+	sent := node.sentTxs()
+	require.Len(t, sent, 2, "each non-root key should be funded")
+
+	// both transfers cost 21_000 * gas price
+	expected := big.NewInt((1_000_000_000 - 2*21_000*fakeNodeGasPrice) / 2)
+	for _, tx := range sent {
+		require.Equal(t, expected, tx.Value(), "funds should be split equally")
+	}
+
+	rootOnly := newFakeNodeClient(t, newFakeNode(t), withAutoMine(), withKeys(t, 1))
+	err := seth.TopUpFunds(rootOnly, big.NewInt(1))
+	require.EqualError(t, err, seth.ErrNoKeysToFund, "there should be no keys to fund")
+}
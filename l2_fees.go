@@ -0,0 +1,292 @@
+package seth
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChainType_Ethereum is any network, where transaction fee is gas used * gas price (default)
+	ChainType_Ethereum = "ethereum"
+	// ChainType_Optimism is an OP stack network, where L1 data fee is charged on top of L2 execution fee
+	ChainType_Optimism = "optimism"
+	// ChainType_Arbitrum is an Arbitrum network, where L1 data fee is charged as additional L2 gas
+	ChainType_Arbitrum = "arbitrum"
+)
+
+const (
+	ErrUnknownChainType = "chain type must be one of: ethereum, optimism, arbitrum"
+	ErrL1FeeEstimation  = "failed to estimate L1 fee"
+)
+
+// L1FeeBufferPercent is added to estimated L1 fee, when calculating how much is needed for transfer fees, because L1 fee can change before transaction is mined
+const L1FeeBufferPercent = 10
+
+var (
+	// OptimismGasPriceOracleAddress is the address of OP stack GasPriceOracle predeploy
+	OptimismGasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+	// ArbitrumNodeInterfaceAddress is the address of Arbitrum NodeInterface virtual contract
+	ArbitrumNodeInterfaceAddress = common.HexToAddress("0x00000000000000000000000000000000000000C8")
+)
+
+const optimismGasPriceOracleABI = `[{"inputs":[{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+const arbitrumNodeInterfaceABI = `[{"inputs":[{"internalType":"address","name":"to","type":"address"},{"internalType":"bool","name":"contractCreation","type":"bool"},{"internalType":"bytes","name":"data","type":"bytes"}],"name":"gasEstimateComponents","outputs":[{"internalType":"uint64","name":"gasEstimate","type":"uint64"},{"internalType":"uint64","name":"gasEstimateForL1","type":"uint64"},{"internalType":"uint256","name":"baseFee","type":"uint256"},{"internalType":"uint256","name":"l1BaseFeeEstimate","type":"uint256"}],"stateMutability":"payable","type":"function"}]`
+
+var (
+	optimismGasPriceOracle = mustParseABI(optimismGasPriceOracleABI)
+	arbitrumNodeInterface  = mustParseABI(arbitrumNodeInterfaceABI)
+)
+
+func mustParseABI(abiJson string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// validateChainType normalises chain type and checks if it's supported
+func validateChainType(network *Network) error {
+	network.ChainType = strings.ToLower(network.ChainType)
+	switch network.ChainType {
+	case "", ChainType_Ethereum, ChainType_Optimism, ChainType_Arbitrum:
+		return nil
+	default:
+		return errors.New(ErrUnknownChainType)
+	}
+}
+
+// TransferFee is the estimated fee of ETH transfer
+type TransferFee struct {
+	GasLimit uint64
+	GasPrice *big.Int
+	// L1Fee is the estimated L1 data fee. On Arbitrum it's paid with L2 gas, so it's already included in GasLimit
+	L1Fee *big.Int
+	// Total is how much wei has to be left for fees, including L1 fee buffer
+	Total *big.Int
+}
+
+// EstimateTransferFee estimates gas limit and total fee of ETH transfer taking into account L1 data fee on L2 networks.
+// If gas limit can't be estimated, network's transfer_gas_fee is used.
+func (m *Client) EstimateTransferFee(ctx context.Context, from, to common.Address, value, gasPrice *big.Int) (*TransferFee, error) {
+	gasLimit := uint64(m.Cfg.Network.TransferGasFee)
+	if estimated, err := m.EstimateGasLimitForFundTransfer(from, to, value); err == nil {
+		gasLimit = estimated
+	}
+
+	fee := &TransferFee{
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		L1Fee:    big.NewInt(0),
+	}
+
+	msg := ethereum.CallMsg{From: from, To: &to, Value: value, Gas: gasLimit, GasPrice: gasPrice}
+	switch m.Cfg.Network.ChainType {
+	case ChainType_Optimism:
+		l1Fee, err := m.optimismL1Fee(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		fee.L1Fee = l1Fee
+	case ChainType_Arbitrum:
+		gasEstimate, gasEstimateForL1, _, err := m.arbitrumGasEstimateComponents(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		// gas limit set in config doesn't include L1 gas
+		if gasEstimate > fee.GasLimit {
+			fee.GasLimit = gasEstimate
+		}
+		fee.L1Fee = new(big.Int).Mul(new(big.Int).SetUint64(gasEstimateForL1), gasPrice)
+	}
+
+	fee.Total = new(big.Int).Mul(new(big.Int).SetUint64(fee.GasLimit), gasPrice)
+	if m.Cfg.Network.ChainType == ChainType_Optimism {
+		buffered := new(big.Int).Mul(fee.L1Fee, big.NewInt(100+L1FeeBufferPercent))
+		fee.Total.Add(fee.Total, buffered.Div(buffered, big.NewInt(100)))
+	}
+
+	L.Debug().
+		Str("Chain type", m.Cfg.Network.ChainType).
+		Uint64("Gas limit", fee.GasLimit).
+		Str("Gas price", gasPrice.String()).
+		Str("L1 fee", fee.L1Fee.String()).
+		Str("Total", fee.Total.String()).
+		Msg("Estimated transfer fee")
+
+	return fee, nil
+}
+
+// EstimateL1Fee returns L1 data fee of a transaction described by msg or 0 if network isn't an L2. On Arbitrum it's
+// gas used for L1 * L2 base fee, which is already included in gas limit returned by eth_estimateGas.
+func (m *Client) EstimateL1Fee(ctx context.Context, msg ethereum.CallMsg) (*big.Int, error) {
+	switch m.Cfg.Network.ChainType {
+	case ChainType_Optimism:
+		return m.optimismL1Fee(ctx, msg)
+	case ChainType_Arbitrum:
+		_, gasEstimateForL1, baseFee, err := m.arbitrumGasEstimateComponents(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).Mul(new(big.Int).SetUint64(gasEstimateForL1), baseFee), nil
+	default:
+		return big.NewInt(0), nil
+	}
+}
+
+// optimismL1Fee calls GasPriceOracle.getL1Fee() with unsigned transaction built from msg (the oracle accounts for missing signature)
+func (m *Client) optimismL1Fee(ctx context.Context, msg ethereum.CallMsg) (*big.Int, error) {
+	nonce, err := m.Client.PendingNonceAt(ctx, msg.From)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+	gasPrice := msg.GasPrice
+	if gasPrice == nil {
+		gasPrice = big.NewInt(0)
+	}
+	value := msg.Value
+	if value == nil {
+		value = big.NewInt(0)
+	}
+
+	encodedTx, err := types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      msg.Gas,
+		To:       msg.To,
+		Value:    value,
+		Data:     msg.Data,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	return m.optimismL1FeeForTx(ctx, encodedTx, nil)
+}
+
+// optimismL1FeeForTx calls GasPriceOracle.getL1Fee() with RLP encoded transaction at given block (nil for latest)
+func (m *Client) optimismL1FeeForTx(ctx context.Context, encodedTx []byte, blockNumber *big.Int) (*big.Int, error) {
+	data, err := optimismGasPriceOracle.Pack("getL1Fee", encodedTx)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	result, err := m.Client.CallContract(ctx, ethereum.CallMsg{To: &OptimismGasPriceOracleAddress, Data: data}, blockNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	unpacked, err := optimismGasPriceOracle.Unpack("getL1Fee", result)
+	if err != nil {
+		return nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	return unpacked[0].(*big.Int), nil
+}
+
+// arbitrumGasEstimateComponents calls NodeInterface.gasEstimateComponents(), which returns total gas estimate, the part of it used for L1 data and L2 base fee
+func (m *Client) arbitrumGasEstimateComponents(ctx context.Context, msg ethereum.CallMsg) (uint64, uint64, *big.Int, error) {
+	var to common.Address
+	if msg.To != nil {
+		to = *msg.To
+	}
+
+	data, err := arbitrumNodeInterface.Pack("gasEstimateComponents", to, msg.To == nil, msg.Data)
+	if err != nil {
+		return 0, 0, nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	result, err := m.Client.CallContract(ctx, ethereum.CallMsg{From: msg.From, To: &ArbitrumNodeInterfaceAddress, Value: msg.Value, Data: data}, nil)
+	if err != nil {
+		return 0, 0, nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	unpacked, err := arbitrumNodeInterface.Unpack("gasEstimateComponents", result)
+	if err != nil {
+		return 0, 0, nil, errors.Wrap(err, ErrL1FeeEstimation)
+	}
+
+	return unpacked[0].(uint64), unpacked[1].(uint64), unpacked[2].(*big.Int), nil
+}
+
+// l1FeeFromReceipt returns L1 fee of a mined transaction and whether it's already included in gas used (Arbitrum). OP stack networks
+// return it as "l1Fee" in the receipt, if it's missing we ask the GasPriceOracle. Arbitrum returns "gasUsedForL1", which is multiplied by effective gas price.
+func (m *Client) l1FeeFromReceipt(ctx context.Context, tx *types.Transaction, receipt *types.Receipt, rawReceipt map[string]interface{}) (*big.Int, bool) {
+	switch m.Cfg.Network.ChainType {
+	case ChainType_Arbitrum:
+		if gasUsedForL1 := hexBigFromReceipt(rawReceipt, "gasUsedForL1"); gasUsedForL1 != nil && receipt.EffectiveGasPrice != nil {
+			return gasUsedForL1.Mul(gasUsedForL1, receipt.EffectiveGasPrice), true
+		}
+		return nil, false
+	case ChainType_Optimism:
+		if l1Fee := hexBigFromReceipt(rawReceipt, "l1Fee"); l1Fee != nil {
+			return l1Fee, false
+		}
+		encodedTx, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, false
+		}
+		l1Fee, err := m.optimismL1FeeForTx(ctx, encodedTx, receipt.BlockNumber)
+		if err != nil {
+			L.Debug().Err(err).Str("TxHash", tx.Hash().Hex()).Msg("Failed to get L1 fee from gas price oracle")
+			return nil, false
+		}
+		return l1Fee, false
+	default:
+		return hexBigFromReceipt(rawReceipt, "l1Fee"), false
+	}
+}
+
+// chargesL1Fee returns true if network's chain type charges L1 data fee on top of L2 execution fee
+func (m *Client) chargesL1Fee() bool {
+	return m.Cfg.Network.ChainType == ChainType_Optimism || m.Cfg.Network.ChainType == ChainType_Arbitrum
+}
+
+// transactionReceiptWithL1FeeFields fetches transaction receipt with a single call and returns it both decoded and as raw fields,
+// because L1 fee fields of L2 networks are not part of the standard receipt
+func (m *Client) transactionReceiptWithL1FeeFields(ctx context.Context, txHash common.Hash) (*types.Receipt, map[string]interface{}, error) {
+	var raw json.RawMessage
+	if err := m.Client.Client().CallContext(ctx, &raw, "eth_getTransactionReceipt", txHash); err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, ethereum.NotFound
+	}
+
+	receipt := new(types.Receipt)
+	if err := json.Unmarshal(raw, receipt); err != nil {
+		return nil, nil, err
+	}
+	var rawReceipt map[string]interface{}
+	if err := json.Unmarshal(raw, &rawReceipt); err != nil {
+		return nil, nil, err
+	}
+
+	return receipt, rawReceipt, nil
+}
+
+// hexBigFromReceipt returns hex encoded number from raw receipt or nil, if it's not there
+func hexBigFromReceipt(rawReceipt map[string]interface{}, field string) *big.Int {
+	if rawReceipt == nil {
+		return nil
+	}
+	raw, ok := rawReceipt[field].(string)
+	if !ok {
+		return nil
+	}
+	value, err := hexutil.DecodeBig(raw)
+	if err != nil {
+		return nil
+	}
+	return value
+}
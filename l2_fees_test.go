@@ -0,0 +1,152 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+const l2TestL1Fee = 1_000_000
+
+// abiWords ABI-encodes given numbers as consecutive 32-byte words
+func abiWords(values ...int64) hexutil.Bytes {
+	var encoded []byte
+	for _, v := range values {
+		encoded = append(encoded, common.BigToHash(big.NewInt(v)).Bytes()...)
+	}
+	return encoded
+}
+
+// withL2Predeploys makes the fake node mine every sent transaction and return canned results of L2 predeploys: Optimism gas
+// price oracle returns l2TestL1Fee as L1 fee and Arbitrum node interface returns (30_000, 9_000, 100, 50) as gas estimate components
+func withL2Predeploys(chainType string) fakeNodeClientOption {
+	return fakeNodeClientOption{
+		node: func(n *fakeNode) {
+			n.autoMine = true
+			n.callResults[seth.OptimismGasPriceOracleAddress] = abiWords(l2TestL1Fee)
+			n.callResults[seth.ArbitrumNodeInterfaceAddress] = abiWords(30_000, 9_000, 100, 50)
+			n.receiptFields["l1Fee"] = hexutil.EncodeBig(big.NewInt(l2TestL1Fee))
+		},
+		config: func(cfg *seth.Config) {
+			cfg.Network.ChainType = chainType
+		},
+	}
+}
+
+// unpackCallInput checks that the call input starts with selector of the method and unpacks its arguments
+func unpackCallInput(t *testing.T, input []byte, signature string, argTypes ...string) []interface{} {
+	var args abi.Arguments
+	for _, argType := range argTypes {
+		typ, err := abi.NewType(argType, "", nil)
+		require.NoError(t, err, "failed to create ABI type")
+		args = append(args, abi.Argument{Type: typ})
+	}
+
+	require.GreaterOrEqual(t, len(input), 4, "call input should start with selector")
+	require.Equal(t, crypto.Keccak256([]byte(signature))[:4], input[:4], "incorrect selector of %s", signature)
+	values, err := args.Unpack(input[4:])
+	require.NoError(t, err, "failed to unpack arguments of %s", signature)
+
+	return values
+}
+
+func TestL2FeesUnknownChainTypeIsRejected(t *testing.T) {
+	cfg := &seth.Config{Network: &seth.Network{Name: "l2_test", ChainType: "Solana"}}
+	require.EqualError(t, seth.ValidateConfig(cfg), seth.ErrUnknownChainType, "unknown chain type should be rejected")
+}
+
+func TestL2FeesOptimismTransferFeeIncludesL1Fee(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withL2Predeploys("Optimism"), withKeys(t, 1), withCostLedger())
+	require.Equal(t, seth.ChainType_Optimism, c.Cfg.Network.ChainType, "chain type should be lowercased")
+
+	recipient := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	fee, err := c.EstimateTransferFee(context.Background(), c.Addresses[0], recipient, big.NewInt(1), big.NewInt(fakeNodeGasPrice))
+	require.NoError(t, err, "failed to estimate transfer fee")
+	require.Equal(t, uint64(21_000), fee.GasLimit, "incorrect gas limit")
+	require.Equal(t, big.NewInt(l2TestL1Fee), fee.L1Fee, "L1 fee should come from gas price oracle")
+
+	inputs := node.callInputs(seth.OptimismGasPriceOracleAddress)
+	require.Len(t, inputs, 1, "gas price oracle should be called once")
+	args := unpackCallInput(t, inputs[0], "getL1Fee(bytes)", "bytes")
+	var unsigned types.Transaction
+	require.NoError(t, unsigned.UnmarshalBinary(args[0].([]byte)), "getL1Fee should be called with RLP encoded transaction")
+	require.Equal(t, recipient, *unsigned.To(), "incorrect recipient of encoded transaction")
+	require.Equal(t, big.NewInt(1), unsigned.Value(), "incorrect value of encoded transaction")
+	require.Equal(t, uint64(21_000), unsigned.Gas(), "incorrect gas limit of encoded transaction")
+	require.Equal(t, big.NewInt(fakeNodeGasPrice), unsigned.GasPrice(), "incorrect gas price of encoded transaction")
+	require.Equal(t, big.NewInt(21_000*fakeNodeGasPrice+l2TestL1Fee*(100+seth.L1FeeBufferPercent)/100), fee.Total, "total should include buffered L1 fee")
+
+	node.setBalance(c.Addresses[0], big.NewInt(1_000_000_000))
+	funding, err := c.CalculateSubKeyFunding(2, fakeNodeGasPrice, 0)
+	require.NoError(t, err, "failed to calculate funding")
+	require.Equal(t, fee.Total.Int64(), funding.NetworkTransferFee, "funding should use L2 aware transfer fee")
+}
+
+func TestL2FeesArbitrumTransferFeeUsesNodeInterface(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withL2Predeploys(seth.ChainType_Arbitrum), withKeys(t, 1), withCostLedger())
+
+	recipient := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	fee, err := c.EstimateTransferFee(context.Background(), c.Addresses[0], recipient, big.NewInt(1), big.NewInt(fakeNodeGasPrice))
+	require.NoError(t, err, "failed to estimate transfer fee")
+	require.Equal(t, uint64(30_000), fee.GasLimit, "gas limit should include L1 gas")
+	require.Equal(t, big.NewInt(9_000*fakeNodeGasPrice), fee.L1Fee, "incorrect L1 fee")
+	require.Equal(t, big.NewInt(30_000*fakeNodeGasPrice), fee.Total, "L1 fee is paid with L2 gas")
+
+	l1Fee, err := c.EstimateL1Fee(context.Background(), ethereum.CallMsg{From: c.Addresses[0], Data: []byte{0x60, 0x00}})
+	require.NoError(t, err, "failed to estimate L1 fee")
+	require.Equal(t, big.NewInt(9_000*100), l1Fee, "L1 fee should be L1 gas * base fee")
+
+	inputs := node.callInputs(seth.ArbitrumNodeInterfaceAddress)
+	require.Len(t, inputs, 2, "node interface should be called for each estimation")
+	args := unpackCallInput(t, inputs[0], "gasEstimateComponents(address,bool,bytes)", "address", "bool", "bytes")
+	require.Equal(t, []interface{}{recipient, false, []byte{}}, args, "transfer should be estimated as a call to the recipient")
+	args = unpackCallInput(t, inputs[1], "gasEstimateComponents(address,bool,bytes)", "address", "bool", "bytes")
+	require.Equal(t, []interface{}{common.Address{}, true, []byte{0x60, 0x00}}, args, "message without recipient should be estimated as contract creation")
+}
+
+func TestL2FeesReturnFundsLeavesL1FeeAndRecordsCost(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withL2Predeploys(seth.ChainType_Optimism), withKeys(t, 2), withCostLedger())
+	balance := big.NewInt(1_000_000_000)
+	node.setBalance(c.Addresses[1], balance)
+
+	require.NoError(t, seth.ReturnFunds(c, ""), "failed to return funds")
+	sent := node.sentTxs()
+	require.Len(t, sent, 1, "one transfer should be sent")
+
+	expectedValue := new(big.Int).Sub(balance, big.NewInt(21_000*fakeNodeGasPrice+l2TestL1Fee*(100+seth.L1FeeBufferPercent)/100))
+	require.Equal(t, expectedValue, sent[0].Value(), "returned funds should leave room for L1 fee")
+	require.Equal(t, c.Addresses[0], *sent[0].To(), "funds should be returned to the root key")
+
+	entries := c.CostLedger.Entries()
+	require.Len(t, entries, 1, "transfer should be recorded in cost ledger")
+	require.Equal(t, 1, entries[0].KeyNum, "incorrect sender key")
+	require.Equal(t, seth.CostLedgerMethod_Transfer, entries[0].Method, "incorrect method")
+	require.Equal(t, big.NewInt(l2TestL1Fee), entries[0].L1Fee, "L1 fee should come from receipt")
+	require.Equal(t, big.NewInt(21_000*fakeNodeGasPrice+l2TestL1Fee), entries[0].Cost, "cost should include L1 fee")
+}
+
+func TestL2FeesReturnFundsUsesGasLimitOfReservedFee(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withL2Predeploys(seth.ChainType_Arbitrum), withKeys(t, 2), withCostLedger())
+	balance := big.NewInt(1_000_000_000)
+	node.setBalance(c.Addresses[1], balance)
+
+	require.NoError(t, seth.ReturnFunds(c, ""), "failed to return funds")
+	sent := node.sentTxs()
+	require.Len(t, sent, 1, "one transfer should be sent")
+	require.Equal(t, uint64(30_000), sent[0].Gas(), "transfer should use gas limit including L1 gas, for which the fee was reserved")
+	require.Equal(t, new(big.Int).Sub(balance, big.NewInt(30_000*fakeNodeGasPrice)), sent[0].Value(), "returned funds should leave room for the whole fee")
+}
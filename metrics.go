@@ -0,0 +1,334 @@
+package seth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	MetricsNamespace = "seth"
+	MetricsPath      = "/metrics"
+	// MetricsRPCBatch is the method label of batched RPC requests
+	MetricsRPCBatch = "batch"
+)
+
+const (
+	// GasEstimationFallback_LegacyFees is recorded, when legacy gas price couldn't be estimated and hardcoded one was used
+	GasEstimationFallback_LegacyFees = "legacy_fees"
+	// GasEstimationFallback_EIP1559Fees is recorded, when EIP-1559 fees couldn't be estimated and hardcoded ones were used
+	GasEstimationFallback_EIP1559Fees = "eip1559_fees"
+	// GasEstimationFallback_GasLimit is recorded, when gas limit of a transfer couldn't be estimated and hardcoded one was used
+	GasEstimationFallback_GasLimit = "gas_limit"
+)
+
+const (
+	ErrMetricsListenAddress = "invalid metrics listen address"
+)
+
+// MetricsConfig controls Prometheus metrics of client activity
+type MetricsConfig struct {
+	Enabled bool `toml:"enabled"`
+	// ListenAddress, if set, starts HTTP server serving metrics at /metrics, e.g. ":9090". Otherwise register client.Metrics in your own registry
+	ListenAddress string `toml:"listen_address"`
+}
+
+// MetricsEnabled returns true if metrics are enabled
+func (c *Config) MetricsEnabled() bool {
+	return c.Metrics != nil && c.Metrics.Enabled
+}
+
+// validateMetricsConfig checks that listen address, if set, is a valid host:port
+func validateMetricsConfig(cfg *MetricsConfig) error {
+	if cfg == nil || !cfg.Enabled || cfg.ListenAddress == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(cfg.ListenAddress); err != nil {
+		return errors.Wrap(err, ErrMetricsListenAddress)
+	}
+	return nil
+}
+
+// Metrics are Prometheus metrics of client activity, all labelled with network name. Metrics implement prometheus.Collector,
+// so they can be registered in any registry, e.g. prometheus.MustRegister(client.Metrics)
+type Metrics struct {
+	// TransactionsSent are transactions accepted by eth_sendRawTransaction, recorded only for HTTP RPC URLs
+	TransactionsSent     prometheus.Counter
+	TransactionsMined    prometheus.Counter
+	TransactionsReverted prometheus.Counter
+	TransactionsBumped   prometheus.Counter
+	// TimeToMine is the time from the moment Seth started waiting for a transaction (including gas bumping) until it was mined
+	TimeToMine prometheus.Histogram
+	// GasUsed is gas used by mined transactions by "Contract.method"
+	GasUsed *prometheus.HistogramVec
+	// RPCDuration and RPCErrors are recorded by JSON-RPC method, only for HTTP RPC URLs
+	RPCDuration *prometheus.HistogramVec
+	RPCErrors   *prometheus.CounterVec
+	// KeySyncWait is the time spent waiting for a synced key, when nonce manager is used
+	KeySyncWait     prometheus.Histogram
+	KeySyncTimeouts prometheus.Counter
+	// GasEstimationFallbacks are recorded by estimation type, when hardcoded values from config were used, because estimation failed
+	GasEstimationFallbacks *prometheus.CounterVec
+
+	collectors []prometheus.Collector
+}
+
+// NewMetrics creates metrics labelled with given network name
+func NewMetrics(network string) *Metrics {
+	labels := prometheus.Labels{"network": network}
+	m := &Metrics{
+		TransactionsSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "transactions_sent_total", ConstLabels: labels,
+			Help: "Transactions sent by Seth, including replacements",
+		}),
+		TransactionsMined: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "transactions_mined_total", ConstLabels: labels,
+			Help: "Transactions whose receipt was received, including reverted ones",
+		}),
+		TransactionsReverted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "transactions_reverted_total", ConstLabels: labels,
+			Help: "Mined transactions that were reverted",
+		}),
+		TransactionsBumped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "transactions_bumped_total", ConstLabels: labels,
+			Help: "Replacement transactions sent with bumped gas price",
+		}),
+		TimeToMine: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace, Name: "transaction_time_to_mine_seconds", ConstLabels: labels,
+			Help:    "Time from start of waiting for a transaction until it was mined",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 15, 30, 60, 120, 300},
+		}),
+		GasUsed: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace, Name: "transaction_gas_used", ConstLabels: labels,
+			Help:    "Gas used by mined transactions by Contract.method",
+			Buckets: prometheus.ExponentialBuckets(21_000, 2, 10),
+		}, []string{"method"}),
+		RPCDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace, Name: "rpc_request_duration_seconds", ConstLabels: labels,
+			Help:    "Duration of JSON-RPC requests by method",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		RPCErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "rpc_errors_total", ConstLabels: labels,
+			Help: "Failed JSON-RPC requests by method",
+		}, []string{"method"}),
+		KeySyncWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: MetricsNamespace, Name: "key_sync_wait_seconds", ConstLabels: labels,
+			Help:    "Time spent waiting for a synced key",
+			Buckets: prometheus.DefBuckets,
+		}),
+		KeySyncTimeouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "key_sync_timeouts_total", ConstLabels: labels,
+			Help: "Times no key was synced before key sync timeout",
+		}),
+		GasEstimationFallbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: MetricsNamespace, Name: "gas_estimation_fallbacks_total", ConstLabels: labels,
+			Help: "Times estimation failed and hardcoded values from config were used, by estimation type",
+		}, []string{"estimation"}),
+	}
+	m.collectors = []prometheus.Collector{
+		m.TransactionsSent, m.TransactionsMined, m.TransactionsReverted, m.TransactionsBumped, m.TimeToMine, m.GasUsed,
+		m.RPCDuration, m.RPCErrors, m.KeySyncWait, m.KeySyncTimeouts, m.GasEstimationFallbacks,
+	}
+	return m
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors {
+		c.Collect(ch)
+	}
+}
+
+// Handler returns HTTP handler serving only these metrics
+func (m *Metrics) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts HTTP server serving metrics at /metrics on given address. Returned function stops the server and releases the address
+func (m *Metrics) Serve(address string) (func() error, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start metrics server")
+	}
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, m.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			L.Error().Err(err).Msg("Metrics server failed")
+		}
+	}()
+
+	L.Info().Str("Address", listener.Addr().String()).Str("Path", MetricsPath).Msg("Serving metrics")
+	return server.Close, nil
+}
+
+// RPCTransport wraps HTTP transport, so that duration and errors of JSON-RPC requests sent through it, as well as transactions
+// accepted by the node, are recorded
+func (m *Metrics) RPCTransport(transport http.RoundTripper) http.RoundTripper {
+	return &metricsTransport{metrics: m, transport: transport}
+}
+
+type metricsTransport struct {
+	metrics   *Metrics
+	transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := MetricsRPCBatch
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		method = rpcMethod(body)
+	}
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	t.metrics.RPCDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		t.metrics.RPCErrors.WithLabelValues(method).Inc()
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		t.metrics.RPCErrors.WithLabelValues(method).Inc()
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || rpcResponseFailed(body) {
+		t.metrics.RPCErrors.WithLabelValues(method).Inc()
+		return resp, nil
+	}
+	// every transaction accepted by the node is counted, no matter if Seth waits for it to be mined
+	if method == "eth_sendRawTransaction" {
+		t.metrics.TransactionsSent.Inc()
+	}
+	return resp, nil
+}
+
+// rpcMethod returns method of JSON-RPC request or "batch" for batched requests
+func rpcMethod(body []byte) string {
+	var request struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil || request.Method == "" {
+		return MetricsRPCBatch
+	}
+	return request.Method
+}
+
+// rpcResponseFailed returns true if JSON-RPC response (or any response in a batch) has an error
+func rpcResponseFailed(body []byte) bool {
+	type response struct {
+		Error json.RawMessage `json:"error"`
+	}
+	var single response
+	if err := json.Unmarshal(body, &single); err == nil {
+		return len(single.Error) > 0 && string(single.Error) != "null"
+	}
+	var batch []response
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return true
+	}
+	for _, r := range batch {
+		if len(r.Error) > 0 && string(r.Error) != "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTransactionBumped increments bumped transactions, if metrics are enabled. Replacement is counted as sent by RPC transport.
+func (m *Client) recordTransactionBumped() {
+	if m.Metrics == nil {
+		return
+	}
+	m.Metrics.TransactionsBumped.Inc()
+}
+
+// recordEstimationFallback increments estimation fallbacks of given type, if metrics are enabled
+func (m *Client) recordEstimationFallback(estimation string) {
+	if m.Metrics == nil {
+		return
+	}
+	m.Metrics.GasEstimationFallbacks.WithLabelValues(estimation).Inc()
+}
+
+// recordTransactionMined records mined transaction, its gas used by "Contract.method" and time since waiting for it started, if metrics
+// are enabled. If receipt is nil, it's fetched from the node. If contract name is empty, it's looked up in the contract map.
+func (m *Client) recordTransactionMined(tx *types.Transaction, receipt *types.Receipt, contract, method string, waitStart time.Time) {
+	if m.Metrics == nil || tx == nil {
+		return
+	}
+
+	if receipt == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+		defer cancel()
+		var err error
+		receipt, err = m.Client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			L.Debug().Err(err).Str("TxHash", tx.Hash().Hex()).Msg("Failed to get transaction receipt. Transaction won't be recorded in metrics")
+			return
+		}
+	}
+
+	m.Metrics.TransactionsMined.Inc()
+	if receipt.Status == types.ReceiptStatusFailed {
+		m.Metrics.TransactionsReverted.Inc()
+	}
+	m.Metrics.TimeToMine.Observe(time.Since(waitStart).Seconds())
+
+	if method == "" {
+		switch {
+		case tx.To() == nil:
+			method = CostLedgerMethod_Deployment
+		case len(tx.Data()) == 0:
+			method = CostLedgerMethod_Transfer
+		default:
+			method = CostLedgerUnknown
+		}
+	}
+	if contract == "" && m.ContractAddressToNameMap.mu != nil {
+		var addr common.Address
+		switch {
+		case tx.To() != nil:
+			addr = *tx.To()
+		case receipt.ContractAddress != (common.Address{}):
+			addr = receipt.ContractAddress
+		}
+		contract = m.ContractAddressToNameMap.GetContractName(addr.Hex())
+	}
+	if contract == "" {
+		contract = CostLedgerUnknown
+	}
+	m.Metrics.GasUsed.WithLabelValues(fmt.Sprintf("%s.%s", contract, method)).Observe(float64(receipt.GasUsed))
+}
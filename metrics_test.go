@@ -0,0 +1,109 @@
+package seth_test
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func TestMetricsRecordRPCRequestsByMethod(t *testing.T) {
+	node := newFakeNode(t)
+
+	metrics := seth.NewMetrics("metrics_test")
+	rpcClient, err := rpc.DialOptions(context.Background(), node.URL, rpc.WithHTTPClient(&http.Client{
+		Transport: metrics.RPCTransport(http.DefaultTransport),
+	}))
+	require.NoError(t, err, "failed to dial fake node")
+	t.Cleanup(rpcClient.Close)
+
+	var chainID string
+	require.NoError(t, rpcClient.Call(&chainID, "eth_chainId"), "failed to get chain ID")
+	require.NoError(t, rpcClient.Call(&chainID, "eth_chainId"), "failed to get chain ID")
+	require.Error(t, rpcClient.Call(&chainID, "eth_unknown"), "unknown method should fail")
+
+	require.Equal(t, 2, testutil.CollectAndCount(metrics.RPCDuration), "duration should be recorded for every method")
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.RPCErrors.WithLabelValues("eth_chainId")), "successful requests aren't errors")
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.RPCErrors.WithLabelValues("eth_unknown")), "JSON-RPC error should be recorded")
+
+	metricsServer := httptest.NewServer(metrics.Handler())
+	t.Cleanup(metricsServer.Close)
+	resp, err := http.Get(metricsServer.URL)
+	require.NoError(t, err, "failed to get metrics")
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "failed to read metrics")
+	require.Contains(t, string(body), `seth_rpc_errors_total{method="eth_unknown",network="metrics_test"} 1`, "metrics should be served with network label")
+	require.Contains(t, string(body), `seth_rpc_request_duration_seconds_count{method="eth_chainId",network="metrics_test"} 2`, "metrics should be served with network label")
+}
+
+func TestMetricsRecordReplacements(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withMetrics(""))
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := signPending(t, c, node, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1_000), Gas: 21_000, To: &to, Value: big.NewInt(0)})
+	node.underpriced = 1
+
+	_, err := c.Cancel(tx)
+	require.NoError(t, err, "failed to cancel transaction")
+	require.Equal(t, float64(1), testutil.ToFloat64(c.Metrics.TransactionsBumped), "only accepted replacement should be recorded")
+	require.Equal(t, float64(1), testutil.ToFloat64(c.Metrics.TransactionsSent), "replacement should be recorded as sent")
+	require.Equal(t, float64(0), testutil.ToFloat64(c.Metrics.TransactionsMined), "nothing was mined")
+}
+
+func TestMetricsRecordTransactionsSentOnlyWhenAccepted(t *testing.T) {
+	node := newFakeNode(t)
+	node.autoMine = true
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	node.code[to] = hexutil.Bytes{0x60}
+	c := newFakeNodeClient(t, node, withMetrics(""))
+	contract := bind.NewBoundContract(to, abi.ABI{}, c.Client, c.Client, c.Client)
+
+	node.underpriced = 1
+	_, err := c.Decode(contract.RawTransact(c.NewTXOpts(), nil))
+	require.Error(t, err, "transaction should be rejected")
+	require.Equal(t, float64(0), testutil.ToFloat64(c.Metrics.TransactionsSent), "rejected transaction should not be recorded as sent")
+
+	tx, err := contract.RawTransact(c.NewTXOpts(), nil)
+	require.NoError(t, err, "failed to send transaction")
+	_, err = c.Decode(tx, nil)
+	require.NoError(t, err, "failed to decode transaction")
+	_, err = c.Decode(tx, nil)
+	require.NoError(t, err, "failed to decode transaction")
+	require.Equal(t, float64(1), testutil.ToFloat64(c.Metrics.TransactionsSent), "accepted transaction should be recorded once")
+
+	_, err = contract.RawTransact(c.NewTXOpts(), nil)
+	require.NoError(t, err, "failed to send transaction")
+	require.Equal(t, float64(2), testutil.ToFloat64(c.Metrics.TransactionsSent), "transaction should be recorded as sent, even if it's never decoded")
+}
+
+func TestMetricsServerIsStoppedByCancelFunc(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "failed to find free port")
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close(), "failed to release port")
+
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withMetrics(address))
+	resp, err := http.Get("http://" + address + seth.MetricsPath)
+	require.NoError(t, err, "metrics should be served")
+	require.NoError(t, resp.Body.Close(), "failed to close response body")
+	require.Equal(t, http.StatusOK, resp.StatusCode, "metrics should be served")
+
+	c.CancelFunc()
+	newFakeNodeClient(t, node, withMetrics(address))
+}
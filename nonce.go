@@ -93,14 +93,21 @@ func (m *NonceManager) NextNonce(addr common.Address) *big.Int {
 func (m *NonceManager) anySyncedKey() int {
 	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.KeySyncTimeout.Duration())
 	defer cancel()
+	waitStart := time.Now()
 	select {
 	case <-ctx.Done():
 		m.Lock()
 		defer m.Unlock()
+		if m.Client.Metrics != nil {
+			m.Client.Metrics.KeySyncTimeouts.Inc()
+		}
 		L.Error().Msg(ErrKeySyncTimeout)
 		m.Client.Errors = append(m.Client.Errors, errors.New(ErrKeySync))
 		return TimeoutKeyNum //so that it's pretty uniqe number of invalid key
 	case keyData := <-m.SyncedKeys:
+		if m.Client.Metrics != nil {
+			m.Client.Metrics.KeySyncWait.Observe(time.Since(waitStart).Seconds())
+		}
 		L.Trace().
 			Interface("KeyNum", keyData.KeyNum).
 			Uint64("Nonce", keyData.Nonce).
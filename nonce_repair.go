@@ -0,0 +1,236 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+const (
+	ErrNonceFixTimeout = "key still has pending transactions"
+)
+
+// NonceFixStatus is the nonce status of a single loaded key, with queued transactions
+type NonceFixStatus struct {
+	KeyNum  int            `json:"key_num"`
+	Address common.Address `json:"address"`
+	NonceStatus
+	// Queued are nonces of transactions waiting in the transaction pool for a missing lower nonce (a gap). They are known
+	// only if the node exposes its transaction pool (txpool_contentFrom or txpool_content)
+	Queued []uint64 `json:"queued,omitempty"`
+}
+
+// Pending returns the number of pending transactions, that is transactions with nonces between latest and pending nonce
+func (s NonceFixStatus) Pending() uint64 {
+	if s.PendingNonce < s.LastNonce {
+		return 0
+	}
+	return s.PendingNonce - s.LastNonce
+}
+
+// IsClean returns true if key has no pending or queued transactions
+func (s NonceFixStatus) IsClean() bool {
+	return s.Pending() == 0 && len(s.Queued) == 0
+}
+
+// NonceFixResult is the result of replacing pending transactions of a single key
+type NonceFixResult struct {
+	Before NonceFixStatus `json:"before"`
+	After  NonceFixStatus `json:"after"`
+	// Replacements are hashes of the last no-op self-transfers sent for every nonce
+	Replacements map[uint64]string `json:"replacements"`
+}
+
+// GetNonceFixStatus returns latest and pending nonce of the key and nonces of queued transactions, if node exposes them
+func (m *Client) GetNonceFixStatus(keyNum int) (NonceFixStatus, error) {
+	status, _, err := m.nonceFixStatus(keyNum)
+	return status, err
+}
+
+// nonceFixStatus returns nonce status of the key and its transactions from the transaction pool by nonce, if node exposes them
+func (m *Client) nonceFixStatus(keyNum int) (NonceFixStatus, map[uint64]*types.Transaction, error) {
+	if err := m.validateKeyNum(keyNum); err != nil {
+		return NonceFixStatus{}, nil, err
+	}
+	address := m.Addresses[keyNum]
+	nonceStatus, err := m.getNonceStatus(address)
+	if err != nil {
+		return NonceFixStatus{}, nil, err
+	}
+	status := NonceFixStatus{KeyNum: keyNum, Address: address, NonceStatus: nonceStatus}
+
+	pool, queued, err := m.txPoolContent(address)
+	if err != nil {
+		L.Debug().Err(err).Msg("Transaction pool isn't available, gaps in nonces won't be detected")
+		return status, nil, nil
+	}
+	for nonce := range queued {
+		if nonce >= status.PendingNonce {
+			status.Queued = append(status.Queued, nonce)
+		}
+		pool[nonce] = queued[nonce]
+	}
+	sort.Slice(status.Queued, func(i, j int) bool { return status.Queued[i] < status.Queued[j] })
+
+	return status, pool, nil
+}
+
+func (m *Client) validateKeyNum(keyNum int) error {
+	if keyNum > len(m.Addresses)-1 || keyNum < 0 {
+		return fmt.Errorf("keyNum is out of range. Expected %d-%d. Got: %d", 0, len(m.Addresses)-1, keyNum)
+	}
+	return nil
+}
+
+// txPoolContent returns pending and queued transactions sent from the address by nonce. It uses txpool_contentFrom and falls back to
+// txpool_content for nodes that don't support it
+func (m *Client) txPoolContent(address common.Address) (map[uint64]*types.Transaction, map[uint64]*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	defer cancel()
+
+	var content struct {
+		Pending map[string]*types.Transaction `json:"pending"`
+		Queued  map[string]*types.Transaction `json:"queued"`
+	}
+	err := m.Client.Client().CallContext(ctx, &content, "txpool_contentFrom", address)
+	if err != nil {
+		var allContent struct {
+			Pending map[common.Address]map[string]*types.Transaction `json:"pending"`
+			Queued  map[common.Address]map[string]*types.Transaction `json:"queued"`
+		}
+		if fallbackErr := m.Client.Client().CallContext(ctx, &allContent, "txpool_content"); fallbackErr != nil {
+			return nil, nil, errors.Wrap(err, "failed to get transaction pool content")
+		}
+		content.Pending, content.Queued = allContent.Pending[address], allContent.Queued[address]
+	}
+
+	pending, err := txsByNonce(content.Pending)
+	if err != nil {
+		return nil, nil, err
+	}
+	queued, err := txsByNonce(content.Queued)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pending, queued, nil
+}
+
+func txsByNonce(txs map[string]*types.Transaction) (map[uint64]*types.Transaction, error) {
+	byNonce := make(map[uint64]*types.Transaction, len(txs))
+	for nonce, tx := range txs {
+		n, err := strconv.ParseUint(nonce, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid nonce %s in transaction pool", nonce)
+		}
+		byNonce[n] = tx
+	}
+	return byNonce, nil
+}
+
+// FixNonce replaces pending transactions of the key with no-op self-transfers and fills gaps in nonces before queued transactions,
+// until pending nonce equals latest nonce. Fees are bumped with gas bump strategy from config (by at least 10%), starting from fees
+// of the replaced transaction if node exposes its transaction pool, or from current fees otherwise. Replacements that aren't mined
+// within transaction timeout are bumped again. Returns error if the key still has pending transactions after timeout.
+func (m *Client) FixNonce(keyNum int, timeout time.Duration) (*NonceFixResult, error) {
+	status, pool, err := m.nonceFixStatus(keyNum)
+	if err != nil {
+		return nil, err
+	}
+	result := &NonceFixResult{Before: status, After: status, Replacements: make(map[uint64]string)}
+
+	address, privateKey := m.Addresses[keyNum], m.PrivateKeys[keyNum]
+	signer := types.LatestSignerForChainID(big.NewInt(m.ChainID))
+	strategy := withMinimumReplacementBump(m.gasBumpStrategy())
+	// last replacement sent for every nonce, it has to be outbid if it's not mined in time
+	sent := make(map[uint64]*types.Transaction)
+	deadline := time.Now().Add(timeout)
+
+	for !status.IsClean() {
+		if time.Now().After(deadline) {
+			return result, fmt.Errorf("%s: after %s key %d (%s) has %d pending and %d queued transactions", ErrNonceFixTimeout, timeout, keyNum, address.Hex(), status.Pending(), len(status.Queued))
+		}
+
+		upTo := status.PendingNonce
+		if len(status.Queued) > 0 {
+			upTo = status.Queued[len(status.Queued)-1] + 1
+		}
+		for nonce := status.LastNonce; nonce < upTo; nonce++ {
+			if _, inPool := pool[nonce]; inPool && nonce >= status.PendingNonce {
+				// queued transaction becomes pending once the gap is filled, it's replaced in the next round if it's stuck
+				continue
+			}
+			previous, ok := pool[nonce]
+			if !ok {
+				previous, ok = sent[nonce]
+			}
+			if !ok {
+				previous = m.nonceFixTemplate(address, nonce)
+			}
+
+			replacement, err := m.signAndSendReplacement(previous, address, privateKey, signer, strategy, true)
+			if err != nil {
+				if strings.Contains(err.Error(), "nonce too low") {
+					L.Debug().Uint64("Nonce", nonce).Msg("Transaction was mined before it was replaced")
+					continue
+				}
+				return result, errors.Wrapf(err, "failed to replace transaction with nonce %d", nonce)
+			}
+			sent[nonce] = replacement
+			result.Replacements[nonce] = replacement.Hash().Hex()
+			L.Info().
+				Int("KeyNum", keyNum).
+				Uint64("Nonce", nonce).
+				Str("Replacement tx hash", replacement.Hash().Hex()).
+				Msg("Sent no-op self-transfer")
+		}
+
+		waitTimeout := m.Cfg.Network.TxnTimeout.Duration()
+		if untilDeadline := time.Until(deadline); untilDeadline < waitTimeout {
+			waitTimeout = untilDeadline
+		}
+		if err := m.WaitUntilNoPendingTx(address, waitTimeout); err != nil {
+			L.Warn().Err(err).Msg("Replacements weren't mined in time, bumping their fees")
+		}
+
+		status, pool, err = m.nonceFixStatus(keyNum)
+		if err != nil {
+			return result, err
+		}
+		result.After = status
+	}
+
+	return result, nil
+}
+
+// nonceFixTemplate returns unsigned self-transfer with given nonce and current fees, used as the base for replacement of a transaction
+// that isn't known, because node doesn't expose its transaction pool, or to fill a gap in nonces
+func (m *Client) nonceFixTemplate(address common.Address, nonce uint64) *types.Transaction {
+	estimations := m.CalculateGasEstimations(m.NewDefaultGasEstimationRequest())
+	if m.Cfg.Network.EIP1559DynamicFees {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(m.ChainID),
+			Nonce:     nonce,
+			GasFeeCap: estimations.GasFeeCap,
+			GasTipCap: estimations.GasTipCap,
+			Gas:       params.TxGas,
+			To:        &address,
+			Value:     big.NewInt(0),
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: estimations.GasPrice,
+		Gas:      params.TxGas,
+		To:       &address,
+		Value:    big.NewInt(0),
+	})
+}
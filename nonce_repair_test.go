@@ -0,0 +1,77 @@
+package seth_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+func addToPool(t *testing.T, c *seth.Client, node *fakeNode, nonce uint64, gasPrice int64) {
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx, err := types.SignNewTx(c.PrivateKeys[0], types.LatestSignerForChainID(big.NewInt(c.ChainID)), &types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: big.NewInt(gasPrice),
+		Gas:      100_000,
+		To:       &to,
+		Value:    big.NewInt(1),
+	})
+	require.NoError(t, err, "failed to sign transaction")
+	node.addPending(t, tx)
+}
+
+func TestFixNonceFillsGapsAndReplacesPendingTransactions(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withTxnTimeout(time.Second))
+	node.setLatestNonce(c.Addresses[0], 2)
+	addToPool(t, c, node, 2, 5_000)
+	addToPool(t, c, node, 5, 5_000)
+
+	status, err := c.GetNonceFixStatus(0)
+	require.NoError(t, err, "failed to get nonce status")
+	require.Equal(t, uint64(2), status.LastNonce, "incorrect latest nonce")
+	require.Equal(t, uint64(3), status.PendingNonce, "incorrect pending nonce")
+	require.Equal(t, []uint64{5}, status.Queued, "transaction after the gap should be queued")
+	require.False(t, status.IsClean(), "key with pending transactions isn't clean")
+
+	node.autoMine = true
+	result, err := c.FixNonce(0, 10*time.Second)
+	require.NoError(t, err, "failed to fix nonce")
+	require.True(t, result.After.IsClean(), "key should be clean after fix")
+	require.Equal(t, uint64(6), result.After.LastNonce, "queued transaction should be mined after the gap is filled")
+	require.Len(t, result.Replacements, 3, "pending transaction should be replaced and the gap filled")
+
+	sent := node.sentTxs()
+	require.Len(t, sent, 3, "queued transaction shouldn't be replaced")
+	for _, tx := range sent {
+		require.Equal(t, c.Addresses[0], *tx.To(), "replacement should be a self-transfer")
+		require.Equal(t, big.NewInt(0), tx.Value(), "replacement should have zero value")
+	}
+	require.Equal(t, big.NewInt(5_500), sent[0].GasPrice(), "pending transaction should be outbid")
+	require.Equal(t, big.NewInt(1_100), sent[1].GasPrice(), "gap should be filled with bumped current gas price")
+}
+
+func TestFixNonceBumpsReplacementsUntilTimeout(t *testing.T) {
+	node := newFakeNode(t)
+	node.txPoolDisabled = true
+	c := newFakeNodeClient(t, node, withTxnTimeout(time.Second))
+	addToPool(t, c, node, 0, 100)
+
+	_, err := c.FixNonce(1, time.Second)
+	require.Error(t, err, "key out of range should be rejected")
+
+	result, err := c.FixNonce(0, 1500*time.Millisecond)
+	require.ErrorContains(t, err, seth.ErrNonceFixTimeout, "fix should time out if replacements aren't mined")
+	require.Equal(t, uint64(1), result.After.Pending(), "transaction should still be pending")
+	require.Empty(t, result.After.Queued, "queued transactions aren't known without transaction pool")
+
+	sent := node.sentTxs()
+	require.Len(t, sent, 2, "replacement that wasn't mined in time should be bumped again")
+	require.Equal(t, big.NewInt(1_100), sent[0].GasPrice(), "without transaction pool replacement should start from current gas price")
+	require.Equal(t, big.NewInt(1_210), sent[1].GasPrice(), "replacement should be bumped")
+}
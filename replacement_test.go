@@ -0,0 +1,95 @@
+package seth_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// signPending signs the transaction with root key and adds it to the transaction pool
+func signPending(t *testing.T, c *seth.Client, node *fakeNode, txData types.TxData) *types.Transaction {
+	tx, err := types.SignNewTx(c.PrivateKeys[0], types.LatestSignerForChainID(big.NewInt(c.ChainID)), txData)
+	require.NoError(t, err, "failed to sign transaction")
+	node.addPending(t, tx)
+	return tx
+}
+
+func TestReplacementCancelSendsSelfTransferWithSameNonce(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node)
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := signPending(t, c, node, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(c.ChainID),
+		Nonce:     7,
+		GasFeeCap: big.NewInt(100),
+		GasTipCap: big.NewInt(10),
+		Gas:       200_000,
+		To:        &to,
+		Value:     big.NewInt(1),
+		Data:      []byte{0xde, 0xad},
+	})
+
+	replacement, err := c.Cancel(tx)
+	require.NoError(t, err, "failed to cancel transaction")
+	sent := node.sentTxs()
+	require.Len(t, sent, 1, "one replacement should be sent")
+	require.Equal(t, replacement.Hash(), sent[0].Hash(), "returned replacement should be the sent one")
+
+	require.Equal(t, tx.Nonce(), replacement.Nonce(), "replacement should use the same nonce")
+	require.Equal(t, c.Addresses[0], *replacement.To(), "replacement should be sent to self")
+	require.Equal(t, big.NewInt(0), replacement.Value(), "replacement should have zero value")
+	require.Empty(t, replacement.Data(), "replacement should have no data")
+	require.Equal(t, params.TxGas, replacement.Gas(), "replacement should use transfer gas limit")
+	// without gas bumping config we still bump by the minimum required by nodes
+	require.Equal(t, big.NewInt(110), replacement.GasFeeCap(), "fee cap should be bumped by 10%")
+	require.Equal(t, big.NewInt(11), replacement.GasTipCap(), "tip cap should be bumped by 10%")
+}
+
+func TestReplacementSpeedUpKeepsTransactionAndUsesStrategy(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{StrategyFn: seth.NoOpGasBumpStrategyFn}))
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := signPending(t, c, node, &types.LegacyTx{
+		Nonce:    3,
+		GasPrice: big.NewInt(1_000),
+		Gas:      50_000,
+		To:       &to,
+		Value:    big.NewInt(5),
+		Data:     []byte{0x01},
+	})
+
+	replacement, err := c.SpeedUp(tx, seth.PriorityBasedGasBumpingStrategyFn(seth.Priority_Degen))
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(2_000), replacement.GasPrice(), "gas price should be bumped with given strategy")
+	require.Equal(t, tx.Nonce(), replacement.Nonce(), "replacement should use the same nonce")
+	require.Equal(t, tx.To(), replacement.To(), "recipient should not change")
+	require.Equal(t, tx.Value(), replacement.Value(), "value should not change")
+	require.Equal(t, tx.Data(), replacement.Data(), "data should not change")
+	require.Equal(t, tx.Gas(), replacement.Gas(), "gas limit should not change")
+
+	// no-op strategy from config is not enough to replace a transaction
+	replacement, err = c.SpeedUp(replacement, nil)
+	require.NoError(t, err, "failed to speed up transaction")
+	require.Equal(t, big.NewInt(2_200), replacement.GasPrice(), "gas price should be bumped by at least 10%")
+}
+
+func TestReplacementRespectsMaxGasPriceAndPendingStatus(t *testing.T) {
+	node := newFakeNode(t)
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{MaxGasPrice: 1_050, StrategyFn: seth.NoOpGasBumpStrategyFn}))
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := signPending(t, c, node, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1_000), Gas: 21_000, To: &to, Value: big.NewInt(0)})
+
+	_, err := c.Cancel(tx)
+	require.ErrorContains(t, err, "higher than max gas price", "bumped gas price above max should be rejected")
+
+	node.mine(t, tx, types.ReceiptStatusSuccessful)
+	_, err = c.SpeedUp(tx, nil)
+	require.EqualError(t, err, seth.ErrTxNotPending, "mined transaction should not be replaced")
+	require.Empty(t, node.sentTxs(), "no replacement should be sent")
+}
@@ -2,6 +2,7 @@ package seth
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"github.com/holiman/uint256"
 	"math/big"
@@ -9,7 +10,9 @@ import (
 	"time"
 
 	"github.com/avast/retry-go"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/pkg/errors"
 )
 
@@ -21,8 +24,14 @@ const (
 
 const (
 	ErrRetryTimeout = "retry timeout"
+	ErrTxNotPending = "transaction was confirmed before bumping gas"
+	// ErrMaxGasPriceExceeded is returned, when bumped gas price would be higher than gas bump's max gas price
+	ErrMaxGasPriceExceeded = "bumped gas price is higher than max gas price"
 )
 
+// MinReplacementGasBumpPercent is the minimal gas price increase required by nodes (geth's default price bump) to accept a replacement transaction
+const MinReplacementGasBumpPercent = 10
+
 // RetryTxAndDecode executes transaction several times, retries if connection is lost and decodes all the data
 func (m *Client) RetryTxAndDecode(f func() (*types.Transaction, error)) (*DecodedTransaction, error) {
 	var tx *types.Transaction
@@ -105,26 +114,83 @@ var PriorityBasedGasBumpingStrategyFn = func(priority string) GasBumpStrategyFn
 var prepareReplacementTransaction = func(client *Client, tx *types.Transaction) (*types.Transaction, error) {
 	L.Warn().Msgf("Transaction wasn't confirmed in %s. Bumping gas", client.Cfg.Network.TxnTimeout.String())
 
-	ctxPending, cancelPending := context.WithTimeout(context.Background(), client.Cfg.Network.TxnTimeout.Duration())
-	_, isPending, err := client.Client.TransactionByHash(ctxPending, tx.Hash())
+	return client.sendReplacementTransaction(tx, client.Cfg.GasBump.StrategyFn, false)
+}
+
+// SpeedUp sends a replacement of a pending transaction with the same nonce, recipient, value and data, but with gas price (or fee cap and tip cap)
+// bumped using given strategy. If strategy is nil, the one from gas bump config is used. Regardless of the strategy, gas price is bumped by
+// at least 10%, because otherwise the node would reject the replacement. Transaction has to be sent from one of the loaded keys.
+// Returns the signed replacement transaction, which can be passed to Decode() to wait for it to be mined.
+func (m *Client) SpeedUp(tx *types.Transaction, strategy GasBumpStrategyFn) (*types.Transaction, error) {
+	if strategy == nil {
+		strategy = m.gasBumpStrategy()
+	}
+	L.Info().Str("Tx hash", tx.Hash().Hex()).Uint64("Nonce", tx.Nonce()).Msg("Speeding up transaction")
+
+	return m.sendReplacementTransaction(tx, withMinimumReplacementBump(strategy), false)
+}
+
+// Cancel replaces a pending transaction with a zero-value transfer to the sender (using the same nonce), with gas price bumped using
+// gas bump strategy from config (at least by 10%). Transaction has to be sent from one of the loaded keys. Once the replacement is mined,
+// the nonce is no longer blocked. Returns the signed replacement transaction.
+func (m *Client) Cancel(tx *types.Transaction) (*types.Transaction, error) {
+	L.Info().Str("Tx hash", tx.Hash().Hex()).Uint64("Nonce", tx.Nonce()).Msg("Cancelling transaction")
+
+	return m.sendReplacementTransaction(tx, withMinimumReplacementBump(m.gasBumpStrategy()), true)
+}
+
+// gasBumpStrategy returns gas bump strategy function from config, nil if declarative strategy is configured or no-op strategy, if gas bumping is not configured
+func (m *Client) gasBumpStrategy() GasBumpStrategyFn {
+	if m.Cfg.GasBump.HasDeclarativeStrategy() {
+		return nil
+	}
+	if m.Cfg.GasBump != nil && m.Cfg.GasBump.StrategyFn != nil {
+		return m.Cfg.GasBump.StrategyFn
+	}
+	return NoOpGasBumpStrategyFn
+}
+
+// withMinimumReplacementBump wraps the strategy, so that it always bumps gas price at least by MinReplacementGasBumpPercent.
+// Nil strategy (declarative one) is returned as is, because declarative strategies always bump by at least that much.
+func withMinimumReplacementBump(strategy GasBumpStrategyFn) GasBumpStrategyFn {
+	if strategy == nil {
+		return nil
+	}
+	return func(previousGasPrice *big.Int) *big.Int {
+		minimum := minimumReplacementFee(previousGasPrice)
+
+		bumped := strategy(new(big.Int).Set(previousGasPrice))
+		if bumped.Cmp(minimum) < 0 {
+			return minimum
+		}
+		return bumped
+	}
+}
+
+// sendReplacementTransaction signs and sends a transaction with the same nonce as the given pending transaction and gas price bumped with given strategy.
+// If cancel is true, replacement is a zero-value transfer to the sender, otherwise it has the same recipient, value and data as the original transaction.
+func (m *Client) sendReplacementTransaction(tx *types.Transaction, strategy GasBumpStrategyFn, cancel bool) (*types.Transaction, error) {
+	ctxPending, cancelPending := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+	_, isPending, err := m.Client.TransactionByHash(ctxPending, tx.Hash())
 	defer cancelPending()
 	if err != nil {
 		return nil, err
 	}
 
-	if !isPending {
-		L.Debug().Str("Tx hash", tx.Hash().Hex()).Msg("Transaction was confirmed before bumping gas")
-		return nil, errors.New("transaction was confirmed before bumping gas")
-	}
-
 	signer := types.LatestSignerForChainID(tx.ChainId())
 	sender, err := types.Sender(signer, tx)
 	if err != nil {
 		return nil, err
 	}
 
+	if !isPending {
+		L.Debug().Str("Tx hash", tx.Hash().Hex()).Msg("Transaction was confirmed before bumping gas")
+		m.forgetOriginalFees(sender, tx.Nonce())
+		return nil, errors.New(ErrTxNotPending)
+	}
+
 	senderPkIdx := -1
-	for j, maybeSender := range client.Addresses {
+	for j, maybeSender := range m.Addresses {
 		if maybeSender == sender {
 			senderPkIdx = j
 			break
@@ -135,114 +201,229 @@ var prepareReplacementTransaction = func(client *Client, tx *types.Transaction)
 		return nil, fmt.Errorf("sender address '%s' not found in loaded private keys", sender)
 	}
 
-	maxGasPrice := big.NewInt(client.Cfg.GasBump.MaxGasPrice)
-	privateKey := client.PrivateKeys[senderPkIdx]
-	var replacementTx *types.Transaction
+	replacementTx, err := m.signAndSendReplacement(tx, sender, m.PrivateKeys[senderPkIdx], signer, strategy, cancel)
+	if err != nil {
+		return nil, err
+	}
+
+	L.Info().
+		Str("Tx hash", tx.Hash().Hex()).
+		Str("Replacement tx hash", replacementTx.Hash().Hex()).
+		Uint64("Nonce", replacementTx.Nonce()).
+		Msg("Sent replacement transaction")
+
+	return replacementTx, nil
+}
+
+// signAndSendReplacement signs and sends replacement of given transaction. If node says that replacement is underpriced, fees are bumped
+// once more (by at least the minimum nodes require), up to MaxUnderpricedReplacementBumps times.
+func (m *Client) signAndSendReplacement(tx *types.Transaction, sender common.Address, privateKey *ecdsa.PrivateKey, signer types.Signer, strategy GasBumpStrategyFn, cancel bool) (*types.Transaction, error) {
+	key := trackedTxKey{from: sender, nonce: tx.Nonce()}
+
+	previousTx := tx
+	for attempt := 0; ; attempt++ {
+		var minimumBump bool
+		if attempt > 0 {
+			minimumBump = true
+		}
+		replacementTx, err := m.signReplacementTransaction(previousTx, key, sender, privateKey, signer, strategy, cancel, minimumBump)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancelSend := context.WithTimeout(context.Background(), m.Cfg.Network.TxnTimeout.Duration())
+		err = m.Client.SendTransaction(ctx, replacementTx)
+		cancelSend()
+		if isReplacementUnderpriced(err) && attempt < MaxUnderpricedReplacementBumps {
+			L.Warn().Err(err).Int("Attempt", attempt+1).Msg("Replacement transaction is underpriced. Bumping fees once more")
+			previousTx = replacementTx
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.recordTransactionBumped()
+
+		if m.StuckTxWatchdog != nil {
+			m.StuckTxWatchdog.Track(replacementTx)
+		}
+
+		return replacementTx, nil
+	}
+}
 
-	var checkMaxPrice = func(gasPrice, maxGasPrice *big.Int) error {
-		if !client.Cfg.HasMaxBumpGasPrice() {
+// signReplacementTransaction returns signed transaction with the same nonce as given one and fees bumped with the strategy (or declarative strategy from config, if it's nil).
+// If minimumBump is true, every fee is bumped by at least MinReplacementGasBumpPercent regardless of the strategy.
+func (m *Client) signReplacementTransaction(tx *types.Transaction, key trackedTxKey, sender common.Address, privateKey *ecdsa.PrivateKey, signer types.Signer, strategy GasBumpStrategyFn, cancel, minimumBump bool) (*types.Transaction, error) {
+	var checkMaxPrice = func(gasPrice *big.Int) error {
+		if !m.Cfg.HasMaxBumpGasPrice() {
 			L.Debug().Msg("Max gas price for gas bump is not set, skipping check")
 			return nil
 		}
 
+		maxGasPrice := big.NewInt(m.Cfg.GasBump.MaxGasPrice)
 		if gasPrice.Cmp(maxGasPrice) > 0 {
-			return fmt.Errorf("bumped gas price %s is higher than max gas price %s", gasPrice.String(), big.NewInt(client.Cfg.GasBump.MaxGasPrice).String())
+			return fmt.Errorf("%s: %s > %s", ErrMaxGasPriceExceeded, gasPrice.String(), maxGasPrice.String())
 		}
 
 		return nil
 	}
 
+	var bump = func(field string, previous *big.Int) (*big.Int, error) {
+		var minimum *big.Int
+		if minimumBump {
+			minimum = minimumReplacementFee(previous)
+		}
+		return m.bumpFee(key, field, previous, minimum, strategy)
+	}
+
+	// blob pool accepts replacement only if all fees are at least doubled
+	var bumpBlob = func(field string, previous *big.Int) (*big.Int, error) {
+		return m.bumpFee(key, field, previous, new(big.Int).Mul(previous, big.NewInt(2)), strategy)
+	}
+
+	to, value, data, gas, accessList := tx.To(), tx.Value(), tx.Data(), tx.Gas(), tx.AccessList()
+	if cancel {
+		to, value, data, gas, accessList = &sender, big.NewInt(0), nil, params.TxGas, nil
+	}
+
 	switch tx.Type() {
 	case types.LegacyTxType:
-		gasPrice := client.Cfg.GasBump.StrategyFn(tx.GasPrice())
-		if err := checkMaxPrice(gasPrice, maxGasPrice); err != nil {
+		gasPrice, err := bump(GasBumpField_GasPrice, tx.GasPrice())
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMaxPrice(gasPrice); err != nil {
 			return nil, err
 		}
 		L.Warn().Interface("Old gas price", tx.GasPrice()).Interface("New gas price", gasPrice).Msg("Bumping gas price for legacy transaction")
 		txData := &types.LegacyTx{
 			Nonce:    tx.Nonce(),
-			To:       tx.To(),
-			Value:    tx.Value(),
-			Gas:      tx.Gas(),
+			To:       to,
+			Value:    value,
+			Gas:      gas,
 			GasPrice: gasPrice,
-			Data:     tx.Data(),
+			Data:     data,
 		}
-		replacementTx, err = types.SignNewTx(privateKey, signer, txData)
+		return types.SignNewTx(privateKey, signer, txData)
 	case types.DynamicFeeTxType:
-		gasFeeCap := client.Cfg.GasBump.StrategyFn(tx.GasFeeCap())
-		gasTipCap := client.Cfg.GasBump.StrategyFn(tx.GasTipCap())
-		if err := checkMaxPrice(big.NewInt(0).Add(gasFeeCap, gasTipCap), maxGasPrice); err != nil {
+		gasFeeCap, err := bump(GasBumpField_GasFeeCap, tx.GasFeeCap())
+		if err != nil {
+			return nil, err
+		}
+		gasTipCap, err := bump(GasBumpField_GasTipCap, tx.GasTipCap())
+		if err != nil {
+			return nil, err
+		}
+		gasTipCap = capTipAtFeeCap(gasTipCap, gasFeeCap)
+		if err := checkMaxPrice(big.NewInt(0).Add(gasFeeCap, gasTipCap)); err != nil {
 			return nil, err
 		}
 		L.Warn().Interface("Old gas fee cap", tx.GasFeeCap()).Interface("New gas fee cap", gasFeeCap).Interface("Old gas tip cap", tx.GasTipCap()).Interface("New gas tip cap", gasTipCap).Msg("Bumping gas fee cap and tip cap for EIP-1559 transaction")
 		txData := &types.DynamicFeeTx{
-			Nonce:     tx.Nonce(),
-			To:        tx.To(),
-			Value:     tx.Value(),
-			Gas:       tx.Gas(),
-			GasFeeCap: gasFeeCap,
-			GasTipCap: gasTipCap,
-			Data:      tx.Data(),
+			Nonce:      tx.Nonce(),
+			To:         to,
+			Value:      value,
+			Gas:        gas,
+			GasFeeCap:  gasFeeCap,
+			GasTipCap:  gasTipCap,
+			Data:       data,
+			AccessList: accessList,
 		}
 
-		replacementTx, err = types.SignNewTx(privateKey, signer, txData)
+		return types.SignNewTx(privateKey, signer, txData)
 	case types.BlobTxType:
-		if tx.To() == nil {
+		if to == nil {
 			return nil, fmt.Errorf("blob tx with nil recipient is not supported")
 		}
-		gasFeeCap := client.Cfg.GasBump.StrategyFn(tx.GasFeeCap())
-		gasTipCap := client.Cfg.GasBump.StrategyFn(tx.GasTipCap())
-		blobFeeCap := client.Cfg.GasBump.StrategyFn(tx.BlobGasFeeCap())
-		if err := checkMaxPrice(big.NewInt(0).Add(gasFeeCap, big.NewInt(0).Add(gasTipCap, blobFeeCap)), maxGasPrice); err != nil {
+		gasFeeCap, err := bumpBlob(GasBumpField_GasFeeCap, tx.GasFeeCap())
+		if err != nil {
+			return nil, err
+		}
+		gasTipCap, err := bumpBlob(GasBumpField_GasTipCap, tx.GasTipCap())
+		if err != nil {
+			return nil, err
+		}
+		gasTipCap = capTipAtFeeCap(gasTipCap, gasFeeCap)
+		blobFeeCap, err := bumpBlob(GasBumpField_BlobFeeCap, tx.BlobGasFeeCap())
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMaxPrice(big.NewInt(0).Add(gasFeeCap, big.NewInt(0).Add(gasTipCap, blobFeeCap))); err != nil {
+			return nil, err
+		}
+
+		// sidecar is not part of the signed transaction, so it's present only if we have the original transaction object
+		if tx.BlobTxSidecar() == nil {
+			return nil, fmt.Errorf("blob tx without sidecar can't be replaced")
+		}
+
+		blobGasFeeCap, err := toBlobTxUint256("gas fee cap", gasFeeCap)
+		if err != nil {
+			return nil, err
+		}
+		blobGasTipCap, err := toBlobTxUint256("gas tip cap", gasTipCap)
+		if err != nil {
+			return nil, err
+		}
+		blobValue, err := toBlobTxUint256("value", value)
+		if err != nil {
+			return nil, err
+		}
+		blobTxFeeCap, err := toBlobTxUint256("blob fee cap", blobFeeCap)
+		if err != nil {
 			return nil, err
 		}
 
 		L.Warn().Interface("Old gas fee cap", tx.GasFeeCap()).Interface("Old max fee per blob", tx.BlobGasFeeCap()).Interface("New max fee per blob", blobFeeCap).Interface("New gas fee cap", gasFeeCap).Interface("Old gas tip cap", tx.GasTipCap()).Interface("New gas tip cap", gasTipCap).Msg("Bumping gas fee cap and tip cap for Blob transaction")
+		// blob pool doesn't accept non-blob replacements, so cancelling transaction still carries the original blobs
 		txData := &types.BlobTx{
+			ChainID:    uint256.MustFromBig(tx.ChainId()),
 			Nonce:      tx.Nonce(),
-			To:         *tx.To(),
-			Value:      uint256.NewInt(tx.Value().Uint64()),
-			Gas:        tx.Gas(),
-			GasFeeCap:  uint256.NewInt(gasFeeCap.Uint64()),
-			GasTipCap:  uint256.NewInt(gasTipCap.Uint64()),
-			BlobFeeCap: uint256.NewInt(blobFeeCap.Uint64()),
+			To:         *to,
+			Value:      blobValue,
+			Gas:        gas,
+			GasFeeCap:  blobGasFeeCap,
+			GasTipCap:  blobGasTipCap,
+			BlobFeeCap: blobTxFeeCap,
 			BlobHashes: tx.BlobHashes(),
-			Data:       tx.Data(),
+			Data:       data,
+			AccessList: accessList,
+			Sidecar:    tx.BlobTxSidecar(),
 		}
 
-		replacementTx, err = types.SignNewTx(privateKey, signer, txData)
+		return types.SignNewTx(privateKey, signer, txData)
 	case types.AccessListTxType:
-		gasPrice := client.Cfg.GasBump.StrategyFn(tx.GasPrice())
-		if err := checkMaxPrice(gasPrice, maxGasPrice); err != nil {
+		gasPrice, err := bump(GasBumpField_GasPrice, tx.GasPrice())
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMaxPrice(gasPrice); err != nil {
 			return nil, err
 		}
 		L.Warn().Interface("Old gas price", tx.GasPrice()).Interface("New gas price", gasPrice).Msg("Bumping gas price for access list transaction")
 
 		txData := &types.AccessListTx{
+			ChainID:    tx.ChainId(),
 			Nonce:      tx.Nonce(),
-			To:         tx.To(),
-			Value:      tx.Value(),
-			Gas:        tx.Gas(),
-			Data:       tx.Data(),
-			AccessList: tx.AccessList(),
+			To:         to,
+			Value:      value,
+			Gas:        gas,
+			GasPrice:   gasPrice,
+			Data:       data,
+			AccessList: accessList,
 		}
 
-		replacementTx, err = types.SignNewTx(privateKey, signer, txData)
-
+		return types.SignNewTx(privateKey, signer, txData)
 	default:
 		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
 	}
+}
 
-	if err != nil {
-		return nil, err
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), client.Cfg.Network.TxnTimeout.Duration())
-	defer cancel()
-	err = client.Client.SendTransaction(ctx, replacementTx)
-	if err != nil {
-		return nil, err
+// capTipAtFeeCap makes sure that tip cap is not higher than fee cap, which could happen if they have different caps
+func capTipAtFeeCap(gasTipCap, gasFeeCap *big.Int) *big.Int {
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		return new(big.Int).Set(gasFeeCap)
 	}
-
-	return replacementTx, nil
+	return gasTipCap
 }
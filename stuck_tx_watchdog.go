@@ -0,0 +1,576 @@
+package seth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultStuckTxWatchdogCheckInterval   = 5 * time.Second
+	DefaultStuckTxWatchdogMaxReplacements = 10
+)
+
+const (
+	// StuckTxEvent_Replaced is emitted, when stuck transaction was replaced with one with bumped gas price
+	StuckTxEvent_Replaced = "replaced"
+	// StuckTxEvent_ReplacementFailed is emitted, when sending replacement transaction failed. It will be retried during the next check
+	StuckTxEvent_ReplacementFailed = "replacement_failed"
+	// StuckTxEvent_Abandoned is emitted, when transaction is no longer tracked, because max gas price or max number of replacements was reached
+	StuckTxEvent_Abandoned = "abandoned"
+	// StuckTxEvent_Confirmed is emitted, when a transaction that was replaced at least once is no longer pending
+	StuckTxEvent_Confirmed = "confirmed"
+)
+
+const (
+	ErrStuckTxWatchdogStopped = "stuck tx watchdog was stopped before transaction was mined"
+)
+
+// StuckTxWatchdogConfig controls stuck transaction watchdog, which replaces all transactions signed by Seth that are pending
+// longer than network's transaction timeout, no matter if they were passed to Decode() or not
+type StuckTxWatchdogConfig struct {
+	Enabled bool `toml:"enabled"`
+	// CheckInterval is how often pending transactions are checked
+	CheckInterval *Duration `toml:"check_interval"`
+	// MaxReplacements is the maximum number of replacements sent for a single nonce
+	MaxReplacements uint `toml:"max_replacements"`
+}
+
+// StuckTxWatchdogEnabled returns true if stuck transaction watchdog is enabled
+func (c *Config) StuckTxWatchdogEnabled() bool {
+	return c.StuckTxWatchdog != nil && c.StuckTxWatchdog.Enabled
+}
+
+// StuckTxEvent describes what the watchdog did with a stuck transaction
+type StuckTxEvent struct {
+	Type  string
+	From  common.Address
+	Nonce uint64
+	// OriginalTxHash is the hash of the first transaction sent with this nonce
+	OriginalTxHash common.Hash
+	// TxHash is the hash of the transaction that got stuck
+	TxHash common.Hash
+	// ReplacementTxHash is set only for StuckTxEvent_Replaced events
+	ReplacementTxHash common.Hash
+	// Replacements is the number of replacements sent for this nonce so far
+	Replacements uint
+	Err          error
+}
+
+// StuckTxWatchdogStats are the counters of stuck transaction watchdog
+type StuckTxWatchdogStats struct {
+	// Tracked is the number of currently tracked transactions
+	Tracked int
+	// Replaced is the number of replacement transactions sent
+	Replaced uint64
+	// FailedReplacements is the number of replacements that couldn't be sent
+	FailedReplacements uint64
+	// Abandoned is the number of transactions that were given up on
+	Abandoned uint64
+	// Confirmed is the number of replaced transactions, which are no longer pending
+	Confirmed uint64
+}
+
+type trackedTxKey struct {
+	from  common.Address
+	nonce uint64
+}
+
+type trackedTx struct {
+	tx             *types.Transaction
+	originalTxHash common.Hash
+	sentAt         time.Time
+	replacements   uint
+	// sent are all transactions sent with this nonce, the latest one last
+	sent []*types.Transaction
+}
+
+// signedTx is a transaction signed with Seth's transaction options, which might not have been sent yet
+type signedTx struct {
+	tx       *types.Transaction
+	signedAt time.Time
+}
+
+// StuckTxWatchdog tracks every transaction sent with transaction options created by Seth (and blob transactions) and replaces
+// the ones that are pending longer than network's transaction timeout using gas bump strategy (but always by at least 10%) and max gas price from config.
+// Replacements sent by SpeedUp() or Cancel() are tracked as well, so the same nonce isn't bumped twice at the same time. Decode() doesn't
+// bump gas when the watchdog is enabled, instead it waits for the latest transaction the watchdog sent with the same nonce.
+type StuckTxWatchdog struct {
+	client          *Client
+	timeout         time.Duration
+	checkInterval   time.Duration
+	maxReplacements uint
+
+	mu      *sync.Mutex
+	tracked map[trackedTxKey]*trackedTx
+	// signed are transactions signed with Seth's transaction options that weren't confirmed to be sent yet
+	signed    map[common.Hash]*signedTx
+	listeners []func(StuckTxEvent)
+	stats     StuckTxWatchdogStats
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewStuckTxWatchdog creates a new StuckTxWatchdog. Call Start() to begin periodic checks.
+func NewStuckTxWatchdog(client *Client, cfg *StuckTxWatchdogConfig) *StuckTxWatchdog {
+	w := &StuckTxWatchdog{
+		client:          client,
+		timeout:         client.Cfg.Network.TxnTimeout.Duration(),
+		checkInterval:   DefaultStuckTxWatchdogCheckInterval,
+		maxReplacements: DefaultStuckTxWatchdogMaxReplacements,
+		mu:              &sync.Mutex{},
+		tracked:         make(map[trackedTxKey]*trackedTx),
+		signed:          make(map[common.Hash]*signedTx),
+	}
+
+	if cfg != nil {
+		if cfg.CheckInterval != nil && cfg.CheckInterval.Duration() > 0 {
+			w.checkInterval = cfg.CheckInterval.Duration()
+		}
+		if cfg.MaxReplacements > 0 {
+			w.maxReplacements = cfg.MaxReplacements
+		}
+	}
+
+	return w
+}
+
+// Subscribe registers a function, which will be called with every event emitted by the watchdog
+func (w *StuckTxWatchdog) Subscribe(fn func(StuckTxEvent)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Track starts tracking the transaction, which has already been sent. If a transaction with the same sender and nonce is already tracked,
+// it's replaced with the new one and the timeout starts counting again, unless it was sent with this nonce before (e.g. it's the original
+// transaction passed to Decode() after the watchdog had replaced it). Transactions sent from keys that aren't loaded are ignored,
+// because they can't be replaced.
+func (w *StuckTxWatchdog) Track(tx *types.Transaction) {
+	w.track(tx, time.Now())
+}
+
+func (w *StuckTxWatchdog) track(tx *types.Transaction, sentAt time.Time) {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		L.Debug().Err(err).Str("Tx hash", tx.Hash().Hex()).Msg("Stuck tx watchdog failed to get sender. Transaction won't be tracked")
+		return
+	}
+	if !w.isLoadedKey(from) {
+		L.Debug().Str("Tx hash", tx.Hash().Hex()).Str("From", from.Hex()).Msg("Transaction wasn't sent from any of the loaded keys. Stuck tx watchdog won't track it")
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.signed, tx.Hash())
+
+	key := trackedTxKey{from: from, nonce: tx.Nonce()}
+	if existing, ok := w.tracked[key]; ok {
+		for _, sent := range existing.sent {
+			if sent.Hash() == tx.Hash() {
+				return
+			}
+		}
+		existing.tx = tx
+		existing.sentAt = sentAt
+		existing.sent = append(existing.sent, tx)
+		return
+	}
+	w.tracked[key] = &trackedTx{tx: tx, originalTxHash: tx.Hash(), sentAt: sentAt, sent: []*types.Transaction{tx}}
+}
+
+func (w *StuckTxWatchdog) isLoadedKey(address common.Address) bool {
+	for _, a := range w.client.Addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+// trackingSigner wraps signer function, so that every signed transaction is remembered. Signing doesn't mean that the transaction
+// was sent, so it's tracked only once it's passed to Track() (e.g. by Decode()) or found in node's transaction pool during the check.
+func (w *StuckTxWatchdog) trackingSigner(signerFn bind.SignerFn) bind.SignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		signed, err := signerFn(from, tx)
+		if err == nil {
+			w.mu.Lock()
+			w.signed[signed.Hash()] = &signedTx{tx: signed, signedAt: time.Now()}
+			w.mu.Unlock()
+		}
+		return signed, err
+	}
+}
+
+// sentTransactions returns all transactions sent with the same sender and nonce as given transaction, the latest one last, and true
+// if the nonce is still tracked
+func (w *StuckTxWatchdog) sentTransactions(from common.Address, nonce uint64) ([]*types.Transaction, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	t, ok := w.tracked[trackedTxKey{from: from, nonce: nonce}]
+	if !ok {
+		return nil, false
+	}
+	return append([]*types.Transaction{}, t.sent...), true
+}
+
+// confirm stops tracking the nonce, because one of the transactions sent with it was mined
+func (w *StuckTxWatchdog) confirm(from common.Address, nonce uint64, tx *types.Transaction) {
+	key := trackedTxKey{from: from, nonce: nonce}
+
+	w.mu.Lock()
+	t, ok := w.tracked[key]
+	if !ok {
+		w.mu.Unlock()
+		return
+	}
+	event := StuckTxEvent{
+		From:           from,
+		Nonce:          nonce,
+		OriginalTxHash: t.originalTxHash,
+		TxHash:         tx.Hash(),
+		Replacements:   t.replacements,
+	}
+	w.mu.Unlock()
+
+	if event.Replacements > 0 {
+		event.Type = StuckTxEvent_Confirmed
+	}
+	w.untrack(key, event)
+}
+
+// Start starts periodic checks until Stop() is called or context is cancelled
+func (w *StuckTxWatchdog) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				L.Debug().Msg("Stuck tx watchdog stopped")
+				return
+			case <-ticker.C:
+				w.Check(ctx)
+			}
+		}
+	}()
+
+	L.Debug().
+		Str("Timeout", w.timeout.String()).
+		Str("Check interval", w.checkInterval.String()).
+		Uint("Max replacements", w.maxReplacements).
+		Msg("Started stuck tx watchdog")
+}
+
+// Stop stops periodic checks and waits for the check in progress to finish
+func (w *StuckTxWatchdog) Stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel = nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Check replaces all tracked transactions that are pending longer than the timeout and stops tracking the ones that are
+// no longer pending. It's called periodically after Start().
+func (w *StuckTxWatchdog) Check(ctx context.Context) {
+	w.checkSigned(ctx)
+
+	w.mu.Lock()
+	stuck := make([]trackedTxKey, 0)
+	for key, t := range w.tracked {
+		if time.Since(t.sentAt) >= w.timeout {
+			stuck = append(stuck, key)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, key := range stuck {
+		if ctx.Err() != nil {
+			return
+		}
+		w.replace(key)
+	}
+}
+
+// checkSigned starts tracking signed transactions older than the timeout, which node knows as pending, and forgets all other ones,
+// because they were never sent or were already mined
+func (w *StuckTxWatchdog) checkSigned(ctx context.Context) {
+	w.mu.Lock()
+	expired := make([]*signedTx, 0)
+	for hash, t := range w.signed {
+		if time.Since(t.signedAt) >= w.timeout {
+			expired = append(expired, t)
+			delete(w.signed, hash)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, t := range expired {
+		if ctx.Err() != nil {
+			return
+		}
+		ctxTx, cancel := context.WithTimeout(ctx, w.client.Cfg.Network.TxnTimeout.Duration())
+		_, isPending, err := w.client.Client.TransactionByHash(ctxTx, t.tx.Hash())
+		cancel()
+		if err != nil || !isPending {
+			continue
+		}
+		w.track(t.tx, t.signedAt)
+	}
+}
+
+func (w *StuckTxWatchdog) replace(key trackedTxKey) {
+	w.mu.Lock()
+	t, ok := w.tracked[key]
+	if !ok {
+		w.mu.Unlock()
+		return
+	}
+	tx, originalTxHash, replacements := t.tx, t.originalTxHash, t.replacements
+	w.mu.Unlock()
+
+	event := StuckTxEvent{
+		From:           key.from,
+		Nonce:          key.nonce,
+		OriginalTxHash: originalTxHash,
+		TxHash:         tx.Hash(),
+		Replacements:   replacements,
+	}
+
+	if replacements >= w.maxReplacements {
+		event.Type = StuckTxEvent_Abandoned
+		w.untrack(key, event)
+		return
+	}
+
+	L.Warn().
+		Str("Tx hash", tx.Hash().Hex()).
+		Str("From", key.from.Hex()).
+		Uint64("Nonce", key.nonce).
+		Msgf("Transaction wasn't confirmed in %s. Stuck tx watchdog is bumping gas", w.timeout.String())
+
+	replacementTx, err := w.client.sendReplacementTransaction(tx, withMinimumReplacementBump(w.client.gasBumpStrategy()), false)
+	switch {
+	case err == nil:
+		event.Type = StuckTxEvent_Replaced
+		event.ReplacementTxHash = replacementTx.Hash()
+		event.Replacements++
+
+		w.mu.Lock()
+		// replacement was already tracked by sendReplacementTransaction()
+		if t, ok := w.tracked[key]; ok {
+			t.replacements = event.Replacements
+		}
+		w.stats.Replaced++
+		w.mu.Unlock()
+		w.emit(event)
+	case isNoLongerPending(err):
+		if replacements == 0 {
+			w.untrack(key, StuckTxEvent{})
+			return
+		}
+		event.Type = StuckTxEvent_Confirmed
+		w.untrack(key, event)
+	case strings.Contains(err.Error(), ErrMaxGasPriceExceeded), strings.Contains(err.Error(), ErrGasBumpCapReached):
+		event.Type = StuckTxEvent_Abandoned
+		event.Err = err
+		w.untrack(key, event)
+	default:
+		event.Type = StuckTxEvent_ReplacementFailed
+		event.Err = err
+		event.Replacements++
+
+		w.mu.Lock()
+		// failed attempts count towards the limit, so that we don't retry forever
+		if t, ok := w.tracked[key]; ok {
+			t.replacements = event.Replacements
+		}
+		w.stats.FailedReplacements++
+		w.mu.Unlock()
+		L.Warn().Err(err).Str("Tx hash", tx.Hash().Hex()).Msg("Stuck tx watchdog failed to replace transaction")
+		w.emit(event)
+	}
+}
+
+// isNoLongerPending returns true if error means that transaction was mined, replaced or dropped
+func isNoLongerPending(err error) bool {
+	return err.Error() == ErrTxNotPending ||
+		errors.Is(err, ethereum.NotFound) ||
+		strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// untrack stops tracking the transaction and emits the event, unless it has no type
+func (w *StuckTxWatchdog) untrack(key trackedTxKey, event StuckTxEvent) {
+	w.client.forgetOriginalFees(key.from, key.nonce)
+
+	w.mu.Lock()
+	delete(w.tracked, key)
+	switch event.Type {
+	case StuckTxEvent_Abandoned:
+		w.stats.Abandoned++
+	case StuckTxEvent_Confirmed:
+		w.stats.Confirmed++
+	}
+	w.mu.Unlock()
+
+	if event.Type == StuckTxEvent_Abandoned {
+		L.Error().
+			Err(event.Err).
+			Str("Tx hash", event.TxHash.Hex()).
+			Uint("Replacements", event.Replacements).
+			Msg("Stuck tx watchdog gave up on transaction")
+	}
+
+	if event.Type != "" {
+		w.emit(event)
+	}
+}
+
+func (w *StuckTxWatchdog) emit(event StuckTxEvent) {
+	w.mu.Lock()
+	listeners := make([]func(StuckTxEvent), len(w.listeners))
+	copy(listeners, w.listeners)
+	w.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(event)
+	}
+}
+
+// Stats returns watchdog's counters
+func (w *StuckTxWatchdog) Stats() StuckTxWatchdogStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := w.stats
+	stats.Tracked = len(w.tracked)
+	return stats
+}
+
+// running returns true if the watchdog was started and its checks weren't stopped by Stop() or by cancelling its context
+func (w *StuckTxWatchdog) running() bool {
+	w.mu.Lock()
+	done := w.done
+	w.mu.Unlock()
+
+	if done == nil {
+		return false
+	}
+	select {
+	case <-done:
+		return false
+	default:
+		return true
+	}
+}
+
+// waitMinedFollowingWatchdog waits until the transaction or any of its replacements sent by the watchdog is mined and returns the mined one.
+// It waits for as long as the watchdog keeps replacing the transaction and then for network's transaction timeout since the latest
+// replacement, but never longer than the timeout multiplied by max replacements + 1, and not at all once the watchdog is stopped.
+// Gas is never bumped here, so that the watchdog and Decode() don't replace the same nonce independently. On error the original
+// transaction is returned.
+func (m *Client) waitMinedFollowingWatchdog(l zerolog.Logger, tx *types.Transaction) (*types.Transaction, *types.Receipt, error) {
+	w := m.StuckTxWatchdog
+	w.Track(tx)
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return tx, nil, err
+	}
+
+	timeout := m.Cfg.Network.TxnTimeout.Duration()
+	// transactions replaced and confirmed between two polls would be missed, so we poll at least as often as the watchdog checks
+	pollInterval := time.Second
+	if w.checkInterval < pollInterval {
+		pollInterval = w.checkInterval
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	candidates := []*types.Transaction{tx}
+	known := map[common.Hash]bool{tx.Hash(): true}
+	deadline := time.Now().Add(timeout)
+	maxDeadline := time.Now().Add(timeout * time.Duration(w.maxReplacements+1))
+	for {
+		// the latest replacement is the most likely to be mined
+		for i := len(candidates) - 1; i >= 0; i-- {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			receipt, err := m.Client.TransactionReceipt(ctx, candidates[i].Hash())
+			cancel()
+			if err == nil {
+				l.Info().
+					Int64("BlockNumber", receipt.BlockNumber.Int64()).
+					Str("TX", candidates[i].Hash().String()).
+					Msg("Transaction receipt found")
+				w.confirm(from, tx.Nonce(), candidates[i])
+				return candidates[i], receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				l.Warn().Err(err).Str("TX", candidates[i].Hash().String()).Msg("Failed to get receipt")
+			}
+		}
+
+		sent, tracked := w.sentTransactions(from, tx.Nonce())
+		for _, s := range sent {
+			if known[s.Hash()] {
+				continue
+			}
+			l.Debug().Str("TX", s.Hash().String()).Msg("Awaiting replacement sent by stuck tx watchdog")
+			known[s.Hash()] = true
+			candidates = append(candidates, s)
+			deadline = time.Now().Add(timeout)
+		}
+		if !tracked && time.Now().After(deadline) {
+			l.Error().Msg("Transaction wasn't mined and stuck tx watchdog no longer replaces it")
+			return tx, nil, context.DeadlineExceeded
+		}
+		if time.Now().After(maxDeadline) {
+			l.Error().Msg("Transaction wasn't mined, even though stuck tx watchdog had time to replace it max number of times")
+			return tx, nil, context.DeadlineExceeded
+		}
+		if !w.running() {
+			l.Error().Msg("Transaction wasn't mined and stuck tx watchdog was stopped")
+			return tx, nil, errors.New(ErrStuckTxWatchdogStopped)
+		}
+
+		<-ticker.C
+	}
+}
+
+// StuckTxWatchdogStats returns stuck transaction watchdog's counters, if it's enabled
+func (m *Client) StuckTxWatchdogStats() (StuckTxWatchdogStats, bool) {
+	if m.StuckTxWatchdog == nil {
+		return StuckTxWatchdogStats{}, false
+	}
+	return m.StuckTxWatchdog.Stats(), true
+}
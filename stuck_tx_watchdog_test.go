@@ -0,0 +1,200 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+// watchdogEvents collects events emitted by the watchdog, which might run in the background
+type watchdogEvents struct {
+	mu     sync.Mutex
+	events []seth.StuckTxEvent
+}
+
+func (e *watchdogEvents) add(event seth.StuckTxEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *watchdogEvents) all() []seth.StuckTxEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]seth.StuckTxEvent{}, e.events...)
+}
+
+// withWatchdogEvents collects all events published by the watchdog
+func withWatchdogEvents(events *watchdogEvents) fakeNodeClientOption {
+	return fakeNodeClientOption{
+		client: func(_ *testing.T, c *seth.Client) {
+			c.StuckTxWatchdog.Subscribe(events.add)
+		},
+	}
+}
+
+func signWithSethOptions(t *testing.T, c *seth.Client) *types.Transaction {
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	opts := c.NewTXOpts()
+	tx, err := opts.Signer(opts.From, types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64(), GasPrice: big.NewInt(1_000), Gas: 21_000, To: &to, Value: big.NewInt(0)}))
+	require.NoError(t, err, "failed to sign transaction")
+	return tx
+}
+
+func TestStuckTxWatchdogReplacesTransactionsSentWithSethOptions(t *testing.T) {
+	node := newFakeNode(t)
+	events := &watchdogEvents{}
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{StrategyFn: seth.PriorityBasedGasBumpingStrategyFn(seth.Priority_Degen)}), withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(time.Hour), withWatchdogEvents(events))
+
+	tx := signWithSethOptions(t, c)
+	stats, ok := c.StuckTxWatchdogStats()
+	require.True(t, ok, "watchdog should be enabled")
+	require.Equal(t, 0, stats.Tracked, "signed transaction should not be tracked before it's sent")
+
+	require.NoError(t, c.Client.SendTransaction(context.Background(), tx), "failed to send transaction")
+	c.StuckTxWatchdog.Check(context.Background())
+	require.Len(t, node.sentTxs(), 1, "transaction should not be replaced before timeout")
+
+	time.Sleep(60 * time.Millisecond)
+	// first check after timeout finds the transaction in the transaction pool and replaces it right away
+	c.StuckTxWatchdog.Check(context.Background())
+	require.Equal(t, 1, c.StuckTxWatchdog.Stats().Tracked, "sent transaction should be tracked")
+	sent := node.sentTxs()
+	require.Len(t, sent, 2, "stuck transaction should be replaced")
+	require.Equal(t, big.NewInt(2_000), sent[1].GasPrice(), "gas price should be bumped with configured strategy")
+	require.Equal(t, tx.Nonce(), sent[1].Nonce(), "replacement should use the same nonce")
+
+	require.Len(t, events.all(), 1, "replacement event should be emitted")
+	require.Equal(t, seth.StuckTxEvent_Replaced, events.all()[0].Type, "incorrect event type")
+	require.Equal(t, tx.Hash(), events.all()[0].OriginalTxHash, "incorrect original tx hash")
+	require.Equal(t, sent[1].Hash(), events.all()[0].ReplacementTxHash, "incorrect replacement tx hash")
+
+	// replacement gets mined
+	node.mine(t, sent[1], types.ReceiptStatusSuccessful)
+	time.Sleep(60 * time.Millisecond)
+	c.StuckTxWatchdog.Check(context.Background())
+	require.Len(t, node.sentTxs(), 2, "mined transaction should not be replaced")
+	require.Len(t, events.all(), 2, "confirmation event should be emitted")
+	require.Equal(t, seth.StuckTxEvent_Confirmed, events.all()[1].Type, "incorrect event type")
+
+	stats = c.StuckTxWatchdog.Stats()
+	require.Equal(t, 0, stats.Tracked, "mined transaction should no longer be tracked")
+	require.Equal(t, uint64(1), stats.Replaced, "incorrect number of replacements")
+	require.Equal(t, uint64(1), stats.Confirmed, "incorrect number of confirmations")
+}
+
+func TestStuckTxWatchdogIgnoresSignedTransactionsThatWereNeverSent(t *testing.T) {
+	node := newFakeNode(t)
+	events := &watchdogEvents{}
+	c := newFakeNodeClient(t, node, withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(time.Hour), withWatchdogEvents(events))
+
+	sentTx := signWithSethOptions(t, c)
+	// transaction signed with the same nonce, but never sent, e.g. because sending failed
+	_ = signWithSethOptions(t, c)
+	node.addPending(t, sentTx)
+	c.StuckTxWatchdog.Track(sentTx)
+
+	time.Sleep(60 * time.Millisecond)
+	c.StuckTxWatchdog.Check(context.Background())
+	sent := node.sentTxs()
+	require.Len(t, sent, 1, "only the sent transaction should be replaced")
+	require.Equal(t, sentTx.Hash(), events.all()[0].TxHash, "sent transaction should be replaced")
+	require.Equal(t, 1, c.StuckTxWatchdog.Stats().Tracked, "only one nonce should be tracked")
+}
+
+func TestStuckTxWatchdogAbandonsTransactionAboveMaxGasPrice(t *testing.T) {
+	node := newFakeNode(t)
+	events := &watchdogEvents{}
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{MaxGasPrice: 1_500, StrategyFn: seth.PriorityBasedGasBumpingStrategyFn(seth.Priority_Degen)}), withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(time.Hour), withWatchdogEvents(events))
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := signPending(t, c, node, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1_000), Gas: 21_000, To: &to, Value: big.NewInt(0)})
+	c.StuckTxWatchdog.Track(tx)
+
+	time.Sleep(60 * time.Millisecond)
+	c.StuckTxWatchdog.Check(context.Background())
+	require.Empty(t, node.sentTxs(), "replacement above max gas price should not be sent")
+	require.Len(t, events.all(), 1, "abandon event should be emitted")
+	require.Equal(t, seth.StuckTxEvent_Abandoned, events.all()[0].Type, "incorrect event type")
+	require.ErrorContains(t, events.all()[0].Err, seth.ErrMaxGasPriceExceeded, "incorrect error")
+	require.Equal(t, uint64(1), c.StuckTxWatchdog.Stats().Abandoned, "incorrect number of abandoned transactions")
+}
+
+func TestStuckTxWatchdogStopsAfterMaxReplacements(t *testing.T) {
+	node := newFakeNode(t)
+	events := &watchdogEvents{}
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{StrategyFn: seth.NoOpGasBumpStrategyFn}), withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(time.Hour), withWatchdogEvents(events))
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := signPending(t, c, node, &types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1_000), Gas: 21_000, To: &to, Value: big.NewInt(0)})
+	c.StuckTxWatchdog.Track(tx)
+
+	for i := 0; i < 3; i++ {
+		time.Sleep(60 * time.Millisecond)
+		c.StuckTxWatchdog.Check(context.Background())
+	}
+
+	require.Len(t, node.sentTxs(), 2, "only max replacements should be sent")
+	require.Equal(t, big.NewInt(1_210), node.sentTxs()[1].GasPrice(), "gas price should be bumped by at least 10% each time")
+	require.Len(t, events.all(), 3, "two replacements and abandon events should be emitted")
+	require.Equal(t, seth.StuckTxEvent_Abandoned, events.all()[2].Type, "incorrect event type")
+	require.Equal(t, uint(2), events.all()[2].Replacements, "incorrect number of replacements")
+}
+
+func TestStuckTxWatchdogDecodeWaitsForReplacementInsteadOfBumpingGas(t *testing.T) {
+	node := newFakeNode(t)
+	events := &watchdogEvents{}
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{Retries: 5, StrategyFn: seth.PriorityBasedGasBumpingStrategyFn(seth.Priority_Degen)}), withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(10*time.Millisecond), withWatchdogEvents(events))
+
+	tx := signWithSethOptions(t, c)
+	require.NoError(t, c.Client.SendTransaction(context.Background(), tx), "failed to send transaction")
+	// original transaction stays pending, replacement is mined as soon as it's sent
+	node.mu.Lock()
+	node.autoMine = true
+	node.mu.Unlock()
+
+	decoded, err := c.Decode(tx, nil)
+	require.NoError(t, err, "failed to wait for replacement")
+
+	sent := node.sentTxs()
+	require.Len(t, sent, 2, "only the watchdog should replace the transaction")
+	require.Equal(t, sent[1].Hash(), decoded.Transaction.Hash(), "replacement sent by the watchdog should be decoded")
+	require.Equal(t, types.ReceiptStatusSuccessful, decoded.Receipt.Status, "replacement should be mined")
+
+	require.Eventually(t, func() bool {
+		return c.StuckTxWatchdog.Stats().Tracked == 0
+	}, time.Second, 10*time.Millisecond, "mined nonce should no longer be tracked")
+	require.Equal(t, seth.StuckTxEvent_Replaced, events.all()[0].Type, "incorrect event type")
+	require.Equal(t, seth.StuckTxEvent_Confirmed, events.all()[len(events.all())-1].Type, "confirmation should be the last event")
+}
+
+func TestStuckTxWatchdogDecodeFailsWhenTransactionIsAbandoned(t *testing.T) {
+	node := newFakeNode(t)
+	events := &watchdogEvents{}
+	c := newFakeNodeClient(t, node, withGasBump(&seth.GasBumpConfig{MaxGasPrice: 1_500, StrategyFn: seth.PriorityBasedGasBumpingStrategyFn(seth.Priority_Degen)}), withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(10*time.Millisecond), withWatchdogEvents(events))
+
+	tx := signWithSethOptions(t, c)
+	require.NoError(t, c.Client.SendTransaction(context.Background(), tx), "failed to send transaction")
+
+	_, err := c.Decode(tx, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded, "abandoned transaction should not be waited for")
+	require.Len(t, node.sentTxs(), 1, "replacement above max gas price should not be sent")
+	require.Equal(t, seth.StuckTxEvent_Abandoned, events.all()[0].Type, "transaction should be abandoned")
+}
+
+func TestStuckTxWatchdogDecodeFailsWhenWatchdogIsStopped(t *testing.T) {
+	c := newFakeNodeClient(t, newFakeNode(t), withTxnTimeout(50*time.Millisecond), withStuckTxWatchdog(time.Hour))
+
+	tx := signWithSethOptions(t, c)
+	require.NoError(t, c.Client.SendTransaction(context.Background(), tx), "failed to send transaction")
+	c.StuckTxWatchdog.Stop()
+
+	_, err := c.Decode(tx, nil)
+	require.EqualError(t, err, seth.ErrStuckTxWatchdogStopped, "pending transaction should not be waited for after watchdog was stopped")
+}
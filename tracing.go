@@ -18,6 +18,8 @@ import (
 const (
 	ErrNoTrace                = "no trace found"
 	ErrNoABIMethod            = "no ABI method found"
+	ErrNoABIEvent             = "no ABI event found"
+	ErrNoEventTopics          = "log has no topics"
 	ErrNoAbiFound             = "no ABI found in Contract Store"
 	ErrNoFourByteFound        = "no method signatures found in tracing data"
 	ErrInvalidMethodSignature = "no method signature found or it's not 4 bytes long"
@@ -41,6 +43,8 @@ type Tracer struct {
 	ABIFinder                *ABIFinder
 	tracesMutex              *sync.RWMutex
 	decodedMutex             *sync.RWMutex
+	// ValueFormatter, if set, is used to make decoded values human-friendly in console, JSON and DOT outputs
+	ValueFormatter *ValueFormatter
 }
 
 func (t *Tracer) getTrace(txHash string) *Trace {
@@ -73,6 +77,17 @@ func (t *Tracer) AddDecodedCalls(txHash string, calls []*DecodedCall) {
 	t.decodedCalls[txHash] = calls
 }
 
+// forget removes trace and decoded calls of the transaction, so that long-running users don't keep all of them in memory
+func (t *Tracer) forget(txHash string) {
+	t.tracesMutex.Lock()
+	delete(t.traces, txHash)
+	t.tracesMutex.Unlock()
+
+	t.decodedMutex.Lock()
+	delete(t.decodedCalls, txHash)
+	t.decodedMutex.Unlock()
+}
+
 type Trace struct {
 	TxHash       string
 	FourByte     map[string]*TXFourByteMetadataOutput
@@ -175,9 +190,14 @@ func (t *Tracer) TraceGethTX(txHash string, revertErr error) error {
 	}
 
 	if len(decodedCalls) != 0 {
-		t.printDecodedCallData(L, decodedCalls, revertErr)
+		// formatting might call the node to read token decimals, skip it if neither console nor DOT output will use it
+		formattedCalls := decodedCalls
+		if t.Cfg.hasOutput(TraceOutput_DOT) || L.Debug().Enabled() {
+			formattedCalls = t.ValueFormatter.FormatCalls(decodedCalls)
+		}
+		t.printDecodedCallData(L, formattedCalls, revertErr)
 
-		err = t.generateDotGraph(txHash, decodedCalls, revertErr)
+		err = t.generateDotGraph(txHash, formattedCalls, revertErr)
 		if err != nil {
 			return err
 		}
@@ -370,7 +390,6 @@ func (t *Tracer) DecodeTrace(l zerolog.Logger, trace Trace) ([]*DecodedCall, err
 func (t *Tracer) decodeCall(byteSignature []byte, rawCall Call) (*DecodedCall, error) {
 	var txInput map[string]interface{}
 	var txOutput map[string]interface{}
-	var txEvents []DecodedCommonLog
 
 	var generateDuplicatesComment = func(abiResult ABIFinderResult) string {
 		var comment string
@@ -394,6 +413,9 @@ func (t *Tracer) decodeCall(byteSignature []byte, rawCall Call) (*DecodedCall, e
 
 	defaultCall.CallType = rawCall.Type
 	defaultCall.Error = rawCall.Error
+	if rawCall.Error != "" {
+		defaultCall.RevertReason = t.decodeRevertReason(rawCall.Output)
+	}
 
 	if rawCall.Value != "" && rawCall.Value != "0x0" {
 		decimalValue, err := strconv.ParseInt(strings.TrimPrefix(rawCall.Value, "0x"), 16, 64)
@@ -431,7 +453,13 @@ func (t *Tracer) decodeCall(byteSignature []byte, rawCall Call) (*DecodedCall, e
 		}
 	}
 
+	// logs are decoded with the ABI of the contract that emitted them, so we can decode them even if we don't know the called method
+	txEvents := t.decodeContractLogs(L, rawCall.Logs)
+
 	if err != nil {
+		if len(txEvents) > 0 {
+			defaultCall.Events = txEvents
+		}
 		if defaultCall.Comment != "" {
 			defaultCall.Comment = fmt.Sprintf("%s; %s", defaultCall.Comment, CommentMissingABI)
 		} else {
@@ -471,14 +499,35 @@ func (t *Tracer) decodeCall(byteSignature []byte, rawCall Call) (*DecodedCall, e
 
 	}
 
-	txEvents, err = t.decodeContractLogs(L, rawCall.Logs, abiResult.ABI)
+	defaultCall.Events = txEvents
+
+	return defaultCall, nil
+}
+
+// decodeRevertReason decodes revert data of a reverted call using custom errors from all known ABIs, falling back to
+// standard Error(string) and Panic(uint256) errors. It returns an empty string if revert data couldn't be decoded.
+func (t *Tracer) decodeRevertReason(output string) string {
+	data, err := hexutil.Decode(output)
+	if err != nil || len(data) < 4 {
+		return ""
+	}
+
+	if t.ContractStore != nil {
+		reason, err := t.ContractStore.DecodeCustomError(data)
+		if err != nil {
+			L.Debug().Err(err).Msg("Failed to decode custom revert error")
+		}
+		if reason != "" {
+			return reason
+		}
+	}
+
+	reason, err := abi.UnpackRevert(data)
 	if err != nil {
-		L.Debug().Err(err).Msg("Failed to decode logs")
-	} else {
-		defaultCall.Events = txEvents
+		return ""
 	}
 
-	return defaultCall, nil
+	return reason
 }
 
 func (t *Tracer) isOwnAddress(addr string) bool {
@@ -603,7 +652,7 @@ func (t *Tracer) checkForMissingCalls(trace Trace) []*DecodedCall {
 
 func (t *Tracer) SaveDecodedCallsAsJson(dirname string) error {
 	for txHash, calls := range t.GetAllDecodedCalls() {
-		_, err := saveAsJson(calls, dirname, txHash)
+		_, err := saveAsJson(t.ValueFormatter.FormatCalls(calls), dirname, txHash)
 		if err != nil {
 			return err
 		}
@@ -612,32 +661,62 @@ func (t *Tracer) SaveDecodedCallsAsJson(dirname string) error {
 	return nil
 }
 
-func (t *Tracer) decodeContractLogs(l zerolog.Logger, logs []TraceLog, a abi.ABI) ([]DecodedCommonLog, error) {
+// decodeContractLogs decodes each log with the ABI of the contract that emitted it. Logs that can't be decoded are
+// kept with raw topics and data, so that no event is lost.
+func (t *Tracer) decodeContractLogs(l zerolog.Logger, logs []TraceLog) []DecodedCommonLog {
 	l.Trace().Msg("Decoding events")
 	var eventsParsed []DecodedCommonLog
 	for _, lo := range logs {
-		for _, evSpec := range a.Events {
-			if evSpec.ID.Hex() == lo.Topics[0] {
-				l.Trace().Str("Name", evSpec.RawName).Str("Signature", evSpec.Sig).Msg("Unpacking event")
-				eventsMap, topicsMap, err := decodeEventFromLog(l, a, evSpec, lo)
-				if err != nil {
-					return nil, errors.Wrap(err, ErrDecodeLog)
-				}
-				parsedEvent := decodedLogFromMaps(&DecodedCommonLog{}, eventsMap, topicsMap)
-				if decodedLog, ok := parsedEvent.(*DecodedCommonLog); ok {
-					decodedLog.Signature = evSpec.Sig
-					t.mergeLogMeta(decodedLog, lo)
-					eventsParsed = append(eventsParsed, *decodedLog)
-					l.Trace().Interface("Log", parsedEvent).Msg("Transaction log")
-				} else {
-					l.Trace().
-						Str("Actual type", fmt.Sprintf("%T", decodedLog)).
-						Msg("Failed to cast decoded event to DecodedCommonLog")
-				}
-			}
+		decodedLog, err := t.decodeContractLog(l, lo)
+		if err != nil {
+			l.Debug().
+				Err(err).
+				Str("Address", lo.Address).
+				Msg("Failed to decode log. Keeping raw topics and data")
+			decodedLog = t.rawLog(lo)
 		}
+		eventsParsed = append(eventsParsed, decodedLog)
+	}
+	return eventsParsed
+}
+
+func (t *Tracer) decodeContractLog(l zerolog.Logger, lo TraceLog) (DecodedCommonLog, error) {
+	abiResult, err := t.ABIFinder.FindABIByEvent(lo.Address, lo.GetTopics())
+	if err != nil {
+		return DecodedCommonLog{}, err
+	}
+
+	evSpec := *abiResult.Event
+	l.Trace().Str("Name", evSpec.RawName).Str("Signature", evSpec.Sig).Msg("Unpacking event")
+	eventsMap, topicsMap, err := decodeEventFromLog(l, abiResult.ABI, evSpec, lo)
+	if err != nil {
+		return DecodedCommonLog{}, errors.Wrap(err, ErrDecodeLog)
+	}
+	parsedEvent := decodedLogFromMaps(&DecodedCommonLog{}, eventsMap, topicsMap)
+	decodedLog, ok := parsedEvent.(*DecodedCommonLog)
+	if !ok {
+		return DecodedCommonLog{}, fmt.Errorf("failed to cast decoded event to DecodedCommonLog, actual type: %T", parsedEvent)
 	}
-	return eventsParsed, nil
+	decodedLog.Signature = evSpec.Sig
+	t.mergeLogMeta(decodedLog, lo)
+	l.Trace().Interface("Log", parsedEvent).Msg("Transaction log")
+
+	return *decodedLog, nil
+}
+
+// rawLog returns log that couldn't be decoded with its topic0 as signature and raw data
+func (t *Tracer) rawLog(lo TraceLog) DecodedCommonLog {
+	signature := UNKNOWN
+	if len(lo.Topics) > 0 {
+		signature = lo.Topics[0]
+	}
+	rawLog := DecodedCommonLog{
+		Signature: signature,
+		EventData: map[string]interface{}{"data": lo.Data},
+	}
+	t.mergeLogMeta(&rawLog, lo)
+
+	return rawLog
 }
 
 // mergeLogMeta add metadata from log
@@ -700,6 +779,10 @@ func (t *Tracer) printDecodedCallData(l zerolog.Logger, calls []*DecodedCall, re
 				Interface(fmt.Sprintf("%s- Log", indentation), e.EventData).Send()
 		}
 
+		if dc.RevertReason != "" {
+			l.Debug().Str(fmt.Sprintf("%s- Revert reason", indentation), dc.RevertReason).Send()
+		}
+
 		if revertErr != nil && dc.Error != "" {
 			l.Error().Str(fmt.Sprintf("%s- Revert", indentation), revertErr.Error()).Send()
 		}
@@ -55,16 +55,17 @@ func (m *Client) CalculateSubKeyFunding(addrs, gasPrice, rooKeyBuffer int64) (*F
 		return nil, err
 	}
 
-	gasLimit := m.Cfg.Network.TransferGasFee
 	newAddress, _, err := NewAddress()
-	if err == nil {
-		gasLimitRaw, err := m.EstimateGasLimitForFundTransfer(m.Addresses[0], common.HexToAddress(newAddress), big.NewInt(0).Quo(balance, big.NewInt(addrs)))
-		if err == nil {
-			gasLimit = int64(gasLimitRaw)
-		}
+	if err != nil {
+		return nil, err
+	}
+
+	transferFee, err := m.EstimateTransferFee(context.Background(), m.Addresses[0], common.HexToAddress(newAddress), big.NewInt(0).Quo(balance, big.NewInt(addrs)), big.NewInt(gasPrice))
+	if err != nil {
+		return nil, err
 	}
 
-	networkTransferFee := gasPrice * gasLimit
+	networkTransferFee := transferFee.Total.Int64()
 	totalFee := new(big.Int).Mul(big.NewInt(networkTransferFee), big.NewInt(addrs))
 	rootKeyBuffer := new(big.Int).Mul(big.NewInt(rooKeyBuffer), big.NewInt(1_000_000_000_000_000_000))
 	freeBalance := new(big.Int).Sub(balance, big.NewInt(0).Add(totalFee, rootKeyBuffer))
@@ -263,7 +264,10 @@ func saveAsJson(v any, dirName, name string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(pwd, dirName)
+	dir := dirName
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(pwd, dirName)
+	}
 	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
 		err := os.MkdirAll(dir, os.ModePerm)
 		if err != nil {
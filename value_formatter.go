@@ -0,0 +1,367 @@
+package seth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxTokenDecimals is the largest number of decimals we accept from decimals() call, anything bigger is treated as garbage
+	maxTokenDecimals    = 77
+	decimalsCallTimeout = 10 * time.Second
+	// executionRevertedErrorCode is the JSON-RPC error code Geth and other clients return for reverted calls
+	executionRevertedErrorCode = 3
+)
+
+// decimalsSelector is the selector of ERC-20 decimals() method
+var decimalsSelector = common.FromHex("0x313ce567")
+
+// ValueFormattingConfig controls how decoded inputs, outputs and event data are presented in console, JSON and DOT outputs.
+// Decoded data returned by Seth's API is never modified.
+type ValueFormattingConfig struct {
+	// Enabled turns on human-friendly formatting of decoded values
+	Enabled bool `toml:"enabled"`
+	// ScaleTokenAmounts scales amount fields by token decimals read via decimals() from the called/emitting contract
+	ScaleTokenAmounts bool `toml:"scale_token_amounts"`
+	// AmountFields are names of inputs, outputs and event fields that hold token amounts (case-insensitive)
+	AmountFields []string `toml:"amount_fields"`
+}
+
+// DefaultAmountFields are used, when token amount scaling is enabled, but no amount fields were configured
+var DefaultAmountFields = []string{"amount", "value", "wad", "balance", "_value", "_amount"}
+
+// ValueFormatterEnabled returns true if human-friendly value formatting is enabled
+func (c *Config) ValueFormatterEnabled() bool {
+	return c.ValueFormatting != nil && c.ValueFormatting.Enabled
+}
+
+// ValueFormatter turns raw values returned by ABI decoder into human-friendly ones: addresses are replaced with contract
+// names or key labels, byte arrays are rendered as hex, big integers as decimal strings and structs (tuples) as maps
+// with field names. Optionally, token amounts are scaled by token decimals.
+type ValueFormatter struct {
+	contractMap       ContractMap
+	addresses         []common.Address
+	caller            ethereum.ContractCaller
+	scaleTokenAmounts bool
+	amountFields      map[string]struct{}
+	decimals          map[common.Address]*uint8
+	decimalsMu        *sync.Mutex
+}
+
+// NewValueFormatter creates a new ValueFormatter. Caller is only used to read token decimals and might be nil, if amount scaling is disabled.
+func NewValueFormatter(contractMap ContractMap, addresses []common.Address, caller ethereum.ContractCaller, cfg *ValueFormattingConfig) *ValueFormatter {
+	f := &ValueFormatter{
+		contractMap:  contractMap,
+		addresses:    addresses,
+		caller:       caller,
+		amountFields: make(map[string]struct{}),
+		decimals:     make(map[common.Address]*uint8),
+		decimalsMu:   &sync.Mutex{},
+	}
+
+	if cfg == nil {
+		return f
+	}
+
+	f.scaleTokenAmounts = cfg.ScaleTokenAmounts && caller != nil
+	amountFields := cfg.AmountFields
+	if len(amountFields) == 0 {
+		amountFields = DefaultAmountFields
+	}
+	for _, field := range amountFields {
+		f.amountFields[strings.ToLower(field)] = struct{}{}
+	}
+
+	return f
+}
+
+// FormatAddress returns contract name or key label together with the address, or just the address if it's unknown
+func (f *ValueFormatter) FormatAddress(address common.Address) string {
+	if name := f.contractMap.GetContractName(address.Hex()); name != "" {
+		return fmt.Sprintf("%s (%s)", name, address.Hex())
+	}
+	for i, a := range f.addresses {
+		if a == address {
+			return fmt.Sprintf("key #%d (%s)", i, address.Hex())
+		}
+	}
+
+	return address.Hex()
+}
+
+// FormatValue converts a single decoded value into its human-friendly representation
+func (f *ValueFormatter) FormatValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case common.Address:
+		return f.FormatAddress(value)
+	case *common.Address:
+		if value == nil {
+			return nil
+		}
+		return f.FormatAddress(*value)
+	case common.Hash:
+		return value.Hex()
+	case *big.Int:
+		if value == nil {
+			return nil
+		}
+		return value.String()
+	case big.Int:
+		return value.String()
+	case []byte:
+		return hexutil.Encode(value)
+	case string, bool:
+		return value
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return f.FormatValue(rv.Elem().Interface())
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		return f.formatSlice(rv)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return hexutil.Encode(rv.Bytes())
+		}
+		return f.formatSlice(rv)
+	case reflect.Struct:
+		formatted := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" && tag != "-" {
+				name = tag
+			}
+			formatted[name] = f.FormatValue(rv.Field(i).Interface())
+		}
+		return formatted
+	case reflect.Map:
+		formatted := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			formatted[fmt.Sprint(f.FormatValue(iter.Key().Interface()))] = f.FormatValue(iter.Value().Interface())
+		}
+		return formatted
+	default:
+		return v
+	}
+}
+
+func (f *ValueFormatter) formatSlice(rv reflect.Value) []interface{} {
+	formatted := make([]interface{}, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		formatted[i] = f.FormatValue(rv.Index(i).Interface())
+	}
+	return formatted
+}
+
+// FormatMap formats all values in a map of decoded inputs, outputs or event data. If token amount scaling is enabled
+// amount fields are scaled by decimals of the token deployed at given address.
+func (f *ValueFormatter) FormatMap(m map[string]interface{}, token common.Address) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	formatted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if scaled, ok := f.scaleAmount(k, v, token); ok {
+			formatted[k] = scaled
+			continue
+		}
+		formatted[k] = f.FormatValue(v)
+	}
+
+	return formatted
+}
+
+// FormatCall returns a copy of the decoded call with formatted inputs, outputs and event data
+func (f *ValueFormatter) FormatCall(call *DecodedCall) *DecodedCall {
+	if call == nil {
+		return nil
+	}
+
+	formatted := *call
+	token := addressOrZero(call.ToAddress)
+	formatted.Input = f.FormatMap(call.Input, token)
+	formatted.Output = f.FormatMap(call.Output, token)
+	if call.Events != nil {
+		formatted.Events = make([]DecodedCommonLog, len(call.Events))
+		for i, e := range call.Events {
+			emitter := e.Address
+			if emitter == (common.Address{}) {
+				emitter = token
+			}
+			e.EventData = f.FormatMap(e.EventData, emitter)
+			formatted.Events[i] = e
+		}
+	}
+
+	return &formatted
+}
+
+// FormatCalls returns formatted copies of all decoded calls. If formatter is nil, calls are returned as they are.
+func (f *ValueFormatter) FormatCalls(calls []*DecodedCall) []*DecodedCall {
+	if f == nil {
+		return calls
+	}
+
+	formatted := make([]*DecodedCall, 0, len(calls))
+	for _, call := range calls {
+		formatted = append(formatted, f.FormatCall(call))
+	}
+
+	return formatted
+}
+
+// FormatTransaction returns a copy of the decoded transaction with formatted inputs, outputs and event data.
+// If formatter is nil, transaction is returned as it is.
+func (f *ValueFormatter) FormatTransaction(tx *DecodedTransaction) *DecodedTransaction {
+	if f == nil || tx == nil {
+		return tx
+	}
+
+	formatted := *tx
+	var token common.Address
+	if tx.Transaction != nil && tx.Transaction.To() != nil {
+		token = *tx.Transaction.To()
+	}
+	formatted.Input = f.FormatMap(tx.Input, token)
+	formatted.Output = f.FormatMap(tx.Output, token)
+	if tx.Events != nil {
+		formatted.Events = make([]DecodedTransactionLog, len(tx.Events))
+		for i, e := range tx.Events {
+			e.EventData = f.FormatMap(e.EventData, e.Address)
+			formatted.Events[i] = e
+		}
+	}
+
+	return &formatted
+}
+
+func (f *ValueFormatter) scaleAmount(field string, v interface{}, token common.Address) (string, bool) {
+	if !f.scaleTokenAmounts || token == (common.Address{}) {
+		return "", false
+	}
+	if _, ok := f.amountFields[strings.ToLower(field)]; !ok {
+		return "", false
+	}
+	amount, ok := v.(*big.Int)
+	if !ok || amount == nil {
+		return "", false
+	}
+	decimals := f.tokenDecimals(token)
+	if decimals == nil {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s (%s)", ScaleByDecimals(amount, *decimals), amount.String()), true
+}
+
+// tokenDecimals reads (and caches) decimals of the token deployed at given address. Returns nil if the contract
+// doesn't implement decimals(). Only definitive answers are cached, so that transient RPC errors are retried.
+func (f *ValueFormatter) tokenDecimals(token common.Address) *uint8 {
+	f.decimalsMu.Lock()
+	d, ok := f.decimals[token]
+	f.decimalsMu.Unlock()
+	if ok {
+		return d
+	}
+
+	// lock is not held during the call, so that formatting isn't serialised behind network I/O; concurrent
+	// lookups of the same token might both call decimals(), which is harmless
+	ctx, cancel := context.WithTimeout(context.Background(), decimalsCallTimeout)
+	defer cancel()
+
+	var decimals *uint8
+	result, err := f.caller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: decimalsSelector}, nil)
+	if err == nil && len(result) == 32 {
+		d := new(big.Int).SetBytes(result)
+		if d.IsUint64() && d.Uint64() <= maxTokenDecimals {
+			value := uint8(d.Uint64())
+			decimals = &value
+		}
+	} else {
+		L.Trace().
+			Err(err).
+			Str("Address", token.Hex()).
+			Msg("Failed to read token decimals. Amounts won't be scaled")
+	}
+
+	// a revert or an unexpected return value means that the contract is not a token, any other error might be
+	// transient and the call should be retried next time
+	if err != nil && !isExecutionReverted(err) {
+		return nil
+	}
+
+	f.decimalsMu.Lock()
+	f.decimals[token] = decimals
+	f.decimalsMu.Unlock()
+
+	return decimals
+}
+
+// isExecutionReverted returns true if the error is a revert of the called contract rather than a failure of the RPC call itself
+func isExecutionReverted(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == executionRevertedErrorCode {
+		return true
+	}
+	return strings.HasPrefix(err.Error(), vm.ErrExecutionReverted.Error())
+}
+
+// ScaleByDecimals renders amount as a decimal number with given number of decimals, e.g. 1500000 with 6 decimals becomes "1.5"
+func ScaleByDecimals(amount *big.Int, decimals uint8) string {
+	if decimals == 0 {
+		return amount.String()
+	}
+
+	abs := new(big.Int).Abs(amount)
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, fraction := new(big.Int).QuoRem(abs, unit, new(big.Int))
+
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+
+	if fraction.Sign() == 0 {
+		return sign + whole.String()
+	}
+
+	fractionStr := fraction.String()
+	fractionStr = strings.Repeat("0", int(decimals)-len(fractionStr)) + fractionStr
+	return fmt.Sprintf("%s%s.%s", sign, whole.String(), strings.TrimRight(fractionStr, "0"))
+}
+
+func addressOrZero(address string) common.Address {
+	if !common.IsHexAddress(address) {
+		return common.Address{}
+	}
+	return common.HexToAddress(address)
+}
@@ -0,0 +1,192 @@
+package seth_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/smartcontractkit/seth"
+)
+
+type decimalsCaller struct {
+	decimals map[common.Address]int64
+	calls    int
+}
+
+func (d *decimalsCaller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	d.calls++
+	decimals, ok := d.decimals[*msg.To]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return common.LeftPadBytes(big.NewInt(decimals).Bytes(), 32), nil
+}
+
+func (d *decimalsCaller) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func TestValueFormatterFormatsValues(t *testing.T) {
+	contract := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	key := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	unknown := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+
+	contractMap := seth.NewEmptyContractMap()
+	contractMap.AddContract(contract.Hex(), "LinkToken")
+
+	formatter := seth.NewValueFormatter(contractMap, []common.Address{common.Address{}, key}, nil, &seth.ValueFormattingConfig{Enabled: true})
+
+	type tuple struct {
+		Owner  common.Address `json:"owner"`
+		Amount *big.Int       `json:"amount"`
+	}
+
+	formatted := formatter.FormatMap(map[string]interface{}{
+		"token":   contract,
+		"sender":  key,
+		"other":   unknown,
+		"amount":  big.NewInt(1_000_000),
+		"data":    []byte{0xde, 0xad},
+		"hash":    [4]byte{0xbe, 0xef, 0x00, 0x01},
+		"tuple":   tuple{Owner: key, Amount: big.NewInt(5)},
+		"holders": []common.Address{contract, unknown},
+		"flag":    true,
+	}, contract)
+
+	require.Equal(t, map[string]interface{}{
+		"token":   "LinkToken (" + contract.Hex() + ")",
+		"sender":  "key #1 (" + key.Hex() + ")",
+		"other":   unknown.Hex(),
+		"amount":  "1000000",
+		"data":    "0xdead",
+		"hash":    "0xbeef0001",
+		"tuple":   map[string]interface{}{"owner": "key #1 (" + key.Hex() + ")", "amount": "5"},
+		"holders": []interface{}{"LinkToken (" + contract.Hex() + ")", unknown.Hex()},
+		"flag":    true,
+	}, formatted, "incorrectly formatted values")
+}
+
+func TestValueFormatterScalesTokenAmounts(t *testing.T) {
+	token := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	notToken := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	caller := &decimalsCaller{decimals: map[common.Address]int64{token: 6}}
+
+	formatter := seth.NewValueFormatter(seth.NewEmptyContractMap(), nil, caller, &seth.ValueFormattingConfig{
+		Enabled:           true,
+		ScaleTokenAmounts: true,
+		AmountFields:      []string{"amount"},
+	})
+
+	input := map[string]interface{}{"amount": big.NewInt(1_500_000), "id": big.NewInt(7)}
+	require.Equal(t, map[string]interface{}{"amount": "1.5 (1500000)", "id": "7"}, formatter.FormatMap(input, token), "incorrectly scaled amount")
+	require.Equal(t, map[string]interface{}{"amount": "1500000", "id": "7"}, formatter.FormatMap(input, notToken), "amount should not be scaled")
+
+	// decimals are cached, also for contracts without decimals()
+	_ = formatter.FormatMap(input, token)
+	_ = formatter.FormatMap(input, notToken)
+	require.Equal(t, 2, caller.calls, "decimals should be read only once per address")
+
+	require.Equal(t, big.NewInt(1_500_000), input["amount"], "raw values should not be modified")
+}
+
+type flakyDecimalsCaller struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyDecimalsCaller) CallContract(_ context.Context, _ ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("Post \"http://localhost:8545\": connection refused")
+	}
+	return common.LeftPadBytes(big.NewInt(6).Bytes(), 32), nil
+}
+
+func (f *flakyDecimalsCaller) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func TestValueFormatterRetriesDecimalsAfterTransientError(t *testing.T) {
+	token := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	caller := &flakyDecimalsCaller{failures: 1}
+
+	formatter := seth.NewValueFormatter(seth.NewEmptyContractMap(), nil, caller, &seth.ValueFormattingConfig{
+		Enabled:           true,
+		ScaleTokenAmounts: true,
+		AmountFields:      []string{"amount"},
+	})
+
+	input := map[string]interface{}{"amount": big.NewInt(1_500_000)}
+	require.Equal(t, map[string]interface{}{"amount": "1500000"}, formatter.FormatMap(input, token), "amount should not be scaled when decimals() call fails")
+	require.Equal(t, map[string]interface{}{"amount": "1.5 (1500000)"}, formatter.FormatMap(input, token), "amount should be scaled once decimals() call succeeds")
+	require.Equal(t, map[string]interface{}{"amount": "1.5 (1500000)"}, formatter.FormatMap(input, token), "incorrectly scaled amount")
+	require.Equal(t, 2, caller.calls, "failed decimals() call should be retried and successful one cached")
+}
+
+type blockingDecimalsCaller struct {
+	slow    common.Address
+	release chan struct{}
+}
+
+func (b *blockingDecimalsCaller) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if *msg.To == b.slow {
+		<-b.release
+	}
+	return common.LeftPadBytes(big.NewInt(6).Bytes(), 32), nil
+}
+
+func (b *blockingDecimalsCaller) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func TestValueFormatterSlowDecimalsCallDoesNotBlockOtherTokens(t *testing.T) {
+	slow := common.HexToAddress("0x0DCd1Bf9A1b36cE34237eEaFef220932846BCD82")
+	fast := common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC")
+	caller := &blockingDecimalsCaller{slow: slow, release: make(chan struct{})}
+
+	formatter := seth.NewValueFormatter(seth.NewEmptyContractMap(), nil, caller, &seth.ValueFormattingConfig{
+		Enabled:           true,
+		ScaleTokenAmounts: true,
+		AmountFields:      []string{"amount"},
+	})
+	input := map[string]interface{}{"amount": big.NewInt(1_500_000)}
+
+	slowDone := make(chan map[string]interface{})
+	go func() {
+		slowDone <- formatter.FormatMap(input, slow)
+	}()
+
+	fastDone := make(chan map[string]interface{})
+	go func() {
+		fastDone <- formatter.FormatMap(input, fast)
+	}()
+
+	select {
+	case formatted := <-fastDone:
+		require.Equal(t, map[string]interface{}{"amount": "1.5 (1500000)"}, formatted, "incorrectly scaled amount")
+	case <-time.After(5 * time.Second):
+		t.Fatal("formatting was blocked by decimals() call of another token")
+	}
+
+	close(caller.release)
+	require.Equal(t, map[string]interface{}{"amount": "1.5 (1500000)"}, <-slowDone, "incorrectly scaled amount")
+}
+
+func TestValueFormatterScaleByDecimals(t *testing.T) {
+	require.Equal(t, "1", seth.ScaleByDecimals(big.NewInt(1_000_000_000_000_000_000), 18))
+	require.Equal(t, "0.000001", seth.ScaleByDecimals(big.NewInt(1_000_000_000_000), 18))
+	require.Equal(t, "-12.34", seth.ScaleByDecimals(big.NewInt(-1234), 2))
+	require.Equal(t, "1234", seth.ScaleByDecimals(big.NewInt(1234), 0))
+}
+
+func TestValueFormatterNilFormatterReturnsRawData(t *testing.T) {
+	var formatter *seth.ValueFormatter
+	calls := []*seth.DecodedCall{{CommonData: seth.CommonData{Input: map[string]interface{}{"a": big.NewInt(1)}}}}
+	require.Equal(t, calls, formatter.FormatCalls(calls), "nil formatter should return calls unchanged")
+}